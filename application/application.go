@@ -30,13 +30,18 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/reinit/coward/common/admin"
 	"github.com/reinit/coward/common/config"
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/parameter"
 	"github.com/reinit/coward/common/print"
 	"github.com/reinit/coward/common/role"
 	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/common/session"
 )
 
 // Application represents the COWARD application
@@ -64,6 +69,10 @@ type application struct {
 
 const roleListFormat = "    %%%ds    %%s\r\n"
 
+// handoverReadyTimeout is how long to wait for the new process to start
+// serving after the listeners has been handed over to it
+const handoverReadyTimeout = 30 * time.Second
+
 // New build a new COWARD application according to Config
 func New(log logger.Logger, cfg Config) Application {
 	var exeName string
@@ -234,6 +243,9 @@ func (c *application) Help() error {
 	printer.Writeln([]byte(fmt.Sprintf(
 		helpUsageParam,
 		strings.Repeat(" ", helpItemSpaceLen))), 4, helpItemSpaceLen+11, 1)
+	printer.Writeln([]byte(fmt.Sprintf(
+		helpUsageAdmin,
+		strings.Repeat(" ", helpItemSpaceLen))), 4, helpItemSpaceLen+11, 1)
 
 	printer.Write([]byte("\r\n\r\n"))
 
@@ -276,13 +288,15 @@ func (c *application) buildRunConfigFromParam(
 	parameters []string) (ExecuteConfig, int, error) {
 	breakLoop := false
 	result := ExecuteConfig{
-		Daemom:    false,
-		Slient:    false,
-		Debug:     false,
-		LogFile:   "",
-		ParamFile: "",
-		Shutdown:  nil,
-		Booted:    nil,
+		Daemom:     false,
+		Slient:     false,
+		Debug:      false,
+		LogFile:    "",
+		ParamFile:  "",
+		Admin:      "",
+		AdminToken: os.Getenv(adminTokenEnv),
+		Shutdown:   nil,
+		Booted:     nil,
 	}
 	lastIdx := 0
 	paramLen := len(parameters)
@@ -345,6 +359,19 @@ func (c *application) buildRunConfigFromParam(
 				return ExecuteConfig{}, 0, ErrConfigFileMustBeSpecified
 			}
 
+		case trimedParam == "-admin":
+			if lastIdx+1 >= paramLen {
+				return ExecuteConfig{}, 0, ErrAdminAddressMustBeSpecified
+			}
+
+			lastIdx++
+
+			result.Admin = strings.TrimSpace(parameters[lastIdx])
+
+			if result.Admin == "" {
+				return ExecuteConfig{}, 0, ErrAdminAddressMustBeSpecified
+			}
+
 		default:
 			if trimedParam[0] == '-' {
 				return ExecuteConfig{}, 0, ErrUnknownExecuteOption
@@ -479,7 +506,11 @@ func (c *application) ExecuteArgumentInput(parameters []string) error {
 
 		return c.execute(
 			printer,
-			func(log logger.Logger) (role.Role, error) {
+			func(
+				log logger.Logger,
+				m metrics.Registry,
+				s session.Sessions,
+			) (role.Role, error) {
 				var param []byte
 				var err error
 
@@ -501,6 +532,8 @@ func (c *application) ExecuteArgumentInput(parameters []string) error {
 					parameters[roleParamStart],
 					param,
 					log,
+					m,
+					s,
 				)
 			},
 			execCfg,
@@ -519,9 +552,13 @@ func (c *application) ExecuteParameter(
 	) error {
 		return c.execute(
 			printer,
-			func(log logger.Logger) (role.Role, error) {
+			func(
+				log logger.Logger,
+				m metrics.Registry,
+				s session.Sessions,
+			) (role.Role, error) {
 				return c.roles.InitParameterString(
-					printer, name, []byte(parameter), log)
+					printer, name, []byte(parameter), log, m, s)
 			},
 			execCfg,
 		)
@@ -539,21 +576,72 @@ func (c *application) ExecuteConfiguration(
 	) error {
 		return c.execute(
 			printer,
-			func(log logger.Logger) (role.Role, error) {
-				return c.roles.Init(printer, name, config, log)
+			func(
+				log logger.Logger,
+				m metrics.Registry,
+				s session.Sessions,
+			) (role.Role, error) {
+				return c.roles.Init(printer, name, config, log, m, s)
 			},
 			execCfg,
 		)
 	})
 }
 
+// handover hands current listeners over to a new process when the
+// handover signal is received, then asks current process to shut down
+// once the new process is serving
+func (c *application) handover(
+	log logger.Logger,
+	handovers <-chan os.Signal,
+	signals chan<- os.Signal,
+	done <-chan struct{},
+) {
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-handovers:
+		}
+
+		process, handoverErr := inherit.Handover(handoverReadyTimeout)
+
+		if handoverErr != nil {
+			log.Warningf("Failed to hand over listeners: %s", handoverErr)
+
+			continue
+		}
+
+		log.Infof("Listeners has been handed over to process %d, which "+
+			"is now serving", process.Pid)
+
+		select {
+		case signals <- syscall.SIGTERM:
+		case <-done:
+		}
+
+		return
+	}
+}
+
 func (c *application) execute(
 	printer print.Printer,
-	roleGen func(log logger.Logger) (role.Role, error),
+	roleGen func(
+		log logger.Logger,
+		m metrics.Registry,
+		s session.Sessions,
+	) (role.Role, error),
 	config ExecuteConfig,
 ) error {
 	var log logger.Logger
 
+	// Metrics will be kept across role reloads, so counters will not
+	// be reset when the role is respawned
+	registry := metrics.New()
+	sessions := session.New()
+	roleStatus := &status{}
+
 	// Buffer 1 for close notify because the shutdown function
 	// or `Unspawn` will try to write it. But since no one is
 	// reading it during that time, it will block and never returned.
@@ -599,6 +687,18 @@ func (c *application) execute(
 
 	defer golog.SetOutput(os.Stdout)
 
+	if config.Admin != "" {
+		adminServing, adminErr := admin.New(
+			config.Admin, config.AdminToken, log, registry, sessions,
+			roleStatus.Health).Serve()
+
+		if adminErr != nil {
+			return adminErr
+		}
+
+		defer adminServing.Close()
+	}
+
 	// If we can manually shutdown the application through the Shutdown
 	// channel, then there will be no need for monitering os signals as
 	// the Shutdown channel is designed for integration
@@ -608,6 +708,18 @@ func (c *application) execute(
 			syscall.SIGTERM, syscall.SIGHUP)
 
 		defer signal.Stop(signals)
+
+		if inherit.HandoverSignal != nil {
+			handovers := make(chan os.Signal, 1)
+			handoverDone := make(chan struct{})
+
+			signal.Notify(handovers, inherit.HandoverSignal)
+
+			defer signal.Stop(handovers)
+			defer close(handoverDone)
+
+			go c.handover(log, handovers, signals, handoverDone)
+		}
 	}
 
 	for {
@@ -615,7 +727,7 @@ func (c *application) execute(
 			break
 		}
 
-		r, rErr := roleGen(log)
+		r, rErr := roleGen(log, registry, sessions)
 
 		if rErr != nil {
 			return rErr
@@ -623,6 +735,8 @@ func (c *application) execute(
 
 		spawnErr := r.Spawn(closedNotify)
 
+		roleStatus.spawned(r, spawnErr)
+
 		if spawnErr != nil {
 			r.Unspawn()
 
@@ -636,6 +750,10 @@ func (c *application) execute(
 		default:
 		}
 
+		// Let the process which handed it's listeners over to us know we
+		// are serving now, so it can exit
+		inherit.Ready()
+
 		select {
 		case sig := <-signals:
 			switch sig {
@@ -652,6 +770,8 @@ func (c *application) execute(
 					breakLoop = true
 				}
 
+				roleStatus.unspawning()
+
 				unspawnErr := r.Unspawn()
 
 				if unspawnErr != nil {
@@ -664,6 +784,8 @@ func (c *application) execute(
 		case <-closedNotify:
 			breakLoop = true
 
+			roleStatus.unspawning()
+
 			unspawnErr := r.Unspawn()
 
 			if unspawnErr != nil {
@@ -676,6 +798,8 @@ func (c *application) execute(
 			// When shutdown channel send true, we shutdown
 			// the application, otherwise the application will
 			// be just reloaded
+			roleStatus.unspawning()
+
 			unspawnErr := r.Unspawn()
 
 			if unspawnErr != nil {
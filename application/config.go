@@ -44,6 +44,11 @@ type ExecuteConfig struct {
 	Debug     bool
 	LogFile   string
 	ParamFile string
-	Shutdown  SignalChan
-	Booted    SignalReceiveChan
+	Admin     string
+
+	// AdminToken is the Bearer token required by the Admin. Without it,
+	// the Admin can only serve on a loopback address
+	AdminToken string
+	Shutdown   SignalChan
+	Booted     SignalReceiveChan
 }
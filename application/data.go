@@ -30,6 +30,8 @@ const (
 
 	aboutPoweredByBanner = ` Powered by <COWARD:Name> v.<COWARD:Version>`
 
+	adminTokenEnv = "COWARD_ADMIN_TOKEN"
+
 	helpUsage = "Usage:\r\n\r\n" +
 		"%s [Execute Options ...] <Role> [Role Options ...]\r\n"
 
@@ -38,6 +40,9 @@ const (
 	helpUsageDaemon = `-daemon%sRun as daemon`
 	helpUsageLog    = `-log   %sWrite log to a file`
 	helpUsageParam  = `-param %sLoad Role Options from a file`
+	helpUsageAdmin  = `-admin %sServe metrics, health and sessions over ` +
+		`HTTP. To serve on a non-loopback address, set a token to the ` +
+		adminTokenEnv + ` environment variable`
 )
 
 // COWARD application errors
@@ -48,6 +53,12 @@ var (
 	ErrConfigFileMustBeSpecified = errors.New(
 		"Configuration file must be specified")
 
+	ErrAdminAddressMustBeSpecified = errors.New(
+		"Admin listen address must be specified")
+
+	ErrRoleNotSpawned = errors.New(
+		"Role is not spawned")
+
 	ErrUnknownExecuteOption = errors.New(
 		"At least one of the Execute Option is unknown")
 
@@ -0,0 +1,80 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package application
+
+import (
+	"sync"
+
+	"github.com/reinit/coward/common/role"
+)
+
+// status records the running status of current role
+type status struct {
+	role     role.Role
+	spawnErr error
+	lock     sync.Mutex
+}
+
+// spawned records the result of role spawn
+func (s *status) spawned(r role.Role, spawnErr error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if spawnErr != nil {
+		s.role = nil
+	} else {
+		s.role = r
+	}
+
+	s.spawnErr = spawnErr
+}
+
+// unspawning marks the role as no longer running. Must be called before
+// calling `Unspawn` so no health check will run during unspawn
+func (s *status) unspawning() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.role = nil
+	s.spawnErr = nil
+}
+
+// Health returns whether or not the role is spawned and healthy
+func (s *status) Health() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.spawnErr != nil {
+		return s.spawnErr
+	}
+
+	if s.role == nil {
+		return ErrRoleNotSpawned
+	}
+
+	checker, isChecker := s.role.(role.HealthChecker)
+
+	if !isChecker {
+		return nil
+	}
+
+	return checker.Health()
+}
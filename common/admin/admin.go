@@ -0,0 +1,350 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/inherit"
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
+)
+
+// Errors
+var (
+	ErrAlreadyServing = errors.New(
+		"Already serving")
+
+	ErrNotServing = errors.New(
+		"Not serving")
+
+	ErrTokenRequired = errors.New(
+		"A token is required to serve on a non-loopback address")
+)
+
+// Consts
+const (
+	readTimeout  = 10 * time.Second
+	writeTimeout = 30 * time.Second
+
+	sessionsPath = "/sessions/"
+	healthzPath  = "/healthz"
+
+	authorizationPrefix = "Bearer "
+)
+
+// Health returns nil when the application is healthy, or the reason
+// why it's not
+type Health func() error
+
+// sessionInfo is the information of a Session that will be sent to the
+// admin client
+type sessionInfo struct {
+	session.Info
+
+	Age float64 `json:"age"`
+}
+
+// Admin is the administration HTTP server
+type Admin interface {
+	Serve() (Serving, error)
+}
+
+// Serving is a serving Admin
+type Serving interface {
+	Listening() net.Addr
+	Close() error
+}
+
+// admin implements Admin
+type admin struct {
+	listen   string
+	token    string
+	logger   logger.Logger
+	registry metrics.Registry
+	sessions session.Sessions
+	health   Health
+	serving  bool
+	lock     sync.Mutex
+}
+
+// serving implements Serving
+type serving struct {
+	admin    *admin
+	listener net.Listener
+	server   *http.Server
+	done     chan struct{}
+}
+
+// New creates a new Admin. When token is not empty, all requests except
+// the ones to /healthz must carry it as a Bearer token. Without a token,
+// the Admin can only serve on a loopback address
+func New(
+	listen string,
+	token string,
+	log logger.Logger,
+	registry metrics.Registry,
+	sessions session.Sessions,
+	health Health,
+) Admin {
+	return &admin{
+		listen:   listen,
+		token:    token,
+		logger:   log.Context("Admin"),
+		registry: registry,
+		sessions: sessions,
+		health:   health,
+		serving:  false,
+		lock:     sync.Mutex{},
+	}
+}
+
+// authorized returns whether or not the request carries the token
+func (a *admin) authorized(r *http.Request) bool {
+	if a.token == "" || r.URL.Path == healthzPath {
+		return true
+	}
+
+	auth := r.Header.Get("Authorization")
+
+	if !strings.HasPrefix(auth, authorizationPrefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(
+		[]byte(strings.TrimPrefix(auth, authorizationPrefix)),
+		[]byte(a.token)) == 1
+}
+
+// guard only passes authorized requests to the given handler
+func (a *admin) guard(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.authorized(r) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// metrics serves all collected metrics in Prometheus text format
+func (a *admin) metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	wErr := metrics.WriteText(w, a.registry)
+
+	if wErr != nil {
+		a.logger.Debugf("Failed to write metrics due to error: %s", wErr)
+	}
+}
+
+// healthz reports whether or not the application is healthy
+func (a *admin) healthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	hErr := a.health()
+
+	if hErr != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(hErr.Error() + "\n"))
+
+		return
+	}
+
+	w.Write([]byte("OK\n"))
+}
+
+// listSessions lists all live sessions in JSON format
+func (a *admin) listSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	now := time.Now()
+	infos := make([]sessionInfo, 0, 64)
+
+	a.sessions.List(func(i session.Info) {
+		infos = append(infos, sessionInfo{
+			Info: i,
+			Age:  now.Sub(i.Started).Seconds(),
+		})
+	})
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ID < infos[j].ID
+	})
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	eErr := json.NewEncoder(w).Encode(infos)
+
+	if eErr != nil {
+		a.logger.Debugf("Failed to write sessions due to error: %s", eErr)
+	}
+}
+
+// killSession kills the session which specified by the request path
+func (a *admin) killSession(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id, idErr := strconv.ParseUint(
+		strings.TrimPrefix(r.URL.Path, sessionsPath), 10, 64)
+
+	if idErr != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("Invalid session ID\n"))
+
+		return
+	}
+
+	kErr := a.sessions.Kill(session.ID(id))
+
+	switch kErr {
+	case nil:
+		a.logger.Infof("Session %d has been killed", id)
+
+		w.Write([]byte("OK\n"))
+
+	case session.ErrNotFound:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(kErr.Error() + "\n"))
+
+	case session.ErrNotKillable:
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(kErr.Error() + "\n"))
+
+	default:
+		a.logger.Warningf("Failed to kill session %d due to error: %s",
+			id, kErr)
+
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(kErr.Error() + "\n"))
+	}
+}
+
+// Serve starts serving
+func (a *admin) Serve() (Serving, error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.serving {
+		return nil, ErrAlreadyServing
+	}
+
+	listenAddr, resolveErr := net.ResolveTCPAddr("tcp", a.listen)
+
+	if resolveErr != nil {
+		return nil, resolveErr
+	}
+
+	if a.token == "" &&
+		(listenAddr.IP == nil || !listenAddr.IP.IsLoopback()) {
+		return nil, ErrTokenRequired
+	}
+
+	listener, listenErr := inherit.ListenTCP(listenAddr)
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/metrics", a.metrics)
+	mux.HandleFunc(healthzPath, a.healthz)
+	mux.HandleFunc("/sessions", a.listSessions)
+	mux.HandleFunc(sessionsPath, a.killSession)
+
+	s := serving{
+		admin:    a,
+		listener: listener,
+		server: &http.Server{
+			Handler:      a.guard(mux),
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
+		},
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		sErr := s.server.Serve(listener)
+
+		if sErr != nil && sErr != http.ErrServerClosed {
+			a.logger.Warningf("Server has stopped due to error: %s", sErr)
+		}
+	}()
+
+	a.serving = true
+
+	a.logger.Infof("Serving on \"%s\"", listener.Addr())
+
+	return s, nil
+}
+
+// Listening returns the address that the server is listening to
+func (s serving) Listening() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close shuts down the server
+func (s serving) Close() error {
+	s.admin.lock.Lock()
+	defer s.admin.lock.Unlock()
+
+	if !s.admin.serving {
+		return ErrNotServing
+	}
+
+	inherit.Forget(s.listener)
+
+	cErr := s.server.Close()
+
+	<-s.done
+
+	s.admin.serving = false
+
+	return cErr
+}
@@ -0,0 +1,287 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
+)
+
+func testAdminGet(t *testing.T, url string) (int, string) {
+	resp, respErr := http.Get(url)
+
+	if respErr != nil {
+		t.Fatal("Failed to request due to error:", respErr)
+	}
+
+	defer resp.Body.Close()
+
+	body, bodyErr := ioutil.ReadAll(resp.Body)
+
+	if bodyErr != nil {
+		t.Fatal("Failed to read respond due to error:", bodyErr)
+	}
+
+	return resp.StatusCode, string(body)
+}
+
+func testAdminDelete(t *testing.T, url string) int {
+	req, reqErr := http.NewRequest(http.MethodDelete, url, nil)
+
+	if reqErr != nil {
+		t.Fatal("Failed to build request due to error:", reqErr)
+	}
+
+	resp, respErr := http.DefaultClient.Do(req)
+
+	if respErr != nil {
+		t.Fatal("Failed to request due to error:", respErr)
+	}
+
+	defer resp.Body.Close()
+
+	return resp.StatusCode
+}
+
+func TestAdmin(t *testing.T) {
+	healthy := int32(1)
+	registry := metrics.New()
+
+	registry.Counter("test_total", "Test").Add(1)
+
+	s, sErr := New("127.0.0.1:0", "", logger.NewDitch(), registry,
+		session.NewDitch(), func() error {
+			if atomic.LoadInt32(&healthy) == 1 {
+				return nil
+			}
+
+			return errors.New("Down")
+		}).Serve()
+
+	if sErr != nil {
+		t.Error("Failed to serve due to error:", sErr)
+
+		return
+	}
+
+	defer s.Close()
+
+	url := "http://" + s.Listening().String()
+
+	code, body := testAdminGet(t, url+"/healthz")
+
+	if code != http.StatusOK {
+		t.Errorf("Expecting status %d, got %d", http.StatusOK, code)
+
+		return
+	}
+
+	atomic.StoreInt32(&healthy, 0)
+
+	code, body = testAdminGet(t, url+"/healthz")
+
+	if code != http.StatusServiceUnavailable || body != "Down\n" {
+		t.Errorf("Expecting status %d with \"Down\", got %d with %q",
+			http.StatusServiceUnavailable, code, body)
+
+		return
+	}
+
+	code, body = testAdminGet(t, url+"/metrics")
+
+	if code != http.StatusOK || !strings.Contains(body, "test_total 1\n") {
+		t.Errorf("Unexpected metrics respond %d: %q", code, body)
+
+		return
+	}
+}
+
+func TestAdminSessions(t *testing.T) {
+	killed := int32(0)
+	sessions := session.New()
+
+	killable := sessions.With("test").Open(
+		session.Client, 0, "127.0.0.1:1234", "", func() error {
+			atomic.StoreInt32(&killed, 1)
+
+			return nil
+		})
+	defer killable.Close()
+
+	unkillable := sessions.With("test").Open(
+		session.Channel, killable.ID(), "", "Test", nil)
+	defer unkillable.Close()
+
+	killable.Received(10)
+	killable.Sent(20)
+
+	s, sErr := New("127.0.0.1:0", "", logger.NewDitch(), metrics.NewDitch(),
+		sessions, func() error { return nil }).Serve()
+
+	if sErr != nil {
+		t.Error("Failed to serve due to error:", sErr)
+
+		return
+	}
+
+	defer s.Close()
+
+	url := "http://" + s.Listening().String()
+
+	code, body := testAdminGet(t, url+"/sessions")
+
+	if code != http.StatusOK {
+		t.Errorf("Expecting status %d, got %d", http.StatusOK, code)
+
+		return
+	}
+
+	infos := []sessionInfo{}
+
+	jErr := json.Unmarshal([]byte(body), &infos)
+
+	if jErr != nil {
+		t.Error("Failed to decode sessions due to error:", jErr)
+
+		return
+	}
+
+	if len(infos) != 2 {
+		t.Errorf("Expecting 2 sessions, got %d", len(infos))
+
+		return
+	}
+
+	if infos[0].ID != killable.ID() || infos[0].Kind != "client" ||
+		infos[0].Role != "test" || infos[0].Inbound != 10 ||
+		infos[0].Outbound != 20 {
+		t.Errorf("Unexpected session %+v", infos[0])
+
+		return
+	}
+
+	if infos[1].Parent != killable.ID() || infos[1].Destination != "Test" {
+		t.Errorf("Unexpected session %+v", infos[1])
+
+		return
+	}
+
+	tests := []struct {
+		Path   string
+		Expect int
+	}{
+		{"/sessions/abc", http.StatusBadRequest},
+		{"/sessions/99999", http.StatusNotFound},
+		{"/sessions/" + strconv.FormatUint(
+			uint64(unkillable.ID()), 10), http.StatusConflict},
+		{"/sessions/" + strconv.FormatUint(
+			uint64(killable.ID()), 10), http.StatusOK},
+	}
+
+	for i, test := range tests {
+		code := testAdminDelete(t, url+test.Path)
+
+		if code != test.Expect {
+			t.Errorf("Test %d: Expecting status %d, got %d",
+				i, test.Expect, code)
+
+			return
+		}
+	}
+
+	if atomic.LoadInt32(&killed) != 1 {
+		t.Error("Session should be killed")
+
+		return
+	}
+}
+
+func TestAdminToken(t *testing.T) {
+	_, sErr := New("0.0.0.0:0", "", logger.NewDitch(), metrics.NewDitch(),
+		session.NewDitch(), func() error { return nil }).Serve()
+
+	if sErr != ErrTokenRequired {
+		t.Errorf("Expecting error %q, got %v", ErrTokenRequired, sErr)
+
+		return
+	}
+
+	s, sErr := New("127.0.0.1:0", "secret", logger.NewDitch(),
+		metrics.NewDitch(), session.NewDitch(),
+		func() error { return nil }).Serve()
+
+	if sErr != nil {
+		t.Error("Failed to serve due to error:", sErr)
+
+		return
+	}
+
+	defer s.Close()
+
+	url := "http://" + s.Listening().String()
+
+	if code, _ := testAdminGet(t, url+"/healthz"); code != http.StatusOK {
+		t.Errorf("Expecting status %d, got %d", http.StatusOK, code)
+
+		return
+	}
+
+	if code, _ := testAdminGet(t, url+"/sessions"); code !=
+		http.StatusUnauthorized {
+		t.Errorf("Expecting status %d, got %d",
+			http.StatusUnauthorized, code)
+
+		return
+	}
+
+	req, reqErr := http.NewRequest(http.MethodGet, url+"/sessions", nil)
+
+	if reqErr != nil {
+		t.Fatal("Failed to build request due to error:", reqErr)
+	}
+
+	req.Header.Set("Authorization", "Bearer secret")
+
+	resp, respErr := http.DefaultClient.Do(req)
+
+	if respErr != nil {
+		t.Fatal("Failed to request due to error:", respErr)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expecting status %d, got %d", http.StatusOK,
+			resp.StatusCode)
+
+		return
+	}
+}
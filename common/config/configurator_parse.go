@@ -262,7 +262,10 @@ func (c *configurator) Parse(parameters []byte) error {
 		})
 	}
 
-	for _, sliceRefers := range slices {
+	sliceStarts := make([]int, len(slices))
+
+	for sIdx, sliceRefers := range slices {
+		sliceStarts[sIdx] = sliceRefers.Field.Len()
 		sliceData := sliceRefers.Field.Slice(0, sliceRefers.Field.Len())
 
 		for _, sliceReferSlice := range sliceRefers.Slice {
@@ -315,5 +318,19 @@ func (c *configurator) Parse(parameters []byte) error {
 			parameters)
 	}
 
+	// Items of non-pointer slices are copies of the parsed items, sync them
+	// again so they carry the changes made by Verify. Inner slices must be
+	// synced before the slices that contain them
+	for sIdx := len(slices) - 1; sIdx >= 0; sIdx-- {
+		if slices[sIdx].Indirect {
+			continue
+		}
+
+		for iIdx, item := range slices[sIdx].Slice {
+			slices[sIdx].Field.Index(
+				sliceStarts[sIdx] + iIdx).Set(item.Elem())
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,110 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"testing"
+)
+
+type testParseNested struct {
+	enabled bool
+	Name    string `cfg:"n,-name:Name."`
+}
+
+func (t *testParseNested) Verify() error {
+	t.enabled = true
+
+	return nil
+}
+
+type testParseSub struct {
+	verified bool
+	ID       uint8 `cfg:"i,-id:ID."`
+}
+
+func (t *testParseSub) Verify() error {
+	t.verified = true
+
+	return nil
+}
+
+type testParseItem struct {
+	verified bool
+	ID       uint8           `cfg:"i,-id:ID."`
+	Nested   testParseNested `cfg:"s,-nested:Nested."`
+	Subs     []testParseSub  `cfg:"u,-subs:Sub items."`
+}
+
+func (t *testParseItem) Verify() error {
+	t.verified = true
+
+	return nil
+}
+
+type testParseRoot struct {
+	Items []testParseItem `cfg:"t,-items:Items."`
+}
+
+func TestParseSliceItemVerify(t *testing.T) {
+	root := &testParseRoot{}
+
+	cfg, importErr := Import(root)
+
+	if importErr != nil {
+		t.Error("Failed to import configuration due to error:", importErr)
+
+		return
+	}
+
+	parseErr := cfg.Parse([]byte(
+		"-t {-i 1 -s {-n a} -u {-i 3}} {-i 2}"))
+
+	if parseErr != nil {
+		t.Error("Failed to parse due to error:", parseErr)
+
+		return
+	}
+
+	if len(root.Items) != 2 {
+		t.Errorf("Expecting 2 items, got %d", len(root.Items))
+
+		return
+	}
+
+	if !root.Items[0].verified || !root.Items[1].verified {
+		t.Error("Changes made by Verify are lost in the slice items")
+
+		return
+	}
+
+	if !root.Items[0].Nested.enabled || root.Items[0].Nested.Name != "a" {
+		t.Error("Changes made by Verify are lost in the nested item")
+
+		return
+	}
+
+	if len(root.Items[0].Subs) != 1 || !root.Items[0].Subs[0].verified ||
+		root.Items[0].Subs[0].ID != 3 {
+		t.Error("Changes made by Verify are lost in the inner slice items")
+
+		return
+	}
+}
@@ -84,6 +84,12 @@ func (vr valueReflect) PointerValue() valueReflect {
 		return newValueReflect(vr.Value)
 	}
 
+	// Refer to the value itself when possible, so changes made through
+	// the pointer (i.e. to a struct field) will be kept
+	if vr.Value.CanAddr() {
+		return newValueReflect(vr.Value.Addr())
+	}
+
 	ptr := reflect.New(vr.Value.Type())
 
 	ptr.Elem().Set(vr.Value)
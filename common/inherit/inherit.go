@@ -0,0 +1,580 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package inherit
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors
+var (
+	ErrNothingToHandover = errors.New(
+		"There is no listener to hand over")
+
+	ErrHandoverNotReady = errors.New(
+		"The new process has failed to get ready in time")
+)
+
+// Consts
+const (
+	// listenFDsStart is the first inherited file descriptor, right after
+	// stdin, stdout and stderr
+	listenFDsStart = 3
+
+	// HandoverEnv is the environment variable that tells the new process
+	// how many listeners it has inherited from the old one
+	HandoverEnv = "COWARD_LISTEN_FDS"
+
+	// ReadyEnv is the environment variable that tells the new process
+	// which file descriptor to write to once it's ready to serve
+	ReadyEnv = "COWARD_HANDOVER_READY"
+)
+
+// filer is a listener that can export it's file descriptor
+type filer interface {
+	File() (*os.File, error)
+}
+
+// inherited is a listener that inherited from the parent process
+type inherited struct {
+	stream net.Listener
+	packet net.PacketConn
+}
+
+// registry keeps the inherited listeners that are not yet adopted, the
+// listeners that are currently active, and the ports on which the
+// unspecified addresses of IPv4 and IPv6 must be listened separately
+type registry struct {
+	lock      sync.Mutex
+	loadOnce  sync.Once
+	inherited []inherited
+	active    map[filer]struct{}
+	separated map[int]struct{}
+}
+
+var sockets = registry{
+	lock:      sync.Mutex{},
+	loadOnce:  sync.Once{},
+	inherited: nil,
+	active:    make(map[filer]struct{}, 16),
+	separated: make(map[int]struct{}, 16),
+}
+
+var readyOnce = sync.Once{}
+
+// inheritedCount returns how many listeners are given by the parent, either
+// by systemd socket activation or by a handover. The related environment
+// variables will be removed so they will not be passed to our children
+func inheritedCount() int {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+		os.Unsetenv(HandoverEnv)
+	}()
+
+	count, countErr := strconv.Atoi(os.Getenv(HandoverEnv))
+
+	if countErr == nil {
+		return count
+	}
+
+	pid, pidErr := strconv.Atoi(os.Getenv("LISTEN_PID"))
+
+	if pidErr != nil || pid != os.Getpid() {
+		return 0
+	}
+
+	count, countErr = strconv.Atoi(os.Getenv("LISTEN_FDS"))
+
+	if countErr != nil {
+		return 0
+	}
+
+	return count
+}
+
+// load loads the inherited listeners
+func (r *registry) load() {
+	count := inheritedCount()
+
+	for fd := listenFDsStart; fd < listenFDsStart+count; fd++ {
+		file := os.NewFile(uintptr(fd), "listener "+strconv.Itoa(fd))
+
+		if file == nil {
+			continue
+		}
+
+		// Both FileListener and FilePacketConn duplicates the file, so the
+		// original one can be closed right after
+		if stream, streamErr := net.FileListener(file); streamErr == nil {
+			r.inherited = append(r.inherited, inherited{
+				stream: stream,
+				packet: nil,
+			})
+		} else if packet, packetErr := net.FilePacketConn(
+			file); packetErr == nil {
+			r.inherited = append(r.inherited, inherited{
+				stream: nil,
+				packet: packet,
+			})
+		}
+
+		file.Close()
+	}
+}
+
+// take removes and returns the first inherited listener that matches
+func (r *registry) take(match func(inherited) bool) (inherited, bool) {
+	r.loadOnce.Do(r.load)
+
+	for iIdx := range r.inherited {
+		if !match(r.inherited[iIdx]) {
+			continue
+		}
+
+		result := r.inherited[iIdx]
+
+		r.inherited = append(r.inherited[:iIdx], r.inherited[iIdx+1:]...)
+
+		return result, true
+	}
+
+	return inherited{}, false
+}
+
+func sameIPPort(ip1 net.IP, port1 int, ip2 net.IP, port2 int) bool {
+	if port1 != port2 || port1 == 0 {
+		return false
+	}
+
+	if ip1 == nil || ip1.IsUnspecified() {
+		return ip2 == nil || ip2.IsUnspecified()
+	}
+
+	return ip1.Equal(ip2)
+}
+
+// sameFamily returns whether or not both IPs are of the same IP family
+func sameFamily(ip1 net.IP, ip2 net.IP) bool {
+	if ip1 == nil || ip2 == nil {
+		return ip1 == nil && ip2 == nil
+	}
+
+	return (ip1.To4() == nil) == (ip2.To4() == nil)
+}
+
+// Separate tells that both "0.0.0.0" and "::" are going to be listened on
+// the given port when both of them are found in the ips. Each of them will
+// then be listened for it's own IP family only, so they can be listened
+// at the same time. Otherwise, the unspecified address will be listened
+// for both IP families
+func Separate(ips []net.IP, port uint16) {
+	v4, v6 := false, false
+
+	for iIdx := range ips {
+		if ips[iIdx] == nil || !ips[iIdx].IsUnspecified() {
+			continue
+		}
+
+		if ips[iIdx].To4() != nil {
+			v4 = true
+		} else {
+			v6 = true
+		}
+	}
+
+	if !v4 || !v6 {
+		return
+	}
+
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	sockets.separated[int(port)] = struct{}{}
+}
+
+// family returns the network name that should be used to listen on the
+// given address. Unless it's port is Separated, unspecified address will
+// be listened for both IP families
+func family(network string, ip net.IP, port int) string {
+	if ip == nil || !ip.IsUnspecified() {
+		return network
+	}
+
+	if _, separated := sockets.separated[port]; !separated {
+		return network
+	}
+
+	if ip.To4() != nil {
+		return network + "4"
+	}
+
+	return network + "6"
+}
+
+// adopt takes the inherited listener that is listening on the given
+// address. An unspecified address can be listened for both IP families, so
+// it will match the inherited listener of the other IP family (for example,
+// a "[::]" socket given by systemd for "0.0.0.0") if none of the same IP
+// family is found
+func adopt(ip net.IP, port int, addr func(inherited) net.Addr) (
+	inherited, bool) {
+	matcher := func(sameFamilyOnly bool) func(inherited) bool {
+		return func(i inherited) bool {
+			var lIP net.IP
+			var lPort int
+
+			switch a := addr(i).(type) {
+			case *net.TCPAddr:
+				lIP, lPort = a.IP, a.Port
+
+			case *net.UDPAddr:
+				lIP, lPort = a.IP, a.Port
+
+			default:
+				return false
+			}
+
+			if sameFamilyOnly && !sameFamily(ip, lIP) {
+				return false
+			}
+
+			return sameIPPort(ip, port, lIP, lPort)
+		}
+	}
+
+	found, ok := sockets.take(matcher(true))
+
+	if ok {
+		return found, true
+	}
+
+	return sockets.take(matcher(false))
+}
+
+// ListenTCP adopts an inherited TCP listener that is listening on the
+// given address, or creates a new one when there is none
+func ListenTCP(addr *net.TCPAddr) (*net.TCPListener, error) {
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	found, ok := adopt(addr.IP, addr.Port, func(i inherited) net.Addr {
+		l, isTCP := i.stream.(*net.TCPListener)
+
+		if !isTCP {
+			return nil
+		}
+
+		return l.Addr()
+	})
+
+	if ok {
+		sockets.active[found.stream.(*net.TCPListener)] = struct{}{}
+
+		return found.stream.(*net.TCPListener), nil
+	}
+
+	listener, listenErr := net.ListenTCP(
+		family("tcp", addr.IP, addr.Port), addr)
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	sockets.active[listener] = struct{}{}
+
+	return listener, nil
+}
+
+// ListenUDP adopts an inherited UDP socket that is bound on the given
+// address, or creates a new one when there is none
+func ListenUDP(addr *net.UDPAddr) (*net.UDPConn, error) {
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	found, ok := adopt(addr.IP, addr.Port, func(i inherited) net.Addr {
+		l, isUDP := i.packet.(*net.UDPConn)
+
+		if !isUDP {
+			return nil
+		}
+
+		return l.LocalAddr()
+	})
+
+	if ok {
+		sockets.active[found.packet.(*net.UDPConn)] = struct{}{}
+
+		return found.packet.(*net.UDPConn), nil
+	}
+
+	listener, listenErr := net.ListenUDP(
+		family("udp", addr.IP, addr.Port), addr)
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	sockets.active[listener] = struct{}{}
+
+	return listener, nil
+}
+
+// AdoptUnix adopts an inherited unix domain socket listener that is
+// listening on the given path. The socket file will not be removed when
+// the adopted listener is closed, as it is created by someone else
+func AdoptUnix(path string) (*net.UnixListener, bool) {
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	found, ok := sockets.take(func(i inherited) bool {
+		l, isUnix := i.stream.(*net.UnixListener)
+
+		if !isUnix {
+			return false
+		}
+
+		return l.Addr().String() == path
+	})
+
+	if !ok {
+		return nil, false
+	}
+
+	listener := found.stream.(*net.UnixListener)
+
+	listener.SetUnlinkOnClose(false)
+
+	sockets.active[listener] = struct{}{}
+
+	return listener, true
+}
+
+// ListenUnix creates a new unix domain socket listener
+func ListenUnix(addr *net.UnixAddr) (*net.UnixListener, error) {
+	listener, listenErr := net.ListenUnix("unix", addr)
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	sockets.active[listener] = struct{}{}
+
+	return listener, nil
+}
+
+// Forget removes a closed listener from the active listeners, so it will
+// not be handed over
+func Forget(listener interface{}) {
+	f, isFiler := listener.(filer)
+
+	if !isFiler {
+		return
+	}
+
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	delete(sockets.active, f)
+}
+
+// Handover starts a new process of the current executable with the same
+// arguments, and hands all the active listeners to it, so it can keep
+// serving on them after the current process has exited.
+//
+// Handover returns after the new process has called Ready. If it failed
+// to do so within the readyTimeout, it will be killed and the listeners
+// will stay with the current process.
+//
+// The socket files of the unix domain socket listeners will no longer
+// be removed when they are closed by the current process
+func Handover(readyTimeout time.Duration) (*os.Process, error) {
+	process, ready, startErr := handover()
+
+	if startErr != nil {
+		return nil, startErr
+	}
+
+	defer ready.Close()
+
+	readyErr := waitReady(ready, readyTimeout)
+
+	if readyErr != nil {
+		process.Kill()
+		process.Wait()
+
+		return nil, readyErr
+	}
+
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	for listener := range sockets.active {
+		if unix, isUnix := listener.(*net.UnixListener); isUnix {
+			unix.SetUnlinkOnClose(false)
+		}
+	}
+
+	return process, nil
+}
+
+// handover starts the new process with all the active listeners, and
+// returns the read end of the pipe which the new process will write to
+// once it's ready
+func handover() (*os.Process, *os.File, error) {
+	sockets.lock.Lock()
+	defer sockets.lock.Unlock()
+
+	if len(sockets.active) <= 0 {
+		return nil, nil, ErrNothingToHandover
+	}
+
+	files := make([]*os.File, 0, len(sockets.active)+1)
+
+	defer func() {
+		for fIdx := range files {
+			files[fIdx].Close()
+		}
+	}()
+
+	for listener := range sockets.active {
+		file, fileErr := listener.File()
+
+		if fileErr != nil {
+			return nil, nil, fileErr
+		}
+
+		files = append(files, file)
+	}
+
+	executable, lookErr := exec.LookPath(os.Args[0])
+
+	if lookErr != nil {
+		return nil, nil, lookErr
+	}
+
+	ready, readyWriter, pipeErr := os.Pipe()
+
+	if pipeErr != nil {
+		return nil, nil, pipeErr
+	}
+
+	listeners := len(files)
+
+	// The write end goes right after the listeners, and will be closed
+	// with them once the new process is started
+	files = append(files, readyWriter)
+
+	env := make([]string, 0, len(os.Environ())+2)
+
+	for _, e := range os.Environ() {
+		if strings.HasPrefix(e, HandoverEnv+"=") ||
+			strings.HasPrefix(e, ReadyEnv+"=") {
+			continue
+		}
+
+		env = append(env, e)
+	}
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(env,
+		HandoverEnv+"="+strconv.Itoa(listeners),
+		ReadyEnv+"="+strconv.Itoa(listenFDsStart+listeners))
+
+	startErr := cmd.Start()
+
+	if startErr != nil {
+		ready.Close()
+
+		return nil, nil, startErr
+	}
+
+	return cmd.Process, ready, nil
+}
+
+// waitReady waits for the new process to write to the ready pipe. The
+// pipe will be closed without being written if the new process exited
+func waitReady(ready *os.File, timeout time.Duration) error {
+	result := make(chan error, 1)
+
+	go func() {
+		_, rErr := ready.Read(make([]byte, 1))
+
+		result <- rErr
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case rErr := <-result:
+		if rErr != nil {
+			return ErrHandoverNotReady
+		}
+
+		return nil
+
+	case <-timer.C:
+		return ErrHandoverNotReady
+	}
+}
+
+// Ready tells the process which has handed it's listeners over to the
+// current process that the current process is now serving, so it can
+// exit. It does nothing when the current process is not started by a
+// Handover
+func Ready() {
+	readyOnce.Do(func() {
+		fd, fdErr := strconv.Atoi(os.Getenv(ReadyEnv))
+
+		os.Unsetenv(ReadyEnv)
+
+		if fdErr != nil || fd < listenFDsStart {
+			return
+		}
+
+		ready := os.NewFile(uintptr(fd), "handover ready")
+
+		if ready == nil {
+			return
+		}
+
+		defer ready.Close()
+
+		ready.Write([]byte{1})
+	})
+}
@@ -0,0 +1,340 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package inherit
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInheritedCount(t *testing.T) {
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("LISTEN_FDS", "2")
+
+	if count := inheritedCount(); count != 2 {
+		t.Errorf("Expecting %d inherited listeners, got %d", 2, count)
+
+		return
+	}
+
+	if os.Getenv("LISTEN_FDS") != "" {
+		t.Error("Expecting LISTEN_FDS to be removed")
+
+		return
+	}
+
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	os.Setenv("LISTEN_FDS", "2")
+
+	if count := inheritedCount(); count != 0 {
+		t.Errorf("Expecting %d inherited listeners, got %d", 0, count)
+
+		return
+	}
+
+	os.Setenv(HandoverEnv, "3")
+
+	if count := inheritedCount(); count != 3 {
+		t.Errorf("Expecting %d inherited listeners, got %d", 3, count)
+
+		return
+	}
+}
+
+func TestListenTCPAdopt(t *testing.T) {
+	original, listenErr := net.ListenTCP("tcp", &net.TCPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: 0,
+		Zone: "",
+	})
+
+	if listenErr != nil {
+		t.Error("Failed to listen due to error:", listenErr)
+
+		return
+	}
+
+	defer original.Close()
+
+	file, fileErr := original.File()
+
+	if fileErr != nil {
+		t.Error("Failed to get listener file due to error:", fileErr)
+
+		return
+	}
+
+	duplicated, dupErr := net.FileListener(file)
+
+	file.Close()
+
+	if dupErr != nil {
+		t.Error("Failed to duplicate listener due to error:", dupErr)
+
+		return
+	}
+
+	sockets.loadOnce.Do(func() {})
+
+	sockets.inherited = append(sockets.inherited, inherited{
+		stream: duplicated,
+		packet: nil,
+	})
+
+	adopted, adoptErr := ListenTCP(&net.TCPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: original.Addr().(*net.TCPAddr).Port,
+		Zone: "",
+	})
+
+	if adoptErr != nil {
+		t.Error("Failed to adopt listener due to error:", adoptErr)
+
+		return
+	}
+
+	defer adopted.Close()
+
+	if adopted != duplicated {
+		t.Error("Expecting the inherited listener to be adopted")
+
+		return
+	}
+
+	if len(sockets.inherited) != 0 {
+		t.Errorf("Expecting no inherited listener left, got %d",
+			len(sockets.inherited))
+
+		return
+	}
+
+	if _, ok := sockets.active[adopted]; !ok {
+		t.Error("Expecting adopted listener to be active")
+
+		return
+	}
+
+	Forget(adopted)
+
+	if _, ok := sockets.active[adopted]; ok {
+		t.Error("Expecting forgotten listener to be inactive")
+
+		return
+	}
+}
+
+func TestListenTCPAdoptUnspecified(t *testing.T) {
+	original, listenErr := net.ListenTCP("tcp", &net.TCPAddr{
+		IP:   net.ParseIP("::"),
+		Port: 0,
+		Zone: "",
+	})
+
+	if listenErr != nil {
+		t.Error("Failed to listen due to error:", listenErr)
+
+		return
+	}
+
+	defer original.Close()
+
+	file, fileErr := original.File()
+
+	if fileErr != nil {
+		t.Error("Failed to get listener file due to error:", fileErr)
+
+		return
+	}
+
+	duplicated, dupErr := net.FileListener(file)
+
+	file.Close()
+
+	if dupErr != nil {
+		t.Error("Failed to duplicate listener due to error:", dupErr)
+
+		return
+	}
+
+	sockets.loadOnce.Do(func() {})
+
+	sockets.inherited = append(sockets.inherited, inherited{
+		stream: duplicated,
+		packet: nil,
+	})
+
+	adopted, adoptErr := ListenTCP(&net.TCPAddr{
+		IP:   net.ParseIP("0.0.0.0"),
+		Port: original.Addr().(*net.TCPAddr).Port,
+		Zone: "",
+	})
+
+	if adoptErr != nil {
+		t.Error("Failed to adopt listener due to error:", adoptErr)
+
+		return
+	}
+
+	defer Forget(adopted)
+	defer adopted.Close()
+
+	if adopted != duplicated {
+		t.Error("Expecting the inherited listener to be adopted")
+
+		return
+	}
+}
+
+func TestListenTCPDualStack(t *testing.T) {
+	single, singleErr := ListenTCP(&net.TCPAddr{
+		IP:   net.ParseIP("0.0.0.0"),
+		Port: 0,
+		Zone: "",
+	})
+
+	if singleErr != nil {
+		t.Error("Failed to listen due to error:", singleErr)
+
+		return
+	}
+
+	port := single.Addr().(*net.TCPAddr).Port
+
+	conn, dialErr := net.Dial("tcp", net.JoinHostPort(
+		"::1", strconv.Itoa(port)))
+
+	if dialErr == nil {
+		conn.Close()
+	} else {
+		// The system may have no IPv6 support at all
+		t.Log("Failed to dial IPv6 due to error:", dialErr)
+	}
+
+	Forget(single)
+	single.Close()
+
+	Separate([]net.IP{
+		net.ParseIP("0.0.0.0"),
+		net.ParseIP("::"),
+	}, uint16(port))
+
+	v4, v4Err := ListenTCP(&net.TCPAddr{
+		IP:   net.ParseIP("0.0.0.0"),
+		Port: port,
+		Zone: "",
+	})
+
+	if v4Err != nil {
+		t.Error("Failed to listen due to error:", v4Err)
+
+		return
+	}
+
+	defer Forget(v4)
+	defer v4.Close()
+
+	v6, v6Err := ListenTCP(&net.TCPAddr{
+		IP:   net.ParseIP("::"),
+		Port: port,
+		Zone: "",
+	})
+
+	if errors.Is(v6Err, syscall.EADDRINUSE) {
+		t.Error("Expecting IPv4 and IPv6 to be listened separately")
+
+		return
+	}
+
+	if v6Err != nil {
+		// The system may have no IPv6 support at all
+		t.Log("Failed to listen IPv6 due to error:", v6Err)
+
+		return
+	}
+
+	defer Forget(v6)
+	defer v6.Close()
+}
+
+func TestHandoverReady(t *testing.T) {
+	ready, readyWriter, pipeErr := os.Pipe()
+
+	if pipeErr != nil {
+		t.Error("Failed to create pipe due to error:", pipeErr)
+
+		return
+	}
+
+	defer ready.Close()
+
+	// Ready takes over the file descriptor, so give it a duplicated one
+	fd, dupErr := syscall.Dup(int(readyWriter.Fd()))
+
+	readyWriter.Close()
+
+	if dupErr != nil {
+		t.Error("Failed to duplicate file descriptor due to error:", dupErr)
+
+		return
+	}
+
+	os.Setenv(ReadyEnv, strconv.Itoa(fd))
+
+	Ready()
+
+	if os.Getenv(ReadyEnv) != "" {
+		t.Errorf("Expecting %s to be removed", ReadyEnv)
+
+		return
+	}
+
+	readyErr := waitReady(ready, 3*time.Second)
+
+	if readyErr != nil {
+		t.Error("Expecting to be ready, got error:", readyErr)
+
+		return
+	}
+
+	notReady, notReadyWriter, pipeErr := os.Pipe()
+
+	if pipeErr != nil {
+		t.Error("Failed to create pipe due to error:", pipeErr)
+
+		return
+	}
+
+	defer notReady.Close()
+	defer notReadyWriter.Close()
+
+	readyErr = waitReady(notReady, 100*time.Millisecond)
+
+	if readyErr != ErrHandoverNotReady {
+		t.Errorf("Expecting error %s, got %v", ErrHandoverNotReady, readyErr)
+
+		return
+	}
+}
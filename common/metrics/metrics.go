@@ -0,0 +1,103 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import "time"
+
+// Type is the type of a metric
+type Type uint8
+
+// Metric types
+const (
+	CounterType   Type = 0x01
+	HistogramType Type = 0x02
+)
+
+// Label is a name and value pair which identifies a metric
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Labels is a set of Label
+type Labels []Label
+
+// Counter is a value that can only be increased
+type Counter interface {
+	Add(delta uint64)
+}
+
+// Histogram samples durations into buckets
+type Histogram interface {
+	Observe(d time.Duration)
+}
+
+// Registry creates and collects metrics
+type Registry interface {
+	With(labels ...Label) Registry
+	Counter(name string, help string, labels ...Label) Counter
+	Histogram(name string, help string, labels ...Label) Histogram
+	Gather(g func(Family))
+}
+
+// Family is a snapshot of all metrics that shares the same name
+type Family struct {
+	Name    string
+	Help    string
+	Type    Type
+	Metrics []Metric
+}
+
+// Metric is a snapshot of a single metric. For a Histogram, Value is the
+// total count of observations
+type Metric struct {
+	Labels  Labels
+	Value   uint64
+	Buckets []Bucket
+	Sum     time.Duration
+}
+
+// Bucket is a snapshot of a Histogram bucket, Count is cumulative
+type Bucket struct {
+	UpperBound time.Duration
+	Count      uint64
+}
+
+// String converts Type to string
+func (t Type) String() string {
+	switch t {
+	case CounterType:
+		return "counter"
+
+	case HistogramType:
+		return "histogram"
+	}
+
+	return "untyped"
+}
+
+// L creates a new Label
+func L(name string, value string) Label {
+	return Label{
+		Name:  name,
+		Value: value,
+	}
+}
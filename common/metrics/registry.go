@@ -0,0 +1,328 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Consts
+const (
+	// maxFamilyMetrics is the maximum amount of metrics a Family can
+	// hold. Once reached, labels of new metrics (except those inherited
+	// from With) will be replaced with overflowLabelValue so they all
+	// be folded into one single metric
+	maxFamilyMetrics   = 512
+	overflowLabelValue = "other"
+)
+
+// Default Histogram buckets
+var (
+	defaultBuckets = []time.Duration{
+		5 * time.Millisecond,
+		10 * time.Millisecond,
+		25 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		1 * time.Second,
+		2500 * time.Millisecond,
+		5 * time.Second,
+		10 * time.Second,
+	}
+)
+
+// counter implements Counter
+type counter struct {
+	value uint64
+}
+
+// histogram implements Histogram
+type histogram struct {
+	buckets []time.Duration
+	counts  []uint64
+	sum     int64
+}
+
+// metric is a registered metric
+type metric struct {
+	labels    Labels
+	counter   *counter
+	histogram *histogram
+}
+
+// family is a group of metric which shares a same name
+type family struct {
+	name    string
+	help    string
+	typ     Type
+	metrics map[string]*metric
+	order   []string
+}
+
+// families is where all families stored in
+type families struct {
+	families map[string]*family
+	order    []string
+	lock     sync.RWMutex
+}
+
+// registry implements Registry
+type registry struct {
+	families *families
+	labels   Labels
+}
+
+// New creates a new Registry
+func New() Registry {
+	return registry{
+		families: &families{
+			families: make(map[string]*family, 64),
+			order:    make([]string, 0, 64),
+			lock:     sync.RWMutex{},
+		},
+		labels: Labels{},
+	}
+}
+
+// Add increases the counter
+func (c *counter) Add(delta uint64) {
+	atomic.AddUint64(&c.value, delta)
+}
+
+// Observe records a duration
+func (h *histogram) Observe(d time.Duration) {
+	for bIdx := range h.buckets {
+		if d > h.buckets[bIdx] {
+			continue
+		}
+
+		atomic.AddUint64(&h.counts[bIdx], 1)
+
+		break
+	}
+
+	// The last slot is the +Inf bucket
+	if d > h.buckets[len(h.buckets)-1] {
+		atomic.AddUint64(&h.counts[len(h.buckets)], 1)
+	}
+
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+// key builds a map key from labels
+func (l Labels) key() string {
+	k := make([]string, len(l))
+
+	for lIdx := range l {
+		k[lIdx] = l[lIdx].Name + "\xff" + l[lIdx].Value
+	}
+
+	return strings.Join(k, "\xfe")
+}
+
+// With creates a child Registry which will attach the given labels
+// to all metrics created from it
+func (r registry) With(labels ...Label) Registry {
+	newLabels := make(Labels, 0, len(r.labels)+len(labels))
+
+	newLabels = append(newLabels, r.labels...)
+	newLabels = append(newLabels, labels...)
+
+	return registry{
+		families: r.families,
+		labels:   newLabels,
+	}
+}
+
+// get returns an existing metric or create a new one
+func (r registry) get(
+	name string,
+	help string,
+	typ Type,
+	labels Labels,
+	create func() *metric,
+) *metric {
+	allLabels := make(Labels, 0, len(r.labels)+len(labels))
+
+	allLabels = append(allLabels, r.labels...)
+	allLabels = append(allLabels, labels...)
+
+	key := allLabels.key()
+
+	r.families.lock.RLock()
+	f, fFound := r.families.families[name]
+
+	if fFound {
+		m, mFound := f.metrics[key]
+
+		if mFound {
+			r.families.lock.RUnlock()
+
+			return m
+		}
+	}
+	r.families.lock.RUnlock()
+
+	r.families.lock.Lock()
+	defer r.families.lock.Unlock()
+
+	f, fFound = r.families.families[name]
+
+	if !fFound {
+		f = &family{
+			name:    name,
+			help:    help,
+			typ:     typ,
+			metrics: make(map[string]*metric, 16),
+			order:   make([]string, 0, 16),
+		}
+
+		r.families.families[name] = f
+		r.families.order = append(r.families.order, name)
+	}
+
+	if f.typ != typ {
+		panic(fmt.Sprintf("Metric \"%s\" already registered as a %s",
+			name, f.typ))
+	}
+
+	if len(f.metrics) >= maxFamilyMetrics {
+		for lIdx := len(r.labels); lIdx < len(allLabels); lIdx++ {
+			allLabels[lIdx].Value = overflowLabelValue
+		}
+
+		key = allLabels.key()
+	}
+
+	m, mFound := f.metrics[key]
+
+	if mFound {
+		return m
+	}
+
+	m = create()
+	m.labels = allLabels
+
+	f.metrics[key] = m
+	f.order = append(f.order, key)
+
+	return m
+}
+
+// Counter returns a Counter
+func (r registry) Counter(
+	name string, help string, labels ...Label) Counter {
+	return r.get(name, help, CounterType, labels, func() *metric {
+		return &metric{
+			counter: &counter{value: 0},
+		}
+	}).counter
+}
+
+// Histogram returns a Histogram
+func (r registry) Histogram(
+	name string, help string, labels ...Label) Histogram {
+	return r.get(name, help, HistogramType, labels, func() *metric {
+		return &metric{
+			histogram: &histogram{
+				buckets: defaultBuckets,
+				counts:  make([]uint64, len(defaultBuckets)+1),
+				sum:     0,
+			},
+		}
+	}).histogram
+}
+
+// snapshot takes snapshots of all registered metrics
+func (r registry) snapshot() []Family {
+	r.families.lock.RLock()
+	defer r.families.lock.RUnlock()
+
+	results := make([]Family, 0, len(r.families.order))
+
+	for _, fName := range r.families.order {
+		f := r.families.families[fName]
+
+		result := Family{
+			Name:    f.name,
+			Help:    f.help,
+			Type:    f.typ,
+			Metrics: make([]Metric, 0, len(f.order)),
+		}
+
+		for _, mKey := range f.order {
+			m := f.metrics[mKey]
+
+			switch f.typ {
+			case CounterType:
+				result.Metrics = append(result.Metrics, Metric{
+					Labels: m.labels,
+					Value:  atomic.LoadUint64(&m.counter.value),
+				})
+
+			case HistogramType:
+				buckets := make([]Bucket, len(m.histogram.buckets))
+				cumulative := uint64(0)
+
+				for bIdx := range m.histogram.buckets {
+					cumulative += atomic.LoadUint64(&m.histogram.counts[bIdx])
+
+					buckets[bIdx] = Bucket{
+						UpperBound: m.histogram.buckets[bIdx],
+						Count:      cumulative,
+					}
+				}
+
+				cumulative += atomic.LoadUint64(
+					&m.histogram.counts[len(m.histogram.buckets)])
+
+				result.Metrics = append(result.Metrics, Metric{
+					Labels:  m.labels,
+					Value:   cumulative,
+					Buckets: buckets,
+					Sum: time.Duration(
+						atomic.LoadInt64(&m.histogram.sum)),
+				})
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// Gather takes snapshots of all registered metrics. The snapshots are
+// taken before g is called, so g can take as long as it needs without
+// blocking the creation of new metrics
+func (r registry) Gather(g func(Family)) {
+	families := r.snapshot()
+
+	for fIdx := range families {
+		g(families[fIdx])
+	}
+}
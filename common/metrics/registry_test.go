@@ -0,0 +1,174 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRegistryCounter(t *testing.T) {
+	r := New()
+	child := r.With(L("role", "test"))
+
+	child.Counter("requests", "Requests", L("id", "1")).Add(1)
+	child.Counter("requests", "Requests", L("id", "1")).Add(2)
+	child.Counter("requests", "Requests", L("id", "2")).Add(5)
+
+	families := []Family{}
+
+	r.Gather(func(f Family) {
+		families = append(families, f)
+	})
+
+	if len(families) != 1 {
+		t.Errorf("Expecting %d Family, got %d", 1, len(families))
+
+		return
+	}
+
+	if len(families[0].Metrics) != 2 {
+		t.Errorf("Expecting %d Metrics, got %d",
+			2, len(families[0].Metrics))
+
+		return
+	}
+
+	if families[0].Metrics[0].Value != 3 {
+		t.Errorf("Expecting the first counter to be %d, got %d",
+			3, families[0].Metrics[0].Value)
+
+		return
+	}
+
+	if len(families[0].Metrics[0].Labels) != 2 ||
+		families[0].Metrics[0].Labels[0] != L("role", "test") ||
+		families[0].Metrics[0].Labels[1] != L("id", "1") {
+		t.Errorf("Unexpected labels: %v", families[0].Metrics[0].Labels)
+
+		return
+	}
+}
+
+func TestRegistryHistogram(t *testing.T) {
+	r := New()
+	h := r.Histogram("delay", "Delay")
+
+	h.Observe(1 * time.Millisecond)
+	h.Observe(7 * time.Millisecond)
+	h.Observe(1 * time.Minute)
+
+	r.Gather(func(f Family) {
+		if f.Type != HistogramType {
+			t.Errorf("Expecting a %s, got %s", HistogramType, f.Type)
+
+			return
+		}
+
+		m := f.Metrics[0]
+
+		if m.Value != 3 {
+			t.Errorf("Expecting %d observations, got %d", 3, m.Value)
+
+			return
+		}
+
+		if m.Buckets[0].Count != 1 || m.Buckets[1].Count != 2 {
+			t.Errorf("Unexpected bucket counts: %v", m.Buckets)
+
+			return
+		}
+
+		if m.Buckets[len(m.Buckets)-1].Count != 2 {
+			t.Errorf("Expecting the last bucket to be %d, got %d",
+				2, m.Buckets[len(m.Buckets)-1].Count)
+
+			return
+		}
+
+		if m.Sum != 1*time.Minute+8*time.Millisecond {
+			t.Errorf("Unexpected sum: %s", m.Sum)
+
+			return
+		}
+	})
+}
+
+func TestRegistryOverflow(t *testing.T) {
+	r := New()
+	child := r.With(L("role", "test"))
+
+	for i := 0; i < maxFamilyMetrics*2; i++ {
+		child.Counter("errors", "Errors",
+			L("error", strconv.FormatInt(int64(i), 10))).Add(1)
+	}
+
+	r.Gather(func(f Family) {
+		if len(f.Metrics) != maxFamilyMetrics+1 {
+			t.Errorf("Expecting %d Metrics, got %d",
+				maxFamilyMetrics+1, len(f.Metrics))
+
+			return
+		}
+
+		overflow := f.Metrics[len(f.Metrics)-1]
+
+		if overflow.Labels[0].Value != "test" ||
+			overflow.Labels[1].Value != overflowLabelValue {
+			t.Errorf("Unexpected labels: %v", overflow.Labels)
+
+			return
+		}
+
+		if overflow.Value != maxFamilyMetrics {
+			t.Errorf("Expecting overflow counter to be %d, got %d",
+				maxFamilyMetrics, overflow.Value)
+
+			return
+		}
+	})
+}
+
+func TestRegistryGatherUnlocked(t *testing.T) {
+	r := New()
+
+	r.Counter("requests", "Requests", L("id", "1")).Add(1)
+
+	gathered := make(chan struct{})
+
+	go func() {
+		defer close(gathered)
+
+		r.Gather(func(f Family) {
+			// Creating a new metric requires the registry to be writable
+			r.Counter("requests", "Requests", L("id", "2")).Add(1)
+		})
+	}()
+
+	select {
+	case <-gathered:
+	case <-time.After(3 * time.Second):
+		t.Error("Expecting the registry not to be locked during Gather")
+
+		return
+	}
+}
@@ -0,0 +1,116 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textEscaper escapes label values for the text exposition format
+var textEscaper = strings.NewReplacer(
+	"\\", "\\\\", "\"", "\\\"", "\n", "\\n")
+
+// WriteText writes all metrics in the Registry to w in Prometheus text
+// exposition format
+func WriteText(w io.Writer, r Registry) error {
+	bw := bufio.NewWriter(w)
+
+	r.Gather(func(f Family) {
+		bw.WriteString("# HELP " + f.Name + " " + f.Help + "\n")
+		bw.WriteString("# TYPE " + f.Name + " " + f.Type.String() + "\n")
+
+		for mIdx := range f.Metrics {
+			m := f.Metrics[mIdx]
+
+			switch f.Type {
+			case CounterType:
+				writeTextSample(bw, f.Name, m.Labels, nil,
+					strconv.FormatUint(m.Value, 10))
+
+			case HistogramType:
+				for bIdx := range m.Buckets {
+					writeTextSample(bw, f.Name+"_bucket", m.Labels,
+						&Label{
+							Name:  "le",
+							Value: seconds(m.Buckets[bIdx].UpperBound),
+						},
+						strconv.FormatUint(m.Buckets[bIdx].Count, 10))
+				}
+
+				writeTextSample(bw, f.Name+"_bucket", m.Labels,
+					&Label{Name: "le", Value: "+Inf"},
+					strconv.FormatUint(m.Value, 10))
+				writeTextSample(bw, f.Name+"_sum", m.Labels, nil,
+					seconds(m.Sum))
+				writeTextSample(bw, f.Name+"_count", m.Labels, nil,
+					strconv.FormatUint(m.Value, 10))
+			}
+		}
+	})
+
+	return bw.Flush()
+}
+
+// writeTextSample writes one sample line
+func writeTextSample(
+	w *bufio.Writer,
+	name string,
+	labels Labels,
+	extra *Label,
+	value string,
+) {
+	w.WriteString(name)
+
+	if len(labels) > 0 || extra != nil {
+		w.WriteByte('{')
+
+		for lIdx := range labels {
+			if lIdx > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(labels[lIdx].Name + "=\"" +
+				textEscaper.Replace(labels[lIdx].Value) + "\"")
+		}
+
+		if extra != nil {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+
+			w.WriteString(extra.Name + "=\"" +
+				textEscaper.Replace(extra.Value) + "\"")
+		}
+
+		w.WriteByte('}')
+	}
+
+	w.WriteString(" " + value + "\n")
+}
+
+// seconds formats a duration as seconds
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
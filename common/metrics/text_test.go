@@ -0,0 +1,69 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteText(t *testing.T) {
+	r := New()
+	buf := bytes.NewBuffer(make([]byte, 0, 1024))
+
+	r.Counter("requests_total", "Requests", L("id", "a\"b")).Add(3)
+	r.Histogram("delay_seconds", "Delay").Observe(7 * time.Millisecond)
+
+	wErr := WriteText(buf, r)
+
+	if wErr != nil {
+		t.Error("Failed to write metrics due to error:", wErr)
+
+		return
+	}
+
+	expected := "# HELP requests_total Requests\n" +
+		"# TYPE requests_total counter\n" +
+		"requests_total{id=\"a\\\"b\"} 3\n" +
+		"# HELP delay_seconds Delay\n" +
+		"# TYPE delay_seconds histogram\n" +
+		"delay_seconds_bucket{le=\"0.005\"} 0\n" +
+		"delay_seconds_bucket{le=\"0.01\"} 1\n" +
+		"delay_seconds_bucket{le=\"0.025\"} 1\n" +
+		"delay_seconds_bucket{le=\"0.05\"} 1\n" +
+		"delay_seconds_bucket{le=\"0.1\"} 1\n" +
+		"delay_seconds_bucket{le=\"0.25\"} 1\n" +
+		"delay_seconds_bucket{le=\"0.5\"} 1\n" +
+		"delay_seconds_bucket{le=\"1\"} 1\n" +
+		"delay_seconds_bucket{le=\"2.5\"} 1\n" +
+		"delay_seconds_bucket{le=\"5\"} 1\n" +
+		"delay_seconds_bucket{le=\"10\"} 1\n" +
+		"delay_seconds_bucket{le=\"+Inf\"} 1\n" +
+		"delay_seconds_sum 0.007\n" +
+		"delay_seconds_count 1\n"
+
+	if buf.String() != expected {
+		t.Errorf("Expecting output:\n%s\ngot:\n%s", expected, buf.String())
+
+		return
+	}
+}
@@ -22,7 +22,9 @@ package role
 
 import (
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/print"
+	"github.com/reinit/coward/common/session"
 )
 
 // Components is some data needed by role
@@ -33,6 +35,8 @@ type Generater func(
 	screenOut print.Common,
 	cfg interface{},
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 ) (Role, error)
 
 // Configurator creates new configuration for a role
@@ -20,6 +20,14 @@
 
 package role
 
+import "errors"
+
+// Errors
+var (
+	ErrNotListening = errors.New(
+		"Role is not listening")
+)
+
 // UnspawnNotifier is a write only chan that will be written when Role has
 // been unspawned
 type UnspawnNotifier chan struct{}
@@ -29,3 +37,9 @@ type Role interface {
 	Spawn(unspawnNotifier UnspawnNotifier) error
 	Unspawn() error
 }
+
+// HealthChecker is an optional interface of Role. When implemented, the
+// Role reports whether or not it's listeners are up
+type HealthChecker interface {
+	Health() error
+}
@@ -27,7 +27,9 @@ import (
 
 	"github.com/reinit/coward/common/config"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/print"
+	"github.com/reinit/coward/common/session"
 )
 
 // Roler errors
@@ -52,12 +54,16 @@ type Roler interface {
 		name string,
 		configuration interface{},
 		log logger.Logger,
+		m metrics.Registry,
+		s session.Sessions,
 	) (Role, error)
 	InitParameterString(
 		screenOut print.Common,
 		name string,
 		parameters []byte,
 		log logger.Logger,
+		m metrics.Registry,
+		s session.Sessions,
 	) (Role, error)
 	List(screenOut print.Common)
 	MaxRoleNameLen() int
@@ -122,8 +128,10 @@ func (r *roler) init(
 	role Registered,
 	configuration interface{},
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 ) (Role, error) {
-	newRole, genErr := role.generater(screenOut, configuration, log)
+	newRole, genErr := role.generater(screenOut, configuration, log, m, s)
 
 	if genErr != nil {
 		return nil, genErr
@@ -138,6 +146,8 @@ func (r *roler) Init(
 	name string,
 	configuration interface{},
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 ) (Role, error) {
 	role, existed := r.roles[name]
 
@@ -147,7 +157,7 @@ func (r *roler) Init(
 		return nil, ErrNotExisted
 	}
 
-	return r.init(screenOut, role, configuration, log)
+	return r.init(screenOut, role, configuration, log, m, s)
 }
 
 // MaxRoleNameLen returns the length of the longest Role
@@ -161,6 +171,8 @@ func (r *roler) InitParameterString(
 	name string,
 	parameters []byte,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 ) (Role, error) {
 	var configuator config.Configurator
 	var configuration interface{}
@@ -228,7 +240,7 @@ func (r *roler) InitParameterString(
 		}
 	}
 
-	return r.init(screenOut, role, configuration, log)
+	return r.init(screenOut, role, configuration, log, m, s)
 }
 
 func (r *roler) List(screenOut print.Common) {
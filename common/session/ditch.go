@@ -0,0 +1,70 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package session
+
+// ditchSession implements Session
+type ditchSession struct{}
+
+// ditch implements Sessions
+type ditch struct{}
+
+// NewDitch creates a Sessions that tracks nothing
+func NewDitch() Sessions {
+	return ditch{}
+}
+
+// ID returns 0
+func (d ditchSession) ID() ID {
+	return 0
+}
+
+// Received does nothing
+func (d ditchSession) Received(n int) {}
+
+// Sent does nothing
+func (d ditchSession) Sent(n int) {}
+
+// Close does nothing
+func (d ditchSession) Close() {}
+
+// With returns the same ditch
+func (d ditch) With(role string) Sessions {
+	return d
+}
+
+// Open returns a Session which tracks nothing
+func (d ditch) Open(
+	kind Kind,
+	parent ID,
+	remote string,
+	destination string,
+	kill Killer,
+) Session {
+	return ditchSession{}
+}
+
+// List lists nothing
+func (d ditch) List(l func(Info)) {}
+
+// Kill always returns ErrNotFound
+func (d ditch) Kill(id ID) error {
+	return ErrNotFound
+}
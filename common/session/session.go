@@ -0,0 +1,106 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"errors"
+	"time"
+)
+
+// Errors
+var (
+	ErrNotFound = errors.New(
+		"Session was not found")
+
+	ErrNotKillable = errors.New(
+		"Session can't be killed")
+)
+
+// ID is the ID of a Session
+type ID uint64
+
+// Kind is the kind of a Session
+type Kind uint8
+
+// Session kinds
+const (
+	Client      Kind = 0x01
+	Transceiver Kind = 0x02
+	Channel     Kind = 0x03
+	Receiver    Kind = 0x04
+)
+
+// Killer kills a Session
+type Killer func() error
+
+// Info is a snapshot of a Session
+type Info struct {
+	ID          ID        `json:"id"`
+	Parent      ID        `json:"parent,omitempty"`
+	Kind        string    `json:"kind"`
+	Role        string    `json:"role"`
+	Remote      string    `json:"remote,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Started     time.Time `json:"started"`
+	Inbound     uint64    `json:"inbound"`
+	Outbound    uint64    `json:"outbound"`
+}
+
+// Session is a live tracked session
+type Session interface {
+	ID() ID
+	Received(n int)
+	Sent(n int)
+	Close()
+}
+
+// Sessions tracks live sessions
+type Sessions interface {
+	With(role string) Sessions
+	Open(
+		kind Kind,
+		parent ID,
+		remote string,
+		destination string,
+		kill Killer,
+	) Session
+	List(l func(Info))
+	Kill(id ID) error
+}
+
+// String converts Kind to string
+func (k Kind) String() string {
+	switch k {
+	case Client:
+		return "client"
+
+	case Transceiver:
+		return "transceiver"
+
+	case Channel:
+		return "channel"
+
+	case Receiver:
+		return "receiver"
+	}
+
+	return "unknown"
+}
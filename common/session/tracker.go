@@ -0,0 +1,167 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// session implements Session
+type session struct {
+	id          ID
+	parent      ID
+	kind        Kind
+	role        string
+	remote      string
+	destination string
+	started     time.Time
+	inbound     uint64
+	outbound    uint64
+	kill        Killer
+	tracker     *tracker
+}
+
+// tracker stores all live sessions
+type tracker struct {
+	sessions map[ID]*session
+	lastID   ID
+	lock     sync.RWMutex
+}
+
+// sessions implements Sessions
+type sessions struct {
+	tracker *tracker
+	role    string
+}
+
+// New creates a new Sessions
+func New() Sessions {
+	return sessions{
+		tracker: &tracker{
+			sessions: make(map[ID]*session, 256),
+			lastID:   0,
+			lock:     sync.RWMutex{},
+		},
+		role: "",
+	}
+}
+
+// ID returns the ID of current session
+func (s *session) ID() ID {
+	return s.id
+}
+
+// Received records inbound bytes
+func (s *session) Received(n int) {
+	atomic.AddUint64(&s.inbound, uint64(n))
+}
+
+// Sent records outbound bytes
+func (s *session) Sent(n int) {
+	atomic.AddUint64(&s.outbound, uint64(n))
+}
+
+// Close stops tracking current session
+func (s *session) Close() {
+	s.tracker.lock.Lock()
+	defer s.tracker.lock.Unlock()
+
+	delete(s.tracker.sessions, s.id)
+}
+
+// With creates a child Sessions whose sessions belongs to given role
+func (s sessions) With(role string) Sessions {
+	return sessions{
+		tracker: s.tracker,
+		role:    role,
+	}
+}
+
+// Open starts tracking a new session
+func (s sessions) Open(
+	kind Kind,
+	parent ID,
+	remote string,
+	destination string,
+	kill Killer,
+) Session {
+	s.tracker.lock.Lock()
+	defer s.tracker.lock.Unlock()
+
+	s.tracker.lastID++
+
+	ss := &session{
+		id:          s.tracker.lastID,
+		parent:      parent,
+		kind:        kind,
+		role:        s.role,
+		remote:      remote,
+		destination: destination,
+		started:     time.Now(),
+		inbound:     0,
+		outbound:    0,
+		kill:        kill,
+		tracker:     s.tracker,
+	}
+
+	s.tracker.sessions[ss.id] = ss
+
+	return ss
+}
+
+// List lists all live sessions
+func (s sessions) List(l func(Info)) {
+	s.tracker.lock.RLock()
+	defer s.tracker.lock.RUnlock()
+
+	for _, ss := range s.tracker.sessions {
+		l(Info{
+			ID:          ss.id,
+			Parent:      ss.parent,
+			Kind:        ss.kind.String(),
+			Role:        ss.role,
+			Remote:      ss.remote,
+			Destination: ss.destination,
+			Started:     ss.started,
+			Inbound:     atomic.LoadUint64(&ss.inbound),
+			Outbound:    atomic.LoadUint64(&ss.outbound),
+		})
+	}
+}
+
+// Kill kills a session
+func (s sessions) Kill(id ID) error {
+	s.tracker.lock.RLock()
+	ss, found := s.tracker.sessions[id]
+	s.tracker.lock.RUnlock()
+
+	if !found {
+		return ErrNotFound
+	}
+
+	if ss.kill == nil {
+		return ErrNotKillable
+	}
+
+	return ss.kill()
+}
@@ -0,0 +1,98 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package session
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSessions(t *testing.T) {
+	s := New()
+	killErr := errors.New("Killed")
+
+	s1 := s.With("a").Open(Client, 0, "remote", "", func() error {
+		return killErr
+	})
+	s2 := s.With("b").Open(Channel, s1.ID(), "", "dest", nil)
+
+	s1.Received(1)
+	s1.Received(2)
+	s1.Sent(4)
+
+	infos := make(map[ID]Info, 2)
+
+	s.List(func(i Info) {
+		infos[i.ID] = i
+	})
+
+	if len(infos) != 2 {
+		t.Errorf("Expecting 2 sessions, got %d", len(infos))
+
+		return
+	}
+
+	if infos[s1.ID()].Role != "a" || infos[s1.ID()].Inbound != 3 ||
+		infos[s1.ID()].Outbound != 4 || infos[s1.ID()].Kind != "client" {
+		t.Errorf("Unexpected session %+v", infos[s1.ID()])
+
+		return
+	}
+
+	if infos[s2.ID()].Parent != s1.ID() || infos[s2.ID()].Role != "b" {
+		t.Errorf("Unexpected session %+v", infos[s2.ID()])
+
+		return
+	}
+
+	if s.Kill(s1.ID()) != killErr {
+		t.Error("Expecting the Killer to be called")
+
+		return
+	}
+
+	if s.Kill(s2.ID()) != ErrNotKillable {
+		t.Error("Expecting ErrNotKillable")
+
+		return
+	}
+
+	s1.Close()
+	s2.Close()
+
+	if s.Kill(s1.ID()) != ErrNotFound {
+		t.Error("Expecting ErrNotFound")
+
+		return
+	}
+
+	count := 0
+
+	s.List(func(i Info) {
+		count++
+	})
+
+	if count != 0 {
+		t.Errorf("Expecting no session, got %d", count)
+
+		return
+	}
+}
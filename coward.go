@@ -44,9 +44,11 @@ func main() {
 		Components: application.Components{
 			proxy.Role, socks5.Role, mapper.Role,
 			projector.Role, project.Role,
-			codec.Plain,
+			codec.Plain, codec.Deflate,
 			codec.AESCFB128, codec.AESCFB256,
 			codec.AESGCM128, codec.AESGCM256,
+			codec.ChaCha20Poly1305, codec.XChaCha20Poly1305,
+			codec.AESGCM256X25519, codec.ChaCha20Poly1305X25519,
 		},
 	})
 
@@ -21,134 +21,123 @@
 package codec
 
 import (
-	"errors"
-	"sync"
-	"time"
-
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/roles/common/codec/aescfb"
 	"github.com/reinit/coward/roles/common/codec/aesgcm"
 	"github.com/reinit/coward/roles/common/codec/key"
 	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/codec/skew"
 	"github.com/reinit/coward/roles/common/transceiver"
 )
 
-// Vars
-var (
-	aesPrefixerOptions = []string{
-		"Key", "Request-Prefix", "Respond-Prefix",
-	}
-)
-
 // AESCFB128 return a AESCFB128 Transceiver Codec
 func AESCFB128() transceiver.Codec {
 	return transceiver.Codec{
 		Name: "aes-cfb-128-hmac",
-		Usage: "Input a string of letters as shared key (passphrase), " +
-			"multiple lines will be combined into a single line " +
-			"according to order",
+		// AES-CFB can't tell whether or not the key is correct until a
+		// whole segment is read, so it can't try keys of other time periods
+		// without waiting for data that the remote may never send. Clock
+		// skew is not tolerated for it, both here and in AESCFB256
+		Usage:  timedWindowUsage,
 		Build:  aesCFB128Builder,
-		Verify: aesVerifier,
+		Verify: timedSettingVerifier(timedWindowOptions),
 	}
 }
 
 // AESCFB256 return a AESCFB256 Transceiver Codec
 func AESCFB256() transceiver.Codec {
 	return transceiver.Codec{
-		Name: "aes-cfb-256-hmac",
-		Usage: "Input a string of letters as shared key (passphrase), " +
-			"multiple lines will be combined into a single line " +
-			"according to order",
+		Name:   "aes-cfb-256-hmac",
+		Usage:  timedWindowUsage,
 		Build:  aesCFB256Builder,
-		Verify: aesVerifier,
+		Verify: timedSettingVerifier(timedWindowOptions),
 	}
 }
 
 // AESGCM128 return a AESGCM128 Transceiver Codec
 func AESGCM128() transceiver.Codec {
 	return transceiver.Codec{
-		Name: "aes-gcm-128",
-		Usage: "Input a string of letters as shared key (passphrase), " +
-			"multiple lines will be combined into a single line " +
-			"according to order",
+		Name:   "aes-gcm-128",
+		Usage:  timedSkewUsage,
 		Build:  aesGCM128Builder,
-		Verify: aesVerifier,
+		Verify: timedSettingVerifier(timedSkewOptions),
 	}
 }
 
 // AESGCM256 return a AESGCM128 Transceiver Codec
 func AESGCM256() transceiver.Codec {
 	return transceiver.Codec{
-		Name: "aes-gcm-256",
-		Usage: "Input a string of letters as shared key (passphrase), " +
-			"multiple lines will be combined into a single line " +
-			"according to order",
+		Name:   "aes-gcm-256",
+		Usage:  timedSkewUsage,
 		Build:  aesGCM256Builder,
-		Verify: aesVerifier,
-	}
-}
-
-func aesSettingBuilder(configuration []string) []byte {
-	var connectedLines string
-
-	for cIdx := range configuration {
-		connectedLines += configuration[cIdx]
+		Verify: timedSettingVerifier(timedSkewOptions),
 	}
-
-	return []byte(connectedLines)
-}
-
-func aesVerifier(configuration []string) error {
-	cfgStr := aesSettingBuilder(configuration)
-
-	if len(cfgStr) < 16 {
-		return errors.New("Shared Key was too short. " +
-			"Make it at least 16 characters long")
-	}
-
-	return nil
 }
 
-func aesCFB128Builder(configuration []string) transceiver.CodecBuilder {
-	cfgStr := aesSettingBuilder(configuration)
-	timedKey := key.Timed([]byte(cfgStr), 10*time.Second, time.Now)
-	timedMarkers := marker.Timed(4096, 10*time.Second, time.Now)
-	timedMarkerLock := &sync.Mutex{}
+func aesCFB128Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedWindowOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
 
 	return func() (rw.Codec, error) {
-		return aescfb.AESCFB(timedKey, 16, timedMarkers, timedMarkerLock)
+		return setting.wrap(aescfb.AESCFB(
+			timedKey, 16, timedMarkers))
 	}
 }
 
-func aesCFB256Builder(configuration []string) transceiver.CodecBuilder {
-	cfgStr := aesSettingBuilder(configuration)
-	timedKey := key.Timed([]byte(cfgStr), 10*time.Second, time.Now)
-	timedMarkers := marker.Timed(4096, 10*time.Second, time.Now)
-	timedMarkerLock := &sync.Mutex{}
+func aesCFB256Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedWindowOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
 
 	return func() (rw.Codec, error) {
-		return aescfb.AESCFB(timedKey, 32, timedMarkers, timedMarkerLock)
+		return setting.wrap(aescfb.AESCFB(
+			timedKey, 32, timedMarkers))
 	}
 }
 
-func aesGCM128Builder(configuration []string) transceiver.CodecBuilder {
-	cfgStr := aesSettingBuilder(configuration)
-	timedKey := key.Timed([]byte(cfgStr), 10*time.Second, time.Now)
-	timedMarkers := marker.Timed(4096, 10*time.Second, time.Now)
-	timedMarkerLock := &sync.Mutex{}
+func aesGCM128Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
 
 	return func() (rw.Codec, error) {
-		return aesgcm.AESGCM(timedKey, 16, timedMarkers, timedMarkerLock)
+		return setting.wrap(skew.Skewed(timedKey, func(
+			k key.Key, m marker.Marker) (rw.Codec, error) {
+			return aesgcm.AESGCM(k, 16, m)
+		}, timedMarkers, reporter))
 	}
 }
 
-func aesGCM256Builder(configuration []string) transceiver.CodecBuilder {
-	cfgStr := aesSettingBuilder(configuration)
-	timedKey := key.Timed([]byte(cfgStr), 10*time.Second, time.Now)
-	timedMarkers := marker.Timed(4096, 10*time.Second, time.Now)
-	timedMarkerLock := &sync.Mutex{}
+func aesGCM256Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
 
 	return func() (rw.Codec, error) {
-		return aesgcm.AESGCM(timedKey, 32, timedMarkers, timedMarkerLock)
+		return setting.wrap(skew.Skewed(timedKey, func(
+			k key.Key, m marker.Marker) (rw.Codec, error) {
+			return aesgcm.AESGCM(k, 32, m)
+		}, timedMarkers, reporter))
 	}
 }
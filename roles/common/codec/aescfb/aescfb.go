@@ -28,7 +28,6 @@ import (
 	"crypto/sha256"
 	"hash"
 	"io"
-	"sync"
 
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/roles/common/codec/key"
@@ -51,26 +50,21 @@ var (
 )
 
 type aescfb struct {
-	block             cipher.Block
-	encrypter         cipher.Stream
-	encryptHMAC       hash.Hash
-	encryptPad        padding
-	decrypter         cipher.Stream
-	decryptHMAC       hash.Hash
-	decryptPad        padding
-	decryptBuf        []byte
-	decryptBufReader  *bytes.Reader
-	decryptMarker     marker.Marker
-	decryptMarkerLock *sync.Mutex
+	block            cipher.Block
+	encrypter        cipher.Stream
+	encryptHMAC      hash.Hash
+	encryptPad       padding
+	decrypter        cipher.Stream
+	decryptHMAC      hash.Hash
+	decryptPad       padding
+	decryptBuf       []byte
+	decryptBufReader *bytes.Reader
+	decryptMarker    marker.Marker
+	decryptMark      marker.Mark
 }
 
 // AESCFB returns a AES-CFB crypter
-func AESCFB(
-	kg key.Key,
-	keySize int,
-	mark marker.Marker,
-	markLock *sync.Mutex,
-) (rw.Codec, error) {
+func AESCFB(kg key.Key, keySize int, mark marker.Marker) (rw.Codec, error) {
 	keyValue, keyErr := kg.Get(keySize)
 
 	if keyErr != nil {
@@ -84,17 +78,17 @@ func AESCFB(
 	}
 
 	return &aescfb{
-		block:             blockCipher,
-		encrypter:         nil,
-		encryptHMAC:       hmac.New(sha256.New, keyValue),
-		encryptPad:        padding{padBuf: [maxPaddingLength]byte{}},
-		decrypter:         nil,
-		decryptHMAC:       hmac.New(sha256.New, keyValue),
-		decryptPad:        padding{padBuf: [maxPaddingLength]byte{}},
-		decryptBuf:        nil,
-		decryptBufReader:  bytes.NewReader(nil),
-		decryptMarker:     mark,
-		decryptMarkerLock: markLock,
+		block:            blockCipher,
+		encrypter:        nil,
+		encryptHMAC:      hmac.New(sha256.New, keyValue),
+		encryptPad:       padding{padBuf: [maxPaddingLength]byte{}},
+		decrypter:        nil,
+		decryptHMAC:      hmac.New(sha256.New, keyValue),
+		decryptPad:       padding{padBuf: [maxPaddingLength]byte{}},
+		decryptBuf:       nil,
+		decryptBufReader: bytes.NewReader(nil),
+		decryptMarker:    mark,
+		decryptMark:      "",
 	}, nil
 }
 
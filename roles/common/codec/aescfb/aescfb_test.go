@@ -24,7 +24,6 @@ import (
 	"bytes"
 	"crypto/rand"
 	"io"
-	"sync"
 	"testing"
 
 	"github.com/reinit/coward/roles/common/codec/marker"
@@ -63,7 +62,7 @@ func TestAESCFB1(t *testing.T) {
 
 	buf := bytes.NewBuffer(make([]byte, 0, 512))
 
-	codec, codecErr := AESCFB(k, 32, dummyMark{}, &sync.Mutex{})
+	codec, codecErr := AESCFB(k, 32, dummyMark{})
 
 	if codecErr != nil {
 		t.Error("Failed to initialize codec:", codecErr)
@@ -120,7 +119,7 @@ func TestAESCFB2(t *testing.T) {
 
 	buf := bytes.NewBuffer(make([]byte, 0, 512))
 
-	codec, codecErr := AESCFB(k, 32, dummyMark{}, &sync.Mutex{})
+	codec, codecErr := AESCFB(k, 32, dummyMark{})
 
 	if codecErr != nil {
 		t.Error("Failed to initialize codec:", codecErr)
@@ -48,14 +48,7 @@ func (a decrypter) Read(b []byte) (int, error) {
 			return 0, rErr
 		}
 
-		a.e.decryptMarkerLock.Lock()
-		markErr := a.e.decryptMarker.Mark(marker.Mark(iv[:]))
-		a.e.decryptMarkerLock.Unlock()
-
-		if markErr != nil {
-			return 0, transceiver.WrapCodecError(markErr)
-		}
-
+		a.e.decryptMark = marker.Mark(iv[:])
 		a.e.decrypter = cipher.NewCFBDecrypter(a.e.block, iv[:])
 	}
 
@@ -142,6 +135,18 @@ func (a decrypter) Read(b []byte) (int, error) {
 		return 0, ErrSegmentDataVerificationFailed
 	}
 
+	// Only mark the IV once the data has been authenticated, so
+	// forged data can't fill up the marker
+	if a.e.decryptMark != "" {
+		markErr := a.e.decryptMarker.Mark(a.e.decryptMark)
+
+		if markErr != nil {
+			return 0, transceiver.WrapCodecError(markErr)
+		}
+
+		a.e.decryptMark = ""
+	}
+
 	// Record the HMAC data
 	_, wHMACErr = a.e.decryptHMAC.Write(hmacValue[:])
 
@@ -25,7 +25,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"io"
-	"sync"
 
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/roles/common/codec/key"
@@ -65,16 +64,11 @@ type aesgcm struct {
 	decryptReader        *bytes.Reader
 	decryptNonceBuf      [nonceSize]byte
 	decryptMarker        marker.Marker
-	decryptMarkerLock    *sync.Mutex
+	decryptMark          marker.Mark
 }
 
 // AESGCM returns a AES-GCM crypter
-func AESGCM(
-	kg key.Key,
-	keySize int,
-	mark marker.Marker,
-	markLock *sync.Mutex,
-) (rw.Codec, error) {
+func AESGCM(kg key.Key, keySize int, mark marker.Marker) (rw.Codec, error) {
 	keyValue, keyErr := kg.Get(keySize)
 
 	if keyErr != nil {
@@ -115,7 +109,7 @@ func AESGCM(
 		decryptReader:        bytes.NewReader(nil),
 		decryptNonceBuf:      [nonceSize]byte{},
 		decryptMarker:        mark,
-		decryptMarkerLock:    markLock,
+		decryptMark:          "",
 	}, nil
 }
 
@@ -24,7 +24,6 @@ import (
 	"bytes"
 	"crypto/rand"
 	"io"
-	"sync"
 	"testing"
 
 	"github.com/reinit/coward/roles/common/codec/marker"
@@ -48,6 +47,98 @@ func (d dummyMark) Mark(marker.Mark) error {
 	return nil
 }
 
+type countMark struct {
+	marks int
+}
+
+func (d *countMark) Mark(marker.Mark) error {
+	d.marks++
+
+	return nil
+}
+
+func TestAESGCMMarkAuthenticated(t *testing.T) {
+	k := dummyKey{
+		Key: make([]byte, 32),
+	}
+
+	mark := &countMark{}
+
+	codec, codecErr := AESGCM(k, 32, mark)
+
+	if codecErr != nil {
+		t.Error("Failed to initialize codec:", codecErr)
+
+		return
+	}
+
+	forged := make([]byte, 128)
+
+	_, rErr := rand.Read(forged)
+
+	if rErr != nil {
+		t.Error("Failed to generate forged data:", rErr)
+
+		return
+	}
+
+	_, rErr = codec.Decode(bytes.NewReader(forged)).Read(make([]byte, 16))
+
+	if rErr == nil {
+		t.Error("Expecting forged data to be rejected")
+
+		return
+	}
+
+	if mark.marks != 0 {
+		t.Errorf("Expecting forged data not to be marked, got %d marks",
+			mark.marks)
+
+		return
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 512))
+
+	encoder, encoderErr := AESGCM(k, 32, dummyMark{})
+
+	if encoderErr != nil {
+		t.Error("Failed to initialize codec:", encoderErr)
+
+		return
+	}
+
+	_, wErr := encoder.Encode(buf).Write([]byte("Hello"))
+
+	if wErr != nil {
+		t.Error("Failed to encode due to error:", wErr)
+
+		return
+	}
+
+	decoder, decoderErr := AESGCM(k, 32, mark)
+
+	if decoderErr != nil {
+		t.Error("Failed to initialize codec:", decoderErr)
+
+		return
+	}
+
+	_, rErr = io.ReadFull(decoder.Decode(buf), make([]byte, 5))
+
+	if rErr != nil {
+		t.Error("Failed to decode due to error:", rErr)
+
+		return
+	}
+
+	if mark.marks != 1 {
+		t.Errorf("Expecting authenticated data to be marked once, got %d",
+			mark.marks)
+
+		return
+	}
+}
+
 func TestAESCFB(t *testing.T) {
 	k := dummyKey{
 		Key: make([]byte, 64),
@@ -63,7 +154,7 @@ func TestAESCFB(t *testing.T) {
 
 	buf := bytes.NewBuffer(make([]byte, 0, 512))
 
-	codec, codecErr := AESGCM(k, 32, dummyMark{}, &sync.Mutex{})
+	codec, codecErr := AESGCM(k, 32, dummyMark{})
 
 	if codecErr != nil {
 		t.Error("Failed to initialize codec:", codecErr)
@@ -43,14 +43,7 @@ func (a decrypter) Read(b []byte) (int, error) {
 			return 0, rErr
 		}
 
-		a.e.decryptMarkerLock.Lock()
-		markErr := a.e.decryptMarker.Mark(marker.Mark(a.e.decryptNonceBuf[:]))
-		a.e.decryptMarkerLock.Unlock()
-
-		if markErr != nil {
-			return 0, transceiver.WrapCodecError(markErr)
-		}
-
+		a.e.decryptMark = marker.Mark(a.e.decryptNonceBuf[:])
 		a.e.decrypterInited = true
 	}
 
@@ -73,6 +66,18 @@ func (a decrypter) Read(b []byte) (int, error) {
 		return 0, ErrInvalidSizeDataLength
 	}
 
+	// Only mark the nonce once the data has been authenticated, so
+	// forged data can't fill up the marker
+	if a.e.decryptMark != "" {
+		markErr := a.e.decryptMarker.Mark(a.e.decryptMark)
+
+		if markErr != nil {
+			return 0, transceiver.WrapCodecError(markErr)
+		}
+
+		a.e.decryptMark = ""
+	}
+
 	a.e.nonceIncreament(a.e.decryptNonceBuf[:])
 
 	size := 0
@@ -0,0 +1,88 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/chacha"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/codec/skew"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// ChaCha20Poly1305 return a ChaCha20-Poly1305 Transceiver Codec
+func ChaCha20Poly1305() transceiver.Codec {
+	return transceiver.Codec{
+		Name:   "chacha20-poly1305",
+		Usage:  timedSkewUsage,
+		Build:  chaCha20Poly1305Builder,
+		Verify: timedSettingVerifier(timedSkewOptions),
+	}
+}
+
+// XChaCha20Poly1305 return a XChaCha20-Poly1305 Transceiver Codec
+func XChaCha20Poly1305() transceiver.Codec {
+	return transceiver.Codec{
+		Name:   "xchacha20-poly1305",
+		Usage:  timedSkewUsage,
+		Build:  xChaCha20Poly1305Builder,
+		Verify: timedSettingVerifier(timedSkewOptions),
+	}
+}
+
+func chaCha20Poly1305Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
+
+	return func() (rw.Codec, error) {
+		return setting.wrap(skew.Skewed(timedKey, func(
+			k key.Key, m marker.Marker) (rw.Codec, error) {
+			return chacha.ChaCha20Poly1305(k, m)
+		}, timedMarkers, reporter))
+	}
+}
+
+func xChaCha20Poly1305Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
+
+	return func() (rw.Codec, error) {
+		return setting.wrap(skew.Skewed(timedKey, func(
+			k key.Key, m marker.Marker) (rw.Codec, error) {
+			return chacha.XChaCha20Poly1305(k, m)
+		}, timedMarkers, reporter))
+	}
+}
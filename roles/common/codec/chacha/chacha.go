@@ -0,0 +1,150 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package chacha
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/transceiver"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	maxDataBlockSize    = 4096
+	maxPaddingBlockSize = 16
+)
+
+// Errors
+var (
+	ErrDataBlockTooLarge = transceiver.NewCodecError(
+		"ChaCha20-Poly1305 Data block too large, decode refused")
+
+	ErrPaddingBlockTooLarge = transceiver.NewCodecError(
+		"ChaCha20-Poly1305 Padding block too large, decode refused")
+
+	ErrInvalidSizeDataLength = transceiver.NewCodecError(
+		"The length information of size data is invalid")
+)
+
+type chacha struct {
+	encrypter            cipher.AEAD
+	encrypterInited      bool
+	encrypterNonceBuf    []byte
+	encryptBuf           *bytes.Buffer
+	decrypter            cipher.AEAD
+	decrypterInited      bool
+	decryptCipherTextBuf []byte
+	decryptReader        *bytes.Reader
+	decryptNonceBuf      []byte
+	decryptMarker        marker.Marker
+	decryptMark          marker.Mark
+}
+
+// ChaCha20Poly1305 returns a ChaCha20-Poly1305 crypter
+func ChaCha20Poly1305(kg key.Key, mark marker.Marker) (rw.Codec, error) {
+	return build(kg, chacha20poly1305.New, mark)
+}
+
+// XChaCha20Poly1305 returns a XChaCha20-Poly1305 crypter which uses
+// 24 bytes long nonce
+func XChaCha20Poly1305(kg key.Key, mark marker.Marker) (rw.Codec, error) {
+	return build(kg, chacha20poly1305.NewX, mark)
+}
+
+func build(
+	kg key.Key,
+	aead func(key []byte) (cipher.AEAD, error),
+	mark marker.Marker,
+) (rw.Codec, error) {
+	keyValue, keyErr := kg.Get(chacha20poly1305.KeySize)
+
+	if keyErr != nil {
+		return nil, keyErr
+	}
+
+	encrypter, encrypterErr := aead(keyValue)
+
+	if encrypterErr != nil {
+		return nil, encrypterErr
+	}
+
+	decrypter, decrypterErr := aead(keyValue)
+
+	if decrypterErr != nil {
+		return nil, decrypterErr
+	}
+
+	return &chacha{
+		encrypter:            encrypter,
+		encrypterInited:      false,
+		encrypterNonceBuf:    make([]byte, encrypter.NonceSize()),
+		encryptBuf:           bytes.NewBuffer(nil),
+		decrypter:            decrypter,
+		decrypterInited:      false,
+		decryptCipherTextBuf: nil,
+		decryptReader:        bytes.NewReader(nil),
+		decryptNonceBuf:      make([]byte, decrypter.NonceSize()),
+		decryptMarker:        mark,
+		decryptMark:          "",
+	}, nil
+}
+
+func (a *chacha) nonceIncreament(nonce []byte) {
+	// Do a increament in reversed byte order
+	for nIdx := range nonce {
+		if nonce[nIdx] < 255 {
+			nonce[nIdx]++
+
+			break
+		}
+
+		nonce[nIdx] = 0
+	}
+}
+
+func (a *chacha) getDecryptBuf(size int) []byte {
+	sizeCipherTextReadLen := a.decrypter.Overhead() + size
+
+	if len(a.decryptCipherTextBuf) < sizeCipherTextReadLen {
+		a.decryptCipherTextBuf = make([]byte, sizeCipherTextReadLen)
+	}
+
+	return a.decryptCipherTextBuf[:sizeCipherTextReadLen]
+}
+
+func (a *chacha) Encode(w io.Writer) rw.WriteWriteAll {
+	return encrypter{
+		e: a,
+		w: w,
+	}
+}
+
+func (a *chacha) Decode(r io.Reader) io.Reader {
+	return decrypter{
+		e: a,
+		r: r,
+	}
+}
@@ -0,0 +1,180 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"compress/flate"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/deflate"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Consts
+const (
+	deflateDefaultEntropyThreshold = 7.5
+	deflateDefaultEntropySample    = 1024
+
+	deflateUsage = "Compresses data with DEFLATE. All options are " +
+		"optional:\r\n\r\n\"Level\" option sets the compression level " +
+		"from 1 (fastest) to 9 (smallest), default is 6.\r\n\r\n" +
+		"\"Entropy-Threshold\" option sets the entropy (in bits per byte, " +
+		"default: 7.5) of the sampled data above which the data will be " +
+		"sent without compression, as it's most likely been compressed " +
+		"already. Set it to 0 to always compress.\r\n\r\n" +
+		"\"Entropy-Sample\" option sets the maximal number of bytes " +
+		"(default: 1024) that will be sampled from every write.\r\n\r\n" +
+		"Compressed data is not encrypted, chain it before an encrypting " +
+		"Codec (for example, \"deflate, aes-gcm-256\") to protect the " +
+		"data." + prefixerUsage + shaperUsage
+)
+
+// Vars
+var (
+	deflateOptions = append([]string{
+		"Level", "Entropy-Threshold", "Entropy-Sample",
+		"Request-Prefix", "Respond-Prefix",
+	}, shaperOptions...)
+)
+
+// deflateSetting Deflate Setting
+type deflateSetting struct {
+	Config   deflate.Config
+	Prefixer prefixerSetting
+	Shaper   shaperSetting
+}
+
+// Deflate returns a Deflate Transceiver Codec
+func Deflate() transceiver.Codec {
+	return transceiver.Codec{
+		Name:   "deflate",
+		Usage:  deflateUsage,
+		Build:  deflateBuilder,
+		Verify: deflateVerifier,
+	}
+}
+
+func deflateSettingParser(configuration []string) (deflateSetting, error) {
+	setting, settingErr := codecSettingParser(
+		configuration, deflateOptions, "")
+
+	if settingErr != nil {
+		return deflateSetting{}, settingErr
+	}
+
+	result := deflateSetting{
+		Config: deflate.Config{
+			Level:            flate.DefaultCompression,
+			EntropyThreshold: deflateDefaultEntropyThreshold,
+			EntropySample:    deflateDefaultEntropySample,
+		},
+	}
+
+	level := strings.TrimSpace(string(setting["Level"]))
+
+	if len(level) > 0 {
+		levelValue, levelErr := strconv.ParseInt(level, 10, 8)
+
+		if levelErr != nil || levelValue < flate.BestSpeed ||
+			levelValue > flate.BestCompression {
+			return deflateSetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Level\" option. It must be "+
+					"a number between %d and %d", level,
+				flate.BestSpeed, flate.BestCompression)
+		}
+
+		result.Config.Level = int(levelValue)
+	}
+
+	threshold := strings.TrimSpace(string(setting["Entropy-Threshold"]))
+
+	if len(threshold) > 0 {
+		thresholdValue, thresholdErr := strconv.ParseFloat(threshold, 64)
+
+		if thresholdErr != nil || thresholdValue < 0 || thresholdValue > 8 {
+			return deflateSetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Entropy-Threshold\" option. "+
+					"It must be a number between 0 and 8", threshold)
+		}
+
+		result.Config.EntropyThreshold = thresholdValue
+	}
+
+	sample := strings.TrimSpace(string(setting["Entropy-Sample"]))
+
+	if len(sample) > 0 {
+		sampleValue, sampleErr := strconv.ParseUint(sample, 10, 16)
+
+		if sampleErr != nil || sampleValue <= 0 {
+			return deflateSetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Entropy-Sample\" option. "+
+					"It must be a number between 1 and 65535", sample)
+		}
+
+		result.Config.EntropySample = int(sampleValue)
+	}
+
+	var prefixerErr error
+
+	result.Prefixer, prefixerErr = prefixerSettingParser(setting)
+
+	if prefixerErr != nil {
+		return deflateSetting{}, prefixerErr
+	}
+
+	var shaperErr error
+
+	result.Shaper, shaperErr = shaperSettingParser(setting)
+
+	if shaperErr != nil {
+		return deflateSetting{}, shaperErr
+	}
+
+	return result, nil
+}
+
+func deflateVerifier(configuration []string) error {
+	_, settingErr := deflateSettingParser(configuration)
+
+	return settingErr
+}
+
+func deflateBuilder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting, settingErr := deflateSettingParser(configuration)
+
+	if settingErr != nil {
+		panic(fmt.Sprintf("Bad deflate setting: %s", settingErr))
+	}
+
+	return func() (rw.Codec, error) {
+		return setting.Prefixer.wrap(
+			setting.Shaper.wrap(deflate.New(setting.Config)))
+	}
+}
@@ -0,0 +1,117 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package deflate
+
+import (
+	"io"
+
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+type decoder struct {
+	d *deflate
+	r io.Reader
+}
+
+// readFrame reads the header of the next frame. If the frame is
+// compressed, all compressed data of the frame will be read so the
+// decompressor will never wait on the underlaying reader
+func (d decoder) readFrame() error {
+	_, rErr := io.ReadFull(d.r, d.d.readHeader[:])
+
+	if rErr != nil {
+		return rErr
+	}
+
+	frameType := d.d.readHeader[0]
+	dataSize := getFrameSize(d.d.readHeader[1:4])
+	compressedSize := getFrameSize(d.d.readHeader[4:7])
+
+	if dataSize <= 0 || dataSize > MaxFrameDataSize {
+		return ErrInvalidFrame
+	}
+
+	switch frameType {
+	case frameTypeRaw:
+		if compressedSize != dataSize {
+			return ErrInvalidFrame
+		}
+
+	case frameTypeDeflated:
+		if compressedSize <= 0 || compressedSize > maxFrameCompressedSize {
+			return ErrInvalidFrame
+		}
+
+		if cap(d.d.readPending) < compressedSize {
+			d.d.readPending = make([]byte, compressedSize)
+		}
+
+		_, rErr = io.ReadFull(d.r, d.d.readPending[:compressedSize])
+
+		if rErr != nil {
+			return rErr
+		}
+
+		d.d.readBuf.Write(d.d.readPending[:compressedSize])
+
+	default:
+		return ErrInvalidFrame
+	}
+
+	d.d.readType = frameType
+	d.d.readRemain = dataSize
+
+	return nil
+}
+
+func (d decoder) Read(b []byte) (int, error) {
+	if d.d.readRemain <= 0 {
+		rErr := d.readFrame()
+
+		if rErr != nil {
+			return 0, rErr
+		}
+	}
+
+	readLen := len(b)
+
+	if readLen > d.d.readRemain {
+		readLen = d.d.readRemain
+	}
+
+	if d.d.readType == frameTypeRaw {
+		rLen, rErr := d.r.Read(b[:readLen])
+
+		d.d.readRemain -= rLen
+
+		return rLen, rErr
+	}
+
+	rLen, rErr := io.ReadFull(d.d.reader, b[:readLen])
+
+	d.d.readRemain -= rLen
+
+	if rErr != nil {
+		return rLen, transceiver.WrapCodecError(rErr)
+	}
+
+	return rLen, nil
+}
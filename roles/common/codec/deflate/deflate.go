@@ -0,0 +1,134 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package deflate
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Errors
+var (
+	ErrInvalidFrame = transceiver.NewCodecError(
+		"Compressed frame was invalid")
+
+	ErrInvalidLevel = transceiver.NewCodecError(
+		"Compression level was invalid")
+)
+
+// Consts
+const (
+	// MaxFrameDataSize is the maximal size of data that will be
+	// carried by one frame. Larger writes will be splited
+	MaxFrameDataSize = 256 * 1024
+
+	// maxFrameCompressedSize is the maximal size of compressed data
+	// that could be produced from MaxFrameDataSize of data
+	maxFrameCompressedSize = MaxFrameDataSize + MaxFrameDataSize/1024 + 64
+
+	frameHeaderSize = 7
+
+	frameTypeRaw      = 0
+	frameTypeDeflated = 1
+)
+
+// Config is the configuration of the compressor
+type Config struct {
+	// Level is the compression level, see compress/flate
+	Level int
+
+	// EntropyThreshold is the entropy (in bits per byte) of the sampled
+	// data above which the data will be sent without compression.
+	// Entropy sampling is disabled when it's 0
+	EntropyThreshold float64
+
+	// EntropySample is the maximal number of bytes that will be sampled
+	EntropySample int
+}
+
+type deflate struct {
+	cfg         Config
+	writer      *flate.Writer
+	writeBuf    *bytes.Buffer
+	readHeader  [frameHeaderSize]byte
+	readType    byte
+	readRemain  int
+	reader      io.ReadCloser
+	readBuf     *bytes.Buffer
+	readPending []byte
+}
+
+// New returns a Codec which compresses the data with DEFLATE.
+//
+// Every write will be compressed and flushed into it's own frame so
+// the remote can decompress it without waiting for further data, while
+// the compression history is kept across frames. Data that looks like
+// already been compressed (decided by entropy sampling) will be sent
+// as it is
+func New(cfg Config) (rw.Codec, error) {
+	if cfg.Level < flate.HuffmanOnly || cfg.Level > flate.BestCompression {
+		return nil, ErrInvalidLevel
+	}
+
+	writeBuf := bytes.NewBuffer(make([]byte, 0, 4096))
+
+	writer, writerErr := flate.NewWriter(writeBuf, cfg.Level)
+
+	if writerErr != nil {
+		return nil, transceiver.WrapCodecError(writerErr)
+	}
+
+	readBuf := bytes.NewBuffer(make([]byte, 0, 4096))
+
+	return &deflate{
+		cfg:         cfg,
+		writer:      writer,
+		writeBuf:    writeBuf,
+		readHeader:  [frameHeaderSize]byte{},
+		readType:    frameTypeRaw,
+		readRemain:  0,
+		reader:      flate.NewReader(readBuf),
+		readBuf:     readBuf,
+		readPending: nil,
+	}, nil
+}
+
+func (d *deflate) Encode(w io.Writer) rw.WriteWriteAll {
+	return encoder{d: d, w: w}
+}
+
+func (d *deflate) Decode(r io.Reader) io.Reader {
+	return decoder{d: d, r: r}
+}
+
+func putFrameSize(b []byte, size int) {
+	b[0] = byte(size >> 16)
+	b[1] = byte(size >> 8)
+	b[2] = byte(size)
+}
+
+func getFrameSize(b []byte) int {
+	return int(b[0])<<16 | int(b[1])<<8 | int(b[2])
+}
@@ -0,0 +1,246 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package deflate
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func testDeflateCodecs(t *testing.T, cfg Config) (
+	*deflate, *deflate) {
+	encode, encodeErr := New(cfg)
+
+	if encodeErr != nil {
+		t.Error("Failed to create codec due to error:", encodeErr)
+
+		return nil, nil
+	}
+
+	decode, decodeErr := New(cfg)
+
+	if decodeErr != nil {
+		t.Error("Failed to create codec due to error:", decodeErr)
+
+		return nil, nil
+	}
+
+	return encode.(*deflate), decode.(*deflate)
+}
+
+func TestDeflateEncodeDecode(t *testing.T) {
+	encode, decode := testDeflateCodecs(t, Config{
+		Level:            flate.DefaultCompression,
+		EntropyThreshold: 7.5,
+		EntropySample:    1024,
+	})
+
+	if encode == nil {
+		return
+	}
+
+	random := make([]byte, 8192)
+
+	_, rErr := rand.Read(random)
+
+	if rErr != nil {
+		t.Error("Failed to generate random data due to error:", rErr)
+
+		return
+	}
+
+	text := bytes.Repeat([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n"), 256)
+	segments := [][][]byte{
+		{[]byte{1, 0, 4}, []byte("Test")},
+		{[]byte{1, 0, 0}, text},
+		{[]byte{2, 0, 0}, random},
+		{[]byte{1, 0, 4}, []byte("Test"), text},
+		{[]byte("Single")},
+	}
+
+	conn := bytes.NewBuffer(make([]byte, 0, 65536))
+	expected := make([]byte, 0, 65536)
+
+	for sIdx := range segments {
+		_, wErr := encode.Encode(conn).WriteAll(segments[sIdx]...)
+
+		if wErr != nil {
+			t.Errorf("Failed to write segment %d due to error: %s",
+				sIdx, wErr)
+
+			return
+		}
+
+		for ssIdx := range segments[sIdx] {
+			expected = append(expected, segments[sIdx][ssIdx]...)
+		}
+	}
+
+	if conn.Len() >= len(expected) {
+		t.Errorf("Expecting data to be compressed, got %d bytes from "+
+			"%d bytes", conn.Len(), len(expected))
+
+		return
+	}
+
+	if conn.Len() < len(random) {
+		t.Errorf("Expecting random data to be sent without compression, "+
+			"got %d bytes in total", conn.Len())
+
+		return
+	}
+
+	result := make([]byte, 0, len(expected))
+	buf := make([]byte, 3)
+
+	for len(result) < len(expected) {
+		rLen, rErr := decode.Decode(conn).Read(buf)
+
+		if rErr != nil {
+			t.Error("Failed to read due to error:", rErr)
+
+			return
+		}
+
+		result = append(result, buf[:rLen]...)
+	}
+
+	if !bytes.Equal(result, expected) {
+		t.Error("Decoded data is different from the original one")
+
+		return
+	}
+
+	_, rErr = decode.Decode(conn).Read(buf)
+
+	if rErr != io.EOF {
+		t.Errorf("Expecting error %s, got %s", io.EOF, rErr)
+
+		return
+	}
+}
+
+func TestDeflateLargeWrite(t *testing.T) {
+	encode, decode := testDeflateCodecs(t, Config{
+		Level:            flate.BestSpeed,
+		EntropyThreshold: 0,
+		EntropySample:    0,
+	})
+
+	if encode == nil {
+		return
+	}
+
+	data := make([]byte, MaxFrameDataSize*2+100)
+
+	for dIdx := range data {
+		data[dIdx] = byte(dIdx % 7)
+	}
+
+	conn := bytes.NewBuffer(make([]byte, 0, 65536))
+
+	wLen, wErr := encode.Encode(conn).WriteAll(data[:10], data[10:])
+
+	if wErr != nil {
+		t.Error("Failed to write due to error:", wErr)
+
+		return
+	}
+
+	if wLen != len(data) {
+		t.Errorf("Expecting %d bytes to be written, got %d",
+			len(data), wLen)
+
+		return
+	}
+
+	result := make([]byte, len(data))
+
+	_, rErr := io.ReadFull(decode.Decode(conn), result)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(result, data) {
+		t.Error("Decoded data is different from the original one")
+
+		return
+	}
+}
+
+func TestDeflateInvalidFrame(t *testing.T) {
+	_, decode := testDeflateCodecs(t, Config{
+		Level:            flate.DefaultCompression,
+		EntropyThreshold: 0,
+		EntropySample:    0,
+	})
+
+	if decode == nil {
+		return
+	}
+
+	conn := bytes.NewBuffer([]byte{3, 0, 0, 1, 0, 0, 1, 0})
+
+	_, rErr := decode.Decode(conn).Read(make([]byte, 1))
+
+	if rErr != ErrInvalidFrame {
+		t.Errorf("Expecting error %s, got %s", ErrInvalidFrame, rErr)
+
+		return
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	random := make([]byte, 4096)
+
+	_, rErr := rand.Read(random)
+
+	if rErr != nil {
+		t.Error("Failed to generate random data due to error:", rErr)
+
+		return
+	}
+
+	if e := entropy([][]byte{random}, len(random), 1024); e < 7.5 {
+		t.Errorf("Expecting entropy of random data to be higher than "+
+			"7.5, got %f", e)
+
+		return
+	}
+
+	repeated := bytes.Repeat([]byte("ABCD"), 1024)
+
+	e := entropy(
+		[][]byte{repeated[:3], repeated[3:]}, len(repeated), 1024)
+
+	if e > 2.5 {
+		t.Errorf("Expecting entropy of repeated data to be lower than "+
+			"2.5, got %f", e)
+
+		return
+	}
+}
@@ -0,0 +1,146 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package deflate
+
+import (
+	"io"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+type encoder struct {
+	d *deflate
+	w io.Writer
+}
+
+// split takes at most max bytes of data from b
+func split(b [][]byte, max int) ([][]byte, int, [][]byte) {
+	size := 0
+
+	for bIdx := range b {
+		bLen := len(b[bIdx])
+
+		if size+bLen <= max {
+			size += bLen
+
+			continue
+		}
+
+		cut := max - size
+		head := make([][]byte, bIdx+1)
+
+		copy(head, b[:bIdx])
+		head[bIdx] = b[bIdx][:cut]
+
+		tail := make([][]byte, len(b)-bIdx)
+
+		copy(tail, b[bIdx:])
+		tail[0] = b[bIdx][cut:]
+
+		return head, max, tail
+	}
+
+	return b, size, nil
+}
+
+func (e encoder) compressible(b [][]byte, size int) bool {
+	if e.d.cfg.EntropyThreshold <= 0 {
+		return true
+	}
+
+	return entropy(b, size, e.d.cfg.EntropySample) <=
+		e.d.cfg.EntropyThreshold
+}
+
+func (e encoder) writeFrame(b [][]byte, size int) error {
+	buf := e.d.writeBuf
+
+	buf.Reset()
+	buf.Write(make([]byte, frameHeaderSize))
+
+	if !e.compressible(b, size) {
+		for bIdx := range b {
+			buf.Write(b[bIdx])
+		}
+
+		frame := buf.Bytes()
+
+		frame[0] = frameTypeRaw
+		putFrameSize(frame[1:4], size)
+		putFrameSize(frame[4:7], size)
+
+		_, wErr := rw.WriteFull(e.w, frame)
+
+		return wErr
+	}
+
+	for bIdx := range b {
+		_, wErr := e.d.writer.Write(b[bIdx])
+
+		if wErr != nil {
+			return transceiver.WrapCodecError(wErr)
+		}
+	}
+
+	fErr := e.d.writer.Flush()
+
+	if fErr != nil {
+		return transceiver.WrapCodecError(fErr)
+	}
+
+	frame := buf.Bytes()
+
+	frame[0] = frameTypeDeflated
+	putFrameSize(frame[1:4], size)
+	putFrameSize(frame[4:7], len(frame)-frameHeaderSize)
+
+	_, wErr := rw.WriteFull(e.w, frame)
+
+	return wErr
+}
+
+func (e encoder) Write(b []byte) (int, error) {
+	return e.WriteAll(b)
+}
+
+func (e encoder) WriteAll(b ...[]byte) (int, error) {
+	totalWriteLen := 0
+
+	for len(b) > 0 {
+		frame, frameSize, remain := split(b, MaxFrameDataSize)
+
+		if frameSize <= 0 {
+			break
+		}
+
+		wErr := e.writeFrame(frame, frameSize)
+
+		if wErr != nil {
+			return totalWriteLen, wErr
+		}
+
+		totalWriteLen += frameSize
+		b = remain
+	}
+
+	return totalWriteLen, nil
+}
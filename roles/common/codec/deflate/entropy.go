@@ -0,0 +1,70 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package deflate
+
+import (
+	"math"
+)
+
+// entropy estimates the Shannon entropy (in bits per byte) of the data
+// by sampling at most sample bytes evenly from it
+func entropy(b [][]byte, size int, sample int) float64 {
+	if size <= 0 || sample <= 0 {
+		return 0
+	}
+
+	step := 1
+
+	if size > sample {
+		step = size / sample
+	}
+
+	counts := [256]int{}
+	sampled := 0
+	skip := 0
+
+	for bIdx := range b {
+		pos := skip
+
+		for ; pos < len(b[bIdx]) && sampled < sample; pos += step {
+			counts[b[bIdx][pos]]++
+			sampled++
+		}
+
+		if pos >= len(b[bIdx]) {
+			skip = pos - len(b[bIdx])
+		}
+	}
+
+	result := 0.0
+
+	for cIdx := range counts {
+		if counts[cIdx] <= 0 {
+			continue
+		}
+
+		p := float64(counts[cIdx]) / float64(sampled)
+
+		result -= p * math.Log2(p)
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"compress/flate"
+	"testing"
+)
+
+func TestDeflateSettingParser(t *testing.T) {
+	tests := []struct {
+		Setting   []string
+		Level     int
+		Threshold float64
+		Sample    int
+		Failure   bool
+	}{
+		{[]string{}, flate.DefaultCompression, 7.5, 1024, false},
+		{[]string{"Level: 1"}, 1, 7.5, 1024, false},
+		{[]string{"Level: 9", "Entropy-Threshold: 0"}, 9, 0, 1024, false},
+		{[]string{"Entropy-Threshold: 7", "Entropy-Sample: 256"},
+			flate.DefaultCompression, 7, 256, false},
+		{[]string{"Level: 0"}, 0, 0, 0, true},
+		{[]string{"Level: 10"}, 0, 0, 0, true},
+		{[]string{"Entropy-Threshold: 9"}, 0, 0, 0, true},
+		{[]string{"Entropy-Sample: 0"}, 0, 0, 0, true},
+		{[]string{"Fast"}, 0, 0, 0, true},
+	}
+
+	for tIdx, test := range tests {
+		result, resultErr := deflateSettingParser(test.Setting)
+
+		if test.Failure {
+			if resultErr == nil {
+				t.Errorf("Test %d: Expecting failure", tIdx)
+
+				return
+			}
+
+			continue
+		}
+
+		if resultErr != nil {
+			t.Errorf("Test %d: Unexpected error: %s", tIdx, resultErr)
+
+			return
+		}
+
+		if result.Config.Level != test.Level ||
+			result.Config.EntropyThreshold != test.Threshold ||
+			result.Config.EntropySample != test.Sample {
+			t.Errorf("Test %d: Unexpected result: %+v", tIdx, result.Config)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/aesgcm"
+	"github.com/reinit/coward/roles/common/codec/chacha"
+	"github.com/reinit/coward/roles/common/codec/ephemeral"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// AESGCM256X25519 return a AES-GCM-256 Transceiver Codec which uses
+// forward-secret keys exchanged through ephemeral X25519
+func AESGCM256X25519() transceiver.Codec {
+	return transceiver.Codec{
+		Name:   "aes-gcm-256-x25519",
+		Usage:  timedSkewUsage,
+		Build:  aesGCM256X25519Builder,
+		Verify: timedSettingVerifier(timedSkewOptions),
+	}
+}
+
+// ChaCha20Poly1305X25519 return a ChaCha20-Poly1305 Transceiver Codec
+// which uses forward-secret keys exchanged through ephemeral X25519
+func ChaCha20Poly1305X25519() transceiver.Codec {
+	return transceiver.Codec{
+		Name:   "chacha20-poly1305-x25519",
+		Usage:  timedSkewUsage,
+		Build:  chaCha20Poly1305X25519Builder,
+		Verify: timedSettingVerifier(timedSkewOptions),
+	}
+}
+
+func aesGCM256X25519Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
+
+	return func() (rw.Codec, error) {
+		return setting.wrap(ephemeral.Ephemeral(timedKey, func(
+			k key.Key) (rw.Codec, error) {
+			return aesgcm.AESGCM(k, 32, marker.None)
+		}, timedMarkers, reporter), nil)
+	}
+}
+
+func chaCha20Poly1305X25519Builder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting := timedSettingBuilder(configuration, timedSkewOptions)
+	timedKey := setting.key()
+	timedMarkers := setting.marker(m)
+	reporter := setting.reporter(log)
+
+	return func() (rw.Codec, error) {
+		return setting.wrap(ephemeral.Ephemeral(timedKey, func(
+			k key.Key) (rw.Codec, error) {
+			return chacha.ChaCha20Poly1305(k, marker.None)
+		}, timedMarkers, reporter), nil)
+	}
+}
@@ -0,0 +1,313 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package ephemeral
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/codec/skew"
+	"github.com/reinit/coward/roles/common/transceiver"
+	"golang.org/x/crypto/curve25519"
+)
+
+// Consts
+const (
+	pskSize       = 32
+	handshakeSize = curve25519.PointSize + sha256.Size
+
+	// publicTopBit is the most significant bit of an X25519 public key,
+	// which is always clear
+	publicTopBit = 0x80
+)
+
+// Vars
+var (
+	handshakeClientLabel = []byte("COWARD X25519 Client")
+	handshakeServerLabel = []byte("COWARD X25519 Server")
+	keyClientToServer    = []byte("COWARD X25519 Client To Server")
+	keyServerToClient    = []byte("COWARD X25519 Server To Client")
+)
+
+// Errors
+var (
+	ErrHandshakeAuthenticationFailed = transceiver.NewCodecError(
+		"X25519 handshake can't be authenticated with the shared key")
+
+	ErrHandshakeIncomplete = transceiver.NewCodecError(
+		"X25519 handshake must be completed before encode or decode")
+)
+
+// Builder builds a Codec which encodes or decodes data with given key
+type Builder func(k key.Key) (rw.Codec, error)
+
+type ephemeral struct {
+	psk     key.Skewed
+	builder Builder
+	mark    marker.Marker
+	report  skew.Reporter
+	encoder rw.Codec
+	decoder rw.Codec
+}
+
+// Ephemeral returns a Codec which exchanges an ephemeral X25519 key with
+// the remote, authenticates the exchange with the pre-shared key and
+// then encode and decode data with the keys derived from the exchange.
+// The keys are discarded when the connection is closed, so past sessions
+// won't be exposed even when the pre-shared key has been compromised.
+// The responder accepts pre-shared keys of all Skews of the psk
+func Ephemeral(
+	psk key.Skewed,
+	builder Builder,
+	mark marker.Marker,
+	report skew.Reporter,
+) rw.Codec {
+	return &ephemeral{
+		psk:     psk,
+		builder: builder,
+		mark:    mark,
+		report:  report,
+		encoder: nil,
+		decoder: nil,
+	}
+}
+
+// hide sets the always clear top bit of the public key inside the given
+// buffer to a random value, so it won't be a constant on the wire
+func (e *ephemeral) hide(buf []byte) error {
+	random := [1]byte{}
+
+	_, rErr := rand.Read(random[:])
+
+	if rErr != nil {
+		return transceiver.WrapCodecError(rErr)
+	}
+
+	buf[curve25519.PointSize-1] |= random[0] & publicTopBit
+
+	return nil
+}
+
+// reveal clears the randomized top bit of a received public key
+func (e *ephemeral) reveal(public []byte) {
+	public[curve25519.PointSize-1] &^= publicTopBit
+}
+
+func (e *ephemeral) sign(psk []byte, label []byte, pubs ...[]byte) []byte {
+	hasher := hmac.New(sha256.New, psk)
+
+	hasher.Write(label)
+
+	for pIdx := range pubs {
+		hasher.Write(pubs[pIdx])
+	}
+
+	return hasher.Sum(nil)
+}
+
+// verify finds the pre-shared key which was used to sign the client
+// public key
+func (e *ephemeral) verify(
+	clientPublic []byte, sign []byte) ([]byte, error) {
+	skews := e.psk.Skews()
+
+	for sIdx := range skews {
+		psk, pskErr := skews[sIdx].Key.Get(pskSize)
+
+		if pskErr != nil {
+			return nil, pskErr
+		}
+
+		if !hmac.Equal(sign, e.sign(psk, handshakeClientLabel, clientPublic)) {
+			continue
+		}
+
+		e.report(skews[sIdx])
+
+		return psk, nil
+	}
+
+	return nil, ErrHandshakeAuthenticationFailed
+}
+
+// Handshake exchanges ephemeral keys with the remote
+func (e *ephemeral) Handshake(conn io.ReadWriter, initiator bool) error {
+	var psk []byte
+
+	private := [curve25519.ScalarSize]byte{}
+
+	_, rErr := rand.Read(private[:])
+
+	if rErr != nil {
+		return transceiver.WrapCodecError(rErr)
+	}
+
+	public, publicErr := curve25519.X25519(private[:], curve25519.Basepoint)
+
+	if publicErr != nil {
+		return transceiver.WrapCodecError(publicErr)
+	}
+
+	var clientPublic, serverPublic []byte
+
+	buf := [handshakeSize]byte{}
+
+	if initiator {
+		var pskErr error
+
+		psk, pskErr = e.psk.Get(pskSize)
+
+		if pskErr != nil {
+			return pskErr
+		}
+
+		clientPublic = public
+
+		copy(buf[:curve25519.PointSize], clientPublic)
+		copy(buf[curve25519.PointSize:],
+			e.sign(psk, handshakeClientLabel, clientPublic))
+
+		hideErr := e.hide(buf[:curve25519.PointSize])
+
+		if hideErr != nil {
+			return hideErr
+		}
+
+		_, wErr := rw.WriteFull(conn, buf[:])
+
+		if wErr != nil {
+			return wErr
+		}
+
+		_, rErr = io.ReadFull(conn, buf[:])
+
+		if rErr != nil {
+			return rErr
+		}
+
+		serverPublic = buf[:curve25519.PointSize]
+
+		e.reveal(serverPublic)
+
+		if !hmac.Equal(buf[curve25519.PointSize:], e.sign(
+			psk, handshakeServerLabel, clientPublic, serverPublic)) {
+			return ErrHandshakeAuthenticationFailed
+		}
+	} else {
+		_, rErr = io.ReadFull(conn, buf[:])
+
+		if rErr != nil {
+			return rErr
+		}
+
+		clientPublic = make([]byte, curve25519.PointSize)
+		serverPublic = public
+
+		copy(clientPublic, buf[:curve25519.PointSize])
+
+		e.reveal(clientPublic)
+
+		var pskErr error
+
+		psk, pskErr = e.verify(clientPublic, buf[curve25519.PointSize:])
+
+		if pskErr != nil {
+			return pskErr
+		}
+
+		markErr := e.mark.Mark(marker.Mark(clientPublic))
+
+		if markErr != nil {
+			return transceiver.WrapCodecError(markErr)
+		}
+
+		copy(buf[:curve25519.PointSize], serverPublic)
+		copy(buf[curve25519.PointSize:], e.sign(
+			psk, handshakeServerLabel, clientPublic, serverPublic))
+
+		hideErr := e.hide(buf[:curve25519.PointSize])
+
+		if hideErr != nil {
+			return hideErr
+		}
+
+		_, wErr := rw.WriteFull(conn, buf[:])
+
+		if wErr != nil {
+			return wErr
+		}
+	}
+
+	peerPublic := serverPublic
+
+	if !initiator {
+		peerPublic = clientPublic
+	}
+
+	shared, sharedErr := curve25519.X25519(private[:], peerPublic)
+
+	if sharedErr != nil {
+		return transceiver.WrapCodecError(sharedErr)
+	}
+
+	c2s, c2sErr := e.builder(derived(
+		shared, psk, keyClientToServer, clientPublic, serverPublic))
+
+	if c2sErr != nil {
+		return c2sErr
+	}
+
+	s2c, s2cErr := e.builder(derived(
+		shared, psk, keyServerToClient, clientPublic, serverPublic))
+
+	if s2cErr != nil {
+		return s2cErr
+	}
+
+	if initiator {
+		e.encoder, e.decoder = c2s, s2c
+	} else {
+		e.encoder, e.decoder = s2c, c2s
+	}
+
+	return nil
+}
+
+func (e *ephemeral) Encode(w io.Writer) rw.WriteWriteAll {
+	if e.encoder == nil {
+		return incomplete{}
+	}
+
+	return e.encoder.Encode(w)
+}
+
+func (e *ephemeral) Decode(r io.Reader) io.Reader {
+	if e.decoder == nil {
+		return incomplete{}
+	}
+
+	return e.decoder.Decode(r)
+}
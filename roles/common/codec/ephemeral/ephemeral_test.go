@@ -0,0 +1,258 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package ephemeral
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/aesgcm"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+)
+
+type dummyKey struct {
+	Key []byte
+}
+
+func (d dummyKey) Get(size int) ([]byte, error) {
+	result := make([]byte, size)
+
+	copy(result, d.Key)
+
+	return result, nil
+}
+
+func (d dummyKey) Skews() []key.Skew {
+	return []key.Skew{{Key: d, Skew: 0}}
+}
+
+type dummyMark struct{}
+
+func (d dummyMark) Mark(marker.Mark) error {
+	return nil
+}
+
+func testEphemeral(k key.Skewed) rw.Codec {
+	return Ephemeral(k, func(k key.Key) (rw.Codec, error) {
+		return aesgcm.AESGCM(k, 32, dummyMark{})
+	}, dummyMark{}, func(key.Skew) {})
+}
+
+func testHandshake(
+	client rw.Codec,
+	server rw.Codec,
+	clientConn net.Conn,
+	serverConn net.Conn,
+) (error, error) {
+	serverErr := make(chan error, 1)
+
+	go func() {
+		sErr := server.(*ephemeral).Handshake(serverConn, false)
+
+		if sErr != nil {
+			serverConn.Close()
+		}
+
+		serverErr <- sErr
+	}()
+
+	cErr := client.(*ephemeral).Handshake(clientConn, true)
+
+	if cErr != nil {
+		clientConn.Close()
+	}
+
+	return cErr, <-serverErr
+}
+
+func TestEphemeral(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	client := testEphemeral(dummyKey{Key: []byte("Test Key")})
+	server := testEphemeral(dummyKey{Key: []byte("Test Key")})
+
+	cErr, sErr := testHandshake(client, server, clientConn, serverConn)
+
+	if cErr != nil || sErr != nil {
+		t.Errorf("Failed to handshake due to error: %s, %s", cErr, sErr)
+
+		return
+	}
+
+	if client.(*ephemeral).encoder == server.(*ephemeral).decoder {
+		t.Error("Expecting different codec instances")
+
+		return
+	}
+
+	for _, test := range []struct {
+		From rw.Codec
+		To   rw.Codec
+		Data []byte
+	}{
+		{client, server, []byte("Hello Server")},
+		{server, client, []byte("Hello Client")},
+	} {
+		buf := bytes.NewBuffer(nil)
+
+		_, wErr := test.From.Encode(buf).Write(test.Data)
+
+		if wErr != nil {
+			t.Error("Failed to write due to error:", wErr)
+
+			return
+		}
+
+		result := make([]byte, len(test.Data))
+
+		_, rErr := io.ReadFull(test.To.Decode(buf), result)
+
+		if rErr != nil {
+			t.Error("Failed to read due to error:", rErr)
+
+			return
+		}
+
+		if !bytes.Equal(result, test.Data) {
+			t.Errorf("Expecting %q, got %q", test.Data, result)
+
+			return
+		}
+	}
+
+	// Keys of different directions must not be the same
+	buf := bytes.NewBuffer(nil)
+
+	client.Encode(buf).Write([]byte("Hello"))
+
+	_, rErr := client.Decode(buf).Read(make([]byte, 5))
+
+	if rErr == nil {
+		t.Error("Data encoded for the server must not be decoded by client")
+
+		return
+	}
+}
+
+func TestEphemeralWrongKey(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	client := testEphemeral(dummyKey{Key: []byte("Test Key")})
+	server := testEphemeral(dummyKey{Key: []byte("Wrong Key")})
+
+	_, sErr := testHandshake(client, server, clientConn, serverConn)
+
+	if sErr != ErrHandshakeAuthenticationFailed {
+		t.Errorf("Expecting error %s, got %s",
+			ErrHandshakeAuthenticationFailed, sErr)
+
+		return
+	}
+
+	_, wErr := client.Encode(bytes.NewBuffer(nil)).Write([]byte("Hello"))
+
+	if wErr != ErrHandshakeIncomplete {
+		t.Errorf("Expecting error %s, got %s", ErrHandshakeIncomplete, wErr)
+
+		return
+	}
+}
+
+func TestEphemeralSkewed(t *testing.T) {
+	clientConn, serverConn := net.Pipe()
+
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	now := time.Now()
+	reported := time.Duration(0)
+
+	client := testEphemeral(key.Timed(
+		[]byte("Test Key"), 10*time.Second, 0, func() time.Time {
+			return now.Add(20 * time.Second)
+		}))
+	server := Ephemeral(key.Timed(
+		[]byte("Test Key"), 10*time.Second, 2, func() time.Time {
+			return now
+		}), func(k key.Key) (rw.Codec, error) {
+		return aesgcm.AESGCM(k, 32, dummyMark{})
+	}, dummyMark{}, func(used key.Skew) {
+		reported = used.Skew
+	})
+
+	cErr, sErr := testHandshake(client, server, clientConn, serverConn)
+
+	if cErr != nil || sErr != nil {
+		t.Errorf("Failed to handshake due to error: %s, %s", cErr, sErr)
+
+		return
+	}
+
+	if reported != 20*time.Second {
+		t.Errorf("Expecting skew %s, got %s", 20*time.Second, reported)
+
+		return
+	}
+}
+
+func TestEphemeralHidePublic(t *testing.T) {
+	e := testEphemeral(dummyKey{Key: []byte("Test")}).(*ephemeral)
+	seen := [2]bool{}
+
+	for i := 0; i < 64; i++ {
+		public := make([]byte, 32)
+
+		hideErr := e.hide(public)
+
+		if hideErr != nil {
+			t.Error("Failed to hide public key due to error:", hideErr)
+
+			return
+		}
+
+		seen[public[31]>>7] = true
+
+		e.reveal(public)
+
+		if !bytes.Equal(public, make([]byte, 32)) {
+			t.Error("Expecting the revealed public key to be unchanged")
+
+			return
+		}
+	}
+
+	if !seen[0] || !seen[1] {
+		t.Error("Expecting the top bit of the public key to be randomized")
+
+		return
+	}
+}
@@ -0,0 +1,37 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package ephemeral
+
+// incomplete is returned as the encoder and decoder before the handshake
+// is completed
+type incomplete struct{}
+
+func (i incomplete) Read(b []byte) (int, error) {
+	return 0, ErrHandshakeIncomplete
+}
+
+func (i incomplete) Write(b []byte) (int, error) {
+	return 0, ErrHandshakeIncomplete
+}
+
+func (i incomplete) WriteAll(b ...[]byte) (int, error) {
+	return 0, ErrHandshakeIncomplete
+}
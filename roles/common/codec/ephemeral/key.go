@@ -0,0 +1,69 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package ephemeral
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// derivedKey is a key.Key which derives key from the shared secret of a
+// key exchange
+type derivedKey struct {
+	secret []byte
+	salt   []byte
+	info   []byte
+}
+
+func derived(
+	secret []byte,
+	salt []byte,
+	label []byte,
+	clientPublic []byte,
+	serverPublic []byte,
+) derivedKey {
+	info := make([]byte, 0, len(label)+len(clientPublic)+len(serverPublic))
+
+	info = append(info, label...)
+	info = append(info, clientPublic...)
+	info = append(info, serverPublic...)
+
+	return derivedKey{
+		secret: secret,
+		salt:   salt,
+		info:   info,
+	}
+}
+
+func (d derivedKey) Get(size int) ([]byte, error) {
+	result := make([]byte, size)
+
+	_, rErr := io.ReadFull(hkdf.New(sha256.New, d.secret, d.salt, d.info),
+		result)
+
+	if rErr != nil {
+		return nil, rErr
+	}
+
+	return result, nil
+}
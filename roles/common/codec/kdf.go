@@ -0,0 +1,198 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/reinit/coward/roles/common/codec/key"
+)
+
+// Consts
+const (
+	kdfDefaultSalt = "Crypto-Obscured Forwarder"
+
+	kdfArgon2idDefaultTime    = 3
+	kdfArgon2idDefaultMemory  = 64 * 1024
+	kdfArgon2idDefaultThreads = 4
+	kdfScryptDefaultN         = 32768
+	kdfScryptDefaultR         = 8
+	kdfScryptDefaultP         = 1
+
+	kdfUsage = "\r\n\r\nBy default, the shared key is hashed with " +
+		"SHA-256, which is cheap to brute-force when the key is weak. " +
+		"\"KDF\" option selects a slower key derivation function, either " +
+		"\"argon2id\" or \"scrypt\". Parameters can be changed through " +
+		"the \"KDF-Params\" option, which is \"t\" (iterations, default: " +
+		"3), \"m\" (memory in KiB, default: 65536) and \"p\" (threads, " +
+		"default: 4) for argon2id, or \"N\" (default: 32768), \"r\" " +
+		"(default: 8) and \"p\" (default: 1) for scrypt. \"KDF-Salt\" " +
+		"option sets the salt, it's recommended to be unique for each " +
+		"deployment. These options must be the same on both side. " +
+		"Example:\r\n\r\nKDF: argon2id\r\nKDF-Params: t=3, m=65536, p=4" +
+		"\r\nKDF-Salt: <Unique Salt>"
+)
+
+// Vars
+var (
+	kdfOptions = []string{
+		"KDF", "KDF-Params", "KDF-Salt",
+	}
+)
+
+// kdfSetting is the setting of the key derivation function, the
+// passphrase will be used directly when KDF is nil
+type kdfSetting struct {
+	KDF key.KDF
+}
+
+// kdfParams parses "<Name>=<Value>, ..." into the params. Only names
+// that already in the params are accepted
+func kdfParams(value string, params map[string]uint64) error {
+	for _, param := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		eqIdx := strings.Index(param, "=")
+
+		if eqIdx <= 0 {
+			return fmt.Errorf(
+				"Invalid parameter \"%s\" of \"KDF-Params\" option. It "+
+					"must be in \"<Name>=<Value>\" format", param)
+		}
+
+		name := param[:eqIdx]
+
+		_, known := params[name]
+
+		if !known {
+			return fmt.Errorf(
+				"Unknown parameter \"%s\" of \"KDF-Params\" option", name)
+		}
+
+		v, vErr := strconv.ParseUint(param[eqIdx+1:], 10, 32)
+
+		if vErr != nil || v <= 0 {
+			return fmt.Errorf(
+				"Invalid value of the parameter \"%s\" of \"KDF-Params\" "+
+					"option. It must be a positive number", name)
+		}
+
+		params[name] = v
+	}
+
+	return nil
+}
+
+// kdfSettingParser parse kdfSetting from Codec options
+func kdfSettingParser(setting codecSetting) (kdfSetting, error) {
+	kdf := strings.TrimSpace(string(setting["KDF"]))
+	params := strings.TrimSpace(string(setting["KDF-Params"]))
+	salt := []byte(kdfDefaultSalt)
+
+	if len(setting["KDF-Salt"]) > 0 {
+		salt = setting["KDF-Salt"]
+	}
+
+	switch strings.ToLower(kdf) {
+	case "", "sha256":
+		if len(params) > 0 || len(setting["KDF-Salt"]) > 0 {
+			return kdfSetting{}, errors.New(
+				"\"KDF-Params\" and \"KDF-Salt\" option requires the " +
+					"\"KDF\" option to be set")
+		}
+
+		return kdfSetting{KDF: nil}, nil
+
+	case "argon2id":
+		p := map[string]uint64{
+			"t": kdfArgon2idDefaultTime,
+			"m": kdfArgon2idDefaultMemory,
+			"p": kdfArgon2idDefaultThreads,
+		}
+
+		pErr := kdfParams(params, p)
+
+		if pErr != nil {
+			return kdfSetting{}, pErr
+		}
+
+		if p["p"] > 255 || p["m"] < 8*p["p"] {
+			return kdfSetting{}, errors.New(
+				"Invalid \"KDF-Params\" option. For argon2id, \"p\" must " +
+					"not be larger than 255, and \"m\" must be at least " +
+					"8 times of \"p\"")
+		}
+
+		return kdfSetting{
+			KDF: key.Argon2id(
+				salt, uint32(p["t"]), uint32(p["m"]), uint8(p["p"])),
+		}, nil
+
+	case "scrypt":
+		p := map[string]uint64{
+			"N": kdfScryptDefaultN,
+			"r": kdfScryptDefaultR,
+			"p": kdfScryptDefaultP,
+		}
+
+		pErr := kdfParams(params, p)
+
+		if pErr != nil {
+			return kdfSetting{}, pErr
+		}
+
+		if p["N"] <= 1 || p["N"]&(p["N"]-1) != 0 ||
+			(p["r"] < 4 && p["N"] >= 1<<(16*p["r"])) ||
+			p["r"]*p["p"] >= 1<<30 {
+			return kdfSetting{}, errors.New(
+				"Invalid \"KDF-Params\" option. For scrypt, \"N\" must " +
+					"be a power of 2 larger than 1 and smaller than " +
+					"2^(16 * \"r\"), and \"r\" * \"p\" must be smaller " +
+					"than 2^30")
+		}
+
+		return kdfSetting{
+			KDF: key.Scrypt(salt, int(p["N"]), int(p["r"]), int(p["p"])),
+		}, nil
+	}
+
+	return kdfSetting{}, fmt.Errorf(
+		"Invalid value \"%s\" of \"KDF\" option. It must be either "+
+			"\"argon2id\" or \"scrypt\"", kdf)
+}
+
+// derive derives the key from the passphrase
+func (k kdfSetting) derive(passphrase []byte) []byte {
+	if k.KDF == nil {
+		return passphrase
+	}
+
+	derived, derivedErr := k.KDF.Derive(passphrase)
+
+	if derivedErr != nil {
+		panic(fmt.Sprintf("Failed to derive key: %s", derivedErr))
+	}
+
+	return derived
+}
@@ -0,0 +1,94 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"testing"
+)
+
+func TestKDFSettingParser(t *testing.T) {
+	tests := []struct {
+		Setting codecSetting
+		Enabled bool
+		Failure bool
+	}{
+		{codecSetting{}, false, false},
+		{codecSetting{"KDF": []byte("sha256")}, false, false},
+		{codecSetting{"KDF": []byte("argon2id")}, true, false},
+		{codecSetting{
+			"KDF":        []byte("argon2id"),
+			"KDF-Params": []byte("t=1, m=64, p=2"),
+			"KDF-Salt":   []byte("Salt"),
+		}, true, false},
+		{codecSetting{
+			"KDF":        []byte("scrypt"),
+			"KDF-Params": []byte("N=1024 r=8 p=1"),
+		}, true, false},
+		{codecSetting{"KDF": []byte("md5")}, false, true},
+		{codecSetting{"KDF-Salt": []byte("Salt")}, false, true},
+		{codecSetting{
+			"KDF":        []byte("argon2id"),
+			"KDF-Params": []byte("N=1024"),
+		}, false, true},
+		{codecSetting{
+			"KDF":        []byte("argon2id"),
+			"KDF-Params": []byte("m=8, p=4"),
+		}, false, true},
+		{codecSetting{
+			"KDF":        []byte("scrypt"),
+			"KDF-Params": []byte("N=1000"),
+		}, false, true},
+		{codecSetting{
+			"KDF":        []byte("scrypt"),
+			"KDF-Params": []byte("N=65536, r=1"),
+		}, false, true},
+		{codecSetting{
+			"KDF":        []byte("scrypt"),
+			"KDF-Params": []byte("r"),
+		}, false, true},
+	}
+
+	for tIdx, test := range tests {
+		result, resultErr := kdfSettingParser(test.Setting)
+
+		if test.Failure {
+			if resultErr == nil {
+				t.Errorf("Test %d: Expecting failure", tIdx)
+
+				return
+			}
+
+			continue
+		}
+
+		if resultErr != nil {
+			t.Errorf("Test %d: Unexpected error: %s", tIdx, resultErr)
+
+			return
+		}
+
+		if (result.KDF != nil) != test.Enabled {
+			t.Errorf("Test %d: Unexpected result: %v", tIdx, result)
+
+			return
+		}
+	}
+}
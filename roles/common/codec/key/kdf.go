@@ -0,0 +1,81 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Consts
+const (
+	// DerivedSize is the size of the key derived by a KDF
+	DerivedSize = 32
+)
+
+// KDF derives a key from a passphrase
+type KDF interface {
+	Derive(passphrase []byte) ([]byte, error)
+}
+
+type argon2idKDF struct {
+	salt    []byte
+	time    uint32
+	memory  uint32
+	threads uint8
+}
+
+type scryptKDF struct {
+	salt []byte
+	n    int
+	r    int
+	p    int
+}
+
+// Argon2id returns a KDF which derives keys with Argon2id. memory is
+// in KiB
+func Argon2id(salt []byte, time uint32, memory uint32, threads uint8) KDF {
+	return argon2idKDF{
+		salt:    salt,
+		time:    time,
+		memory:  memory,
+		threads: threads,
+	}
+}
+
+// Scrypt returns a KDF which derives keys with scrypt
+func Scrypt(salt []byte, n int, r int, p int) KDF {
+	return scryptKDF{
+		salt: salt,
+		n:    n,
+		r:    r,
+		p:    p,
+	}
+}
+
+func (a argon2idKDF) Derive(passphrase []byte) ([]byte, error) {
+	return argon2.IDKey(
+		passphrase, a.salt, a.time, a.memory, a.threads, DerivedSize), nil
+}
+
+func (s scryptKDF) Derive(passphrase []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, s.salt, s.n, s.r, s.p, DerivedSize)
+}
@@ -0,0 +1,70 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKDF(t *testing.T) {
+	for _, kdfs := range [][2]KDF{
+		{
+			Argon2id([]byte("Salt 1"), 1, 64, 1),
+			Argon2id([]byte("Salt 2"), 1, 64, 1),
+		},
+		{
+			Scrypt([]byte("Salt 1"), 16, 1, 1),
+			Scrypt([]byte("Salt 2"), 16, 1, 1),
+		},
+	} {
+		first, firstErr := kdfs[0].Derive([]byte("Passphrase"))
+
+		if firstErr != nil {
+			t.Error("Failed to derive key due to error:", firstErr)
+
+			return
+		}
+
+		if len(first) != DerivedSize {
+			t.Errorf("Expecting a %d bytes key, got %d bytes",
+				DerivedSize, len(first))
+
+			return
+		}
+
+		again, _ := kdfs[0].Derive([]byte("Passphrase"))
+
+		if !bytes.Equal(first, again) {
+			t.Error("Same passphrase and salt must derive the same key")
+
+			return
+		}
+
+		salted, _ := kdfs[1].Derive([]byte("Passphrase"))
+
+		if bytes.Equal(first, salted) {
+			t.Error("Different salt must derive a different key")
+
+			return
+		}
+	}
+}
@@ -20,7 +20,27 @@
 
 package key
 
+import "time"
+
 // Key is the key generater
 type Key interface {
 	Get(size int) ([]byte, error)
 }
+
+// Skew is the Key of a time period which is adjacent to the current one.
+// Previous is 0 when it's derived from the primary key, otherwise it's
+// the number of the previous key which it's derived from
+type Skew struct {
+	Key      Key
+	Skew     time.Duration
+	Previous int
+}
+
+// Skewed is a Key which tolerates the clock skew of the remote by also
+// providing Keys of the adjacent time periods. The Key of current time
+// period is always the first one in the Skews
+type Skewed interface {
+	Key
+
+	Skews() []Skew
+}
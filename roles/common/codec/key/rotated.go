@@ -0,0 +1,81 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"time"
+)
+
+// Previous is a previous key which is still accepted until it expires
+type Previous struct {
+	Key    Skewed
+	Expiry time.Time
+}
+
+type rotated struct {
+	primary  Skewed
+	previous []Previous
+	timer    func() time.Time
+}
+
+// Rotated returns a Key which always uses the primary key, but also
+// provides the Skews of the previous keys that has not yet expired,
+// so the remote which is still using them can be accepted. Previous
+// keys with a zero Expiry never expire
+func Rotated(
+	primary Skewed,
+	previous []Previous,
+	timer func() time.Time,
+) Skewed {
+	if len(previous) <= 0 {
+		return primary
+	}
+
+	return rotated{
+		primary:  primary,
+		previous: previous,
+		timer:    timer,
+	}
+}
+
+func (r rotated) Get(size int) ([]byte, error) {
+	return r.primary.Get(size)
+}
+
+func (r rotated) Skews() []Skew {
+	now := r.timer()
+	skews := r.primary.Skews()
+
+	for pIdx := range r.previous {
+		if !r.previous[pIdx].Expiry.IsZero() &&
+			!now.Before(r.previous[pIdx].Expiry) {
+			continue
+		}
+
+		for _, skew := range r.previous[pIdx].Key.Skews() {
+			skew.Previous = pIdx + 1
+
+			skews = append(skews, skew)
+		}
+	}
+
+	return skews
+}
@@ -0,0 +1,77 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package key
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRotated(t *testing.T) {
+	now := time.Now()
+	timer := func() time.Time { return now }
+	primary := Timed([]byte("Primary"), 10*time.Second, 1, timer)
+	expiring := Timed([]byte("Expiring"), 10*time.Second, 1, timer)
+	lasting := Timed([]byte("Lasting"), 10*time.Second, 0, timer)
+
+	k := Rotated(primary, []Previous{
+		{Key: expiring, Expiry: now.Add(time.Minute)},
+		{Key: lasting, Expiry: time.Time{}},
+	}, timer)
+
+	primaryKey, _ := primary.Get(32)
+	rotatedKey, _ := k.Get(32)
+
+	if !bytes.Equal(primaryKey, rotatedKey) {
+		t.Error("Rotated key must use the primary key")
+
+		return
+	}
+
+	skews := k.Skews()
+
+	if len(skews) != 7 {
+		t.Errorf("Expecting 7 Skews, got %d", len(skews))
+
+		return
+	}
+
+	for sIdx, expected := range []int{0, 0, 0, 1, 1, 1, 2} {
+		if skews[sIdx].Previous != expected {
+			t.Errorf("Expecting Skew %d to be derived from previous key "+
+				"%d, got %d", sIdx, expected, skews[sIdx].Previous)
+
+			return
+		}
+	}
+
+	now = now.Add(time.Minute)
+
+	skews = k.Skews()
+
+	if len(skews) != 4 || skews[3].Previous != 2 {
+		t.Errorf("Expecting the expired key to be skipped, got %d Skews",
+			len(skews))
+
+		return
+	}
+}
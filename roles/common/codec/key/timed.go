@@ -30,22 +30,65 @@ import (
 type timed struct {
 	k [sha256.Size]byte
 	d time.Duration
+	s uint8
 	t func() time.Time
 }
 
-// Timed returns a timed key generater
-func Timed(k []byte, d time.Duration, timer func() time.Time) Key {
+type timedSkew struct {
+	timed timed
+	skew  time.Duration
+}
+
+// Timed returns a timed key generater. The keys of skew amount of time
+// periods before and after the current one will be provided as Skews
+func Timed(
+	k []byte,
+	d time.Duration,
+	skew uint8,
+	timer func() time.Time,
+) Skewed {
 	return timed{
 		k: sha256.Sum256(k),
 		d: d,
+		s: skew,
 		t: timer,
 	}
 }
 
 func (t timed) Get(size int) ([]byte, error) {
+	return t.get(size, 0)
+}
+
+func (t timed) Skews() []Skew {
+	skews := make([]Skew, 0, int(t.s)*2+1)
+
+	skews = append(skews, Skew{
+		Key:      timedSkew{timed: t, skew: 0},
+		Skew:     0,
+		Previous: 0,
+	})
+
+	for sIdx := 1; sIdx <= int(t.s); sIdx++ {
+		skew := time.Duration(sIdx) * t.d
+
+		skews = append(skews, Skew{
+			Key:      timedSkew{timed: t, skew: -skew},
+			Skew:     -skew,
+			Previous: 0,
+		}, Skew{
+			Key:      timedSkew{timed: t, skew: skew},
+			Skew:     skew,
+			Previous: 0,
+		})
+	}
+
+	return skews
+}
+
+func (t timed) get(size int, skew time.Duration) ([]byte, error) {
 	hasher := hmac.New(sha256.New, t.k[:])
 	nowByte := [8]byte{}
-	nowInt := uint64(t.t().Truncate(t.d).Unix())
+	nowInt := uint64(t.t().Add(skew).Truncate(t.d).Unix())
 
 	binary.BigEndian.PutUint64(nowByte[:], nowInt)
 
@@ -61,3 +104,7 @@ func (t timed) Get(size int) ([]byte, error) {
 
 	return result, nil
 }
+
+func (t timedSkew) Get(size int) ([]byte, error) {
+	return t.timed.get(size, t.skew)
+}
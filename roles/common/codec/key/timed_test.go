@@ -28,7 +28,7 @@ import (
 
 func TestTimed(t *testing.T) {
 	testTime := time.Time{}
-	tt := Timed([]byte("Hello World"), 1*time.Second, func() time.Time {
+	tt := Timed([]byte("Hello World"), 1*time.Second, 0, func() time.Time {
 		return testTime
 	})
 
@@ -68,3 +68,47 @@ func TestTimed(t *testing.T) {
 		return
 	}
 }
+
+func TestTimedSkews(t *testing.T) {
+	testTime := time.Time{}.Add(10 * time.Second)
+	tt := Timed([]byte("Hello World"), 1*time.Second, 2, func() time.Time {
+		return testTime
+	})
+
+	skews := tt.Skews()
+
+	if len(skews) != 5 {
+		t.Errorf("Expecting 5 skews, got %d", len(skews))
+
+		return
+	}
+
+	expectedSkews := []time.Duration{
+		0, -1 * time.Second, 1 * time.Second,
+		-2 * time.Second, 2 * time.Second,
+	}
+
+	for sIdx := range skews {
+		if skews[sIdx].Skew != expectedSkews[sIdx] {
+			t.Errorf("Expecting skew %d to be %s, got %s",
+				sIdx, expectedSkews[sIdx], skews[sIdx].Skew)
+
+			return
+		}
+
+		skewedKey, _ := skews[sIdx].Key.Get(32)
+
+		testTime = testTime.Add(skews[sIdx].Skew)
+
+		expectedKey, _ := tt.Get(32)
+
+		testTime = testTime.Add(-skews[sIdx].Skew)
+
+		if !bytes.Equal(skewedKey, expectedKey) {
+			t.Errorf("Skew %d must generate the key of time period %s",
+				sIdx, skews[sIdx].Skew)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package marker
+
+import (
+	"math"
+)
+
+// Consts
+const (
+	bloomMinBits = 64
+)
+
+// bloom is a bloom filter
+type bloom struct {
+	bits   []uint64
+	size   uint64
+	hashes uint32
+}
+
+// newBloom creates a bloom filter which can hold capacity items before
+// its false positive rate exceeds falsePositive
+func newBloom(capacity int, falsePositive float64) bloom {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	size := uint64(math.Ceil(-float64(capacity) * math.Log(falsePositive) /
+		(math.Ln2 * math.Ln2)))
+
+	if size < bloomMinBits {
+		size = bloomMinBits
+	}
+
+	hashes := uint32(math.Round(
+		float64(size) / float64(capacity) * math.Ln2))
+
+	if hashes < 1 {
+		hashes = 1
+	}
+
+	return bloom{
+		bits:   make([]uint64, (size+63)/64),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// locate returns the bit position of the idx-th hash
+func (b *bloom) locate(h1 uint32, h2 uint32, idx uint32) (int, uint64) {
+	pos := (uint64(h1) + uint64(idx)*uint64(h2)) % b.size
+
+	return int(pos / 64), 1 << (pos % 64)
+}
+
+// test returns whether or not the item may have been added
+func (b *bloom) test(h1 uint32, h2 uint32) bool {
+	for hIdx := uint32(0); hIdx < b.hashes; hIdx++ {
+		word, bit := b.locate(h1, h2, hIdx)
+
+		if b.bits[word]&bit == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// add adds the item
+func (b *bloom) add(h1 uint32, h2 uint32) {
+	for hIdx := uint32(0); hIdx < b.hashes; hIdx++ {
+		word, bit := b.locate(h1, h2, hIdx)
+
+		b.bits[word] |= bit
+	}
+}
+
+// reset removes all items
+func (b *bloom) reset() {
+	for bIdx := range b.bits {
+		b.bits[bIdx] = 0
+	}
+}
@@ -23,7 +23,19 @@ package marker
 // Mark Mark data
 type Mark string
 
-// Marker contains marks
+// Marker contains marks, it must be safe for concurrent use
 type Marker interface {
 	Mark(m Mark) error
 }
+
+// none is a Marker that marks nothing
+type none struct{}
+
+// None is a Marker that marks nothing. It's for the codecs that never
+// reuse their keys, so replayed data can't be decrypted anyway
+var None Marker = none{}
+
+// Mark does nothing
+func (n none) Mark(m Mark) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package marker
+
+import (
+	"github.com/reinit/coward/common/metrics"
+)
+
+type metered struct {
+	marker   Marker
+	rejected metrics.Counter
+}
+
+// Metered returns a Marker which counts the marks that has been
+// rejected by the marker
+func Metered(marker Marker, rejected metrics.Counter) Marker {
+	return metered{
+		marker:   marker,
+		rejected: rejected,
+	}
+}
+
+func (m metered) Mark(mark Mark) error {
+	mErr := m.marker.Mark(mark)
+
+	if mErr == ErrAlreadyExisted {
+		m.rejected.Add(1)
+	}
+
+	return mErr
+}
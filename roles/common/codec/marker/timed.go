@@ -21,6 +21,8 @@
 package marker
 
 import (
+	"hash/maphash"
+	"sync"
 	"time"
 
 	"github.com/reinit/coward/roles/common/transceiver"
@@ -32,61 +34,96 @@ var (
 		"Marker already existed")
 )
 
+// Consts
+const (
+	timedShards = 16
+)
+
+type timedShard struct {
+	lock    sync.Mutex
+	period  int64
+	current bloom
+	next    bloom
+}
+
 type timed struct {
-	markers     []map[Mark]struct{}
+	shards      [timedShards]timedShard
+	seed        maphash.Seed
 	timer       func() time.Time
-	nextSwitch  time.Time
+	start       time.Time
 	switchDelay time.Duration
-	index       uint8
-	cap         int
 }
 
-// Timed creates a timed (expirable) Marker
-func Timed(capSize int, d time.Duration, t func() time.Time) Marker {
-	return &timed{
-		markers:     make([]map[Mark]struct{}, 3),
+// Timed creates a Marker which remembers a mark for at least d and at
+// most 2 * d.
+//
+// Marks are spread into shards, each of them keeps two time partitioned
+// bloom filters, so the memory it uses is fixed no matter how many
+// marks has been added. The filters are sized to hold capacity marks
+// within d with the given falsePositive rate. Once the capacity is
+// exceeded, more marks will be treated as existed by mistake
+func Timed(
+	capacity int,
+	falsePositive float64,
+	d time.Duration,
+	t func() time.Time,
+) Marker {
+	tm := &timed{
+		seed:        maphash.MakeSeed(),
 		timer:       t,
-		nextSwitch:  t().Add(d),
+		start:       t(),
 		switchDelay: d,
-		index:       0,
-		cap:         0,
 	}
-}
 
-func (t *timed) nextIndex(currentIndex uint8) uint8 {
-	return (currentIndex + 1) % 3
-}
+	shardCapacity := (capacity + timedShards - 1) / timedShards
 
-func (t *timed) currentIndex() uint8 {
-	if t.timer().After(t.nextSwitch) {
-		t.markers[t.index] = nil
-
-		t.index = t.nextIndex(t.index)
-		t.nextSwitch = time.Now().Add(t.switchDelay)
+	for sIdx := range tm.shards {
+		tm.shards[sIdx].current = newBloom(shardCapacity, falsePositive)
+		tm.shards[sIdx].next = newBloom(shardCapacity, falsePositive)
 	}
 
-	return t.index
+	return tm
 }
 
-func (t *timed) getMap(index uint8) map[Mark]struct{} {
-	if t.markers[index] == nil {
-		t.markers[index] = make(map[Mark]struct{}, t.cap)
+// rotate switches the filters of the shard according to current time.
+// Lock of the shard must be held by the caller
+func (t *timed) rotate(s *timedShard) {
+	period := int64(t.timer().Sub(t.start) / t.switchDelay)
+	elapsed := period - s.period
+
+	switch {
+	case elapsed <= 0:
+		return
+
+	case elapsed == 1:
+		s.current, s.next = s.next, s.current
+
+		s.next.reset()
+
+	default:
+		s.current.reset()
+		s.next.reset()
 	}
 
-	return t.markers[index]
+	s.period = period
 }
 
 func (t *timed) Mark(m Mark) error {
-	cIdx := t.currentIndex()
+	hash := maphash.String(t.seed, string(m))
+	h1, h2 := uint32(hash), uint32(hash>>32)|1
+	s := &t.shards[hash%timedShards]
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
 
-	_, found := t.getMap(cIdx)[m]
+	t.rotate(s)
 
-	if found {
+	if s.current.test(h1, h2) {
 		return ErrAlreadyExisted
 	}
 
-	t.getMap(cIdx)[m] = struct{}{}
-	t.getMap(t.nextIndex(cIdx))[m] = struct{}{}
+	s.current.add(h1, h2)
+	s.next.add(h1, h2)
 
 	return nil
 }
@@ -21,12 +21,16 @@
 package marker
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/reinit/coward/common/metrics"
 )
 
 func TestTimed(t *testing.T) {
-	tmd := Timed(1024, 1*time.Second, time.Now)
+	tmd := Timed(1024, 0.0001, 1*time.Second, time.Now)
 
 	mkErr := tmd.Mark("Test 1")
 
@@ -64,3 +68,114 @@ func TestTimed(t *testing.T) {
 		return
 	}
 }
+
+func TestTimedExpireAll(t *testing.T) {
+	now := time.Now()
+	tmd := Timed(1024, 0.0001, 1*time.Second, func() time.Time {
+		return now
+	})
+
+	for mIdx := 0; mIdx < 64; mIdx++ {
+		mkErr := tmd.Mark(Mark("Test " + strconv.Itoa(mIdx)))
+
+		if mkErr != nil {
+			t.Error("Marking failed:", mkErr)
+
+			return
+		}
+	}
+
+	now = now.Add(10 * time.Second)
+
+	for mIdx := 0; mIdx < 64; mIdx++ {
+		mkErr := tmd.Mark(Mark("Test " + strconv.Itoa(mIdx)))
+
+		if mkErr != nil {
+			t.Error("Marking failed:", mkErr)
+
+			return
+		}
+	}
+}
+
+func TestTimedFalsePositive(t *testing.T) {
+	const capacity = 16384
+
+	tmd := Timed(capacity, 0.001, 1*time.Minute, time.Now)
+	rejected := 0
+
+	for mIdx := 0; mIdx < capacity; mIdx++ {
+		if tmd.Mark(Mark("Test "+strconv.Itoa(mIdx))) == nil {
+			continue
+		}
+
+		rejected++
+	}
+
+	// Expecting 0.1% of false positive, allow some error
+	if rejected > capacity/200 {
+		t.Errorf("Expecting at most %d false positive, got %d",
+			capacity/200, rejected)
+
+		return
+	}
+}
+
+func TestTimedConcurrent(t *testing.T) {
+	tmd := Timed(4096, 0.0001, 1*time.Minute, time.Now)
+	wait := sync.WaitGroup{}
+	failed := make(chan error, 8)
+
+	for gIdx := 0; gIdx < 8; gIdx++ {
+		wait.Add(1)
+
+		go func(gIdx int) {
+			defer wait.Done()
+
+			for mIdx := 0; mIdx < 256; mIdx++ {
+				mkErr := tmd.Mark(Mark(
+					strconv.Itoa(gIdx) + ":" + strconv.Itoa(mIdx)))
+
+				if mkErr == nil {
+					continue
+				}
+
+				failed <- mkErr
+
+				return
+			}
+		}(gIdx)
+	}
+
+	wait.Wait()
+
+	select {
+	case fErr := <-failed:
+		t.Error("Marking failed:", fErr)
+
+	default:
+	}
+}
+
+func TestMetered(t *testing.T) {
+	registry := metrics.New()
+	tmd := Metered(Timed(1024, 0.0001, 1*time.Minute, time.Now),
+		registry.Counter("replays_rejected_total", "Rejected replays"))
+
+	tmd.Mark("Test 1")
+	tmd.Mark("Test 1")
+	tmd.Mark("Test 1")
+	tmd.Mark("Test 2")
+
+	rejected := uint64(0)
+
+	registry.Gather(func(f metrics.Family) {
+		rejected = f.Metrics[0].Value
+	})
+
+	if rejected != 2 {
+		t.Errorf("Expecting 2 rejected marks, got %d", rejected)
+
+		return
+	}
+}
@@ -0,0 +1,138 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"fmt"
+	"strings"
+)
+
+// codecSetting is the options of a Codec
+type codecSetting map[string][]byte
+
+// codecSettingScanner scans the configuration and calls the scan with
+// every line and the option it belongs to.
+//
+// A line starts with a known option name followed by a ":" symbol will
+// switch the current option to that option (begin will be true), and
+// the option name will be removed from the line. Otherwise, the line
+// belongs to the current option. Lines before any option is switched
+// belongs to the defaultOption
+func codecSettingScanner(
+	configuration []string,
+	options []string,
+	defaultOption string,
+	scan func(option string, begin bool, line string),
+) error {
+	currentOption := defaultOption
+	knownOptions := make(map[string]struct{}, len(options))
+
+	for oIdx := range options {
+		knownOptions[options[oIdx]] = struct{}{}
+	}
+
+	for cIdx := range configuration {
+		line := configuration[cIdx]
+		clIdx := strings.Index(line, ":")
+		begin := false
+
+		if clIdx >= 0 {
+			optionName := strings.TrimSpace(line[:clIdx])
+
+			_, optionFound := knownOptions[optionName]
+
+			if optionFound {
+				currentOption = optionName
+				line = strings.TrimLeft(line[clIdx+1:], " \t")
+				begin = true
+			}
+		}
+
+		_, optionFound := knownOptions[currentOption]
+
+		if !optionFound {
+			return fmt.Errorf(
+				"You must define an option before configuring it. "+
+					"Available options: %s", strings.Join(options, ", "))
+		}
+
+		scan(currentOption, begin, line)
+	}
+
+	return nil
+}
+
+// codecSettingParser parses the configuration into options. All lines
+// of an option will be appended together
+func codecSettingParser(
+	configuration []string,
+	options []string,
+	defaultOption string,
+) (codecSetting, error) {
+	setting := make(codecSetting, len(options))
+
+	for oIdx := range options {
+		setting[options[oIdx]] = make([]byte, 0, 64)
+	}
+
+	scanErr := codecSettingScanner(configuration, options, defaultOption,
+		func(option string, begin bool, line string) {
+			setting[option] = append(setting[option], line...)
+		})
+
+	if scanErr != nil {
+		return codecSetting{}, scanErr
+	}
+
+	return setting, nil
+}
+
+// codecSettingEntries parses the configuration and returns every entry
+// of the option. Each time the option is switched to, a new entry will
+// begin, following lines of the option will be appended to it
+func codecSettingEntries(
+	configuration []string,
+	options []string,
+	defaultOption string,
+	option string,
+) ([][]byte, error) {
+	entries := [][]byte{}
+
+	scanErr := codecSettingScanner(configuration, options, defaultOption,
+		func(current string, begin bool, line string) {
+			if current != option {
+				return
+			}
+
+			if begin || len(entries) <= 0 {
+				entries = append(entries, make([]byte, 0, 64))
+			}
+
+			entries[len(entries)-1] = append(
+				entries[len(entries)-1], line...)
+		})
+
+	if scanErr != nil {
+		return nil, scanErr
+	}
+
+	return entries, nil
+}
@@ -21,43 +21,81 @@
 package codec
 
 import (
+	"fmt"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/roles/common/codec/plain"
 	"github.com/reinit/coward/roles/common/transceiver"
 )
 
 var (
-	plainPrefixerOptions = []string{
+	plainOptions = append([]string{
 		"Request-Prefix", "Respond-Prefix",
-	}
+	}, shaperOptions...)
 )
 
-const (
-	plainPrefixerUsageErr = "You must define an option " +
-		"before configuring it. Available options: %s.\r\n\r\nExample:" +
-		"\r\n\r\nRequest-Prefix: 436C69656E74\r\nRespond-Prefix: 536572766572" +
-		"\r\n\r\nNotice:\r\n * One single line can only contain " +
-		"one option\r\n * The value of \"Request-Prefix\" and " +
-		"\"Respond-Prefix\" option must be swapped at the opponent " +
-		"side accordingly"
-)
+// plainSetting Plain Setting
+type plainSetting struct {
+	Prefixer prefixerSetting
+	Shaper   shaperSetting
+}
 
 // Plain return a Plain Transceiver Codec
 func Plain() transceiver.Codec {
 	return transceiver.Codec{
 		Name:   "plain",
-		Usage:  "No option required",
+		Usage:  "No option required." + prefixerUsage + shaperUsage,
 		Build:  plainBuilder,
 		Verify: plainVerifier,
 	}
 }
 
+func plainSettingParser(configuration []string) (plainSetting, error) {
+	setting, settingErr := codecSettingParser(
+		configuration, plainOptions, "")
+
+	if settingErr != nil {
+		return plainSetting{}, settingErr
+	}
+
+	prefixer, prefixerErr := prefixerSettingParser(setting)
+
+	if prefixerErr != nil {
+		return plainSetting{}, prefixerErr
+	}
+
+	shaper, shaperErr := shaperSettingParser(setting)
+
+	if shaperErr != nil {
+		return plainSetting{}, shaperErr
+	}
+
+	return plainSetting{
+		Prefixer: prefixer,
+		Shaper:   shaper,
+	}, nil
+}
+
 func plainVerifier(configuration []string) error {
-	return nil
+	_, settingErr := plainSettingParser(configuration)
+
+	return settingErr
 }
 
-func plainBuilder(configuration []string) transceiver.CodecBuilder {
+func plainBuilder(
+	configuration []string,
+	log logger.Logger,
+	m metrics.Registry,
+) transceiver.CodecBuilder {
+	setting, settingErr := plainSettingParser(configuration)
+
+	if settingErr != nil {
+		panic(fmt.Sprintf("Bad plain setting: %s", settingErr))
+	}
+
 	return func() (rw.Codec, error) {
-		return plain.New()
+		return setting.Prefixer.wrap(setting.Shaper.wrap(plain.New()))
 	}
 }
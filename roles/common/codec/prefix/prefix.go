@@ -0,0 +1,204 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package prefix
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Errors
+var (
+	ErrUnexpectedPrefix = transceiver.NewCodecError(
+		"Data from the remote didn't begin with the expected prefix")
+)
+
+type prefix struct {
+	codec          rw.Codec
+	request        []byte
+	requestWritten bool
+	respond        []byte
+	respondRead    bool
+}
+
+type handshaker struct {
+	*prefix
+
+	handshaker transceiver.CodecHandshaker
+}
+
+// New returns a Codec which writes the request prefix before any data
+// that will be sent to the remote, and verifies the respond prefix
+// before any data received from the remote.
+//
+// The prefixes are not encoded, so the traffic can begin with given
+// bytes. If the codec is a transceiver.CodecHandshaker, the prefixes
+// will be exchanged before the handshake
+func New(codec rw.Codec, request []byte, respond []byte) rw.Codec {
+	if len(request) <= 0 && len(respond) <= 0 {
+		return codec
+	}
+
+	p := &prefix{
+		codec:          codec,
+		request:        request,
+		requestWritten: len(request) <= 0,
+		respond:        respond,
+		respondRead:    len(respond) <= 0,
+	}
+
+	h, isHandshaker := codec.(transceiver.CodecHandshaker)
+
+	if !isHandshaker {
+		return p
+	}
+
+	return handshaker{
+		prefix:     p,
+		handshaker: h,
+	}
+}
+
+func (p *prefix) writePrefix(w io.Writer) error {
+	if p.requestWritten {
+		return nil
+	}
+
+	_, wErr := rw.WriteFull(w, p.request)
+
+	if wErr != nil {
+		return wErr
+	}
+
+	p.requestWritten = true
+
+	return nil
+}
+
+func (p *prefix) readPrefix(r io.Reader) error {
+	if p.respondRead {
+		return nil
+	}
+
+	buf := make([]byte, len(p.respond))
+
+	_, rErr := io.ReadFull(r, buf)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	if !bytes.Equal(buf, p.respond) {
+		return ErrUnexpectedPrefix
+	}
+
+	p.respondRead = true
+
+	return nil
+}
+
+func (p *prefix) Encode(w io.Writer) rw.WriteWriteAll {
+	if p.requestWritten {
+		return p.codec.Encode(w)
+	}
+
+	return encoder{p: p, w: w}
+}
+
+func (p *prefix) Decode(r io.Reader) io.Reader {
+	if p.respondRead {
+		return p.codec.Decode(r)
+	}
+
+	return decoder{p: p, r: r}
+}
+
+func (h handshaker) Handshake(conn io.ReadWriter, initiator bool) error {
+	return h.handshaker.Handshake(prefixedConn{
+		p:    h.prefix,
+		conn: conn,
+	}, initiator)
+}
+
+// encoder writes the prefix before the first encoded data
+type encoder struct {
+	p *prefix
+	w io.Writer
+}
+
+func (e encoder) Write(b []byte) (int, error) {
+	return e.WriteAll(b)
+}
+
+func (e encoder) WriteAll(b ...[]byte) (int, error) {
+	wErr := e.p.writePrefix(e.w)
+
+	if wErr != nil {
+		return 0, wErr
+	}
+
+	return e.p.codec.Encode(e.w).WriteAll(b...)
+}
+
+// decoder verifies the prefix before decoding the first data
+type decoder struct {
+	p *prefix
+	r io.Reader
+}
+
+func (d decoder) Read(b []byte) (int, error) {
+	rErr := d.p.readPrefix(d.r)
+
+	if rErr != nil {
+		return 0, rErr
+	}
+
+	return d.p.codec.Decode(d.r).Read(b)
+}
+
+// prefixedConn exchanges prefixes during the handshake
+type prefixedConn struct {
+	p    *prefix
+	conn io.ReadWriter
+}
+
+func (p prefixedConn) Read(b []byte) (int, error) {
+	rErr := p.p.readPrefix(p.conn)
+
+	if rErr != nil {
+		return 0, rErr
+	}
+
+	return p.conn.Read(b)
+}
+
+func (p prefixedConn) Write(b []byte) (int, error) {
+	wErr := p.p.writePrefix(p.conn)
+
+	if wErr != nil {
+		return 0, wErr
+	}
+
+	return p.conn.Write(b)
+}
@@ -0,0 +1,164 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package prefix
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/plain"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+type dummyHandshaker struct {
+	rw.Codec
+}
+
+func (d dummyHandshaker) Handshake(conn io.ReadWriter, initiator bool) error {
+	if initiator {
+		_, wErr := conn.Write([]byte("HS"))
+
+		return wErr
+	}
+
+	buf := [2]byte{}
+
+	_, rErr := io.ReadFull(conn, buf[:])
+
+	return rErr
+}
+
+func testPlain(t *testing.T) rw.Codec {
+	p, pErr := plain.New()
+
+	if pErr != nil {
+		t.Fatal("Failed to build plain codec due to error:", pErr)
+	}
+
+	return p
+}
+
+func TestPrefix(t *testing.T) {
+	client := New(testPlain(t), []byte("GET "), []byte("HTTP"))
+	server := New(testPlain(t), []byte("HTTP"), []byte("GET "))
+	buf := bytes.NewBuffer(nil)
+
+	client.Encode(buf).Write([]byte("Hello"))
+	client.Encode(buf).Write([]byte("World"))
+
+	if !bytes.Equal(buf.Bytes(), []byte("GET HelloWorld")) {
+		t.Errorf("Expecting \"GET HelloWorld\", got %q", buf.Bytes())
+
+		return
+	}
+
+	result := make([]byte, 10)
+
+	_, rErr := io.ReadFull(server.Decode(buf), result)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(result, []byte("HelloWorld")) {
+		t.Errorf("Expecting \"HelloWorld\", got %q", result)
+
+		return
+	}
+
+	server.Encode(buf).Write([]byte("Hi"))
+
+	_, rErr = io.ReadFull(client.Decode(buf), result[:2])
+
+	if rErr != nil || !bytes.Equal(result[:2], []byte("Hi")) {
+		t.Errorf("Expecting \"Hi\", got %q: %s", result[:2], rErr)
+
+		return
+	}
+
+	rLen, rErr := client.Decode(bytes.NewBuffer([]byte("HTTP"))).Read(result)
+
+	if rErr != nil || !bytes.Equal(result[:rLen], []byte("HTTP")) {
+		t.Errorf("Prefix must only be verified once, got %q: %s",
+			result[:rLen], rErr)
+
+		return
+	}
+}
+
+func TestPrefixMismatch(t *testing.T) {
+	server := New(testPlain(t), []byte("HTTP"), []byte("GET "))
+
+	_, rErr := server.Decode(bytes.NewBuffer(
+		[]byte("PUT Hello"))).Read(make([]byte, 5))
+
+	if rErr != ErrUnexpectedPrefix {
+		t.Errorf("Expecting error %s, got %s", ErrUnexpectedPrefix, rErr)
+
+		return
+	}
+}
+
+func TestPrefixHandshake(t *testing.T) {
+	client := New(dummyHandshaker{Codec: testPlain(t)},
+		[]byte("GET "), []byte("HTTP"))
+	server := New(dummyHandshaker{Codec: testPlain(t)},
+		[]byte("HTTP"), []byte("GET "))
+	conn := bytes.NewBuffer(nil)
+
+	hErr := client.(transceiver.CodecHandshaker).Handshake(conn, true)
+
+	if hErr != nil {
+		t.Error("Failed to handshake due to error:", hErr)
+
+		return
+	}
+
+	client.Encode(conn).Write([]byte("Hello"))
+
+	if !bytes.Equal(conn.Bytes(), []byte("GET HSHello")) {
+		t.Errorf("Expecting \"GET HSHello\", got %q", conn.Bytes())
+
+		return
+	}
+
+	hErr = server.(transceiver.CodecHandshaker).Handshake(conn, false)
+
+	if hErr != nil {
+		t.Error("Failed to handshake due to error:", hErr)
+
+		return
+	}
+
+	result := make([]byte, 5)
+
+	_, rErr := io.ReadFull(server.Decode(conn), result)
+
+	if rErr != nil || !bytes.Equal(result, []byte("Hello")) {
+		t.Errorf("Expecting \"Hello\", got %q: %s", result, rErr)
+
+		return
+	}
+}
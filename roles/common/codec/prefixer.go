@@ -24,109 +24,65 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/prefix"
 )
 
-// prefixerSetting Prefixer Setting
-type prefixerSetting map[string][]byte
+// Consts
+const (
+	prefixerUsage = "\r\n\r\nOptionally, hex encoded bytes defined " +
+		"by \"Request-Prefix\" option will be sent before any other data, " +
+		"and bytes defined by \"Respond-Prefix\" option must be received " +
+		"before any other data. Example:\r\n\r\n" +
+		"Request-Prefix: 436C69656E74\r\nRespond-Prefix: 536572766572" +
+		"\r\n\r\nNotice: The value of \"Request-Prefix\" and " +
+		"\"Respond-Prefix\" option must be swapped at the opponent " +
+		"side accordingly"
+)
 
-// prefixerSettingParser parse prefixerSetting
-func prefixerSettingParser(
-	configuration []string,
-	prefixerOptions []string,
-	usageErr error,
-) (prefixerSetting, error) {
-	currentSettingContext := ""
-	currentSetting := make(prefixerSetting, len(prefixerOptions))
+// prefixerSetting Prefixer Setting
+type prefixerSetting struct {
+	Request []byte
+	Respond []byte
+}
 
-	for pIdx := range prefixerOptions {
-		currentSetting[prefixerOptions[pIdx]] = make([]byte, 0, 256)
+// prefixerSettingParser parse prefixerSetting from Codec options
+func prefixerSettingParser(setting codecSetting) (prefixerSetting, error) {
+	result := prefixerSetting{
+		Request: nil,
+		Respond: nil,
 	}
 
-	for sIdx := range configuration {
-		clIdx := strings.Index(configuration[sIdx], ":")
-
-		// Check whether or not to switch setting context
-		if clIdx >= 0 {
-			currentSettingContext =
-				strings.TrimSpace(configuration[sIdx][:clIdx])
-
-			_, scFound := currentSetting[currentSettingContext]
+	for _, option := range []struct {
+		Name   string
+		Result *[]byte
+	}{
+		{"Request-Prefix", &result.Request},
+		{"Respond-Prefix", &result.Respond},
+	} {
+		value := strings.TrimSpace(string(setting[option.Name]))
 
-			if !scFound {
-				return prefixerSetting{}, fmt.Errorf(
-					"Unknown Option \"%s\": %s",
-					currentSettingContext, usageErr)
-			}
-
-			clIdx++ // Skip ":" symbol
-		} else {
-			clIdx = 0
-		}
-
-		sc, scFound := currentSetting[currentSettingContext]
-
-		if !scFound {
-			return prefixerSetting{}, usageErr
-		}
-
-		sc = append(sc, configuration[sIdx][clIdx:]...)
-
-		currentSetting[currentSettingContext] = sc
-	}
-
-	for sKey := range currentSetting {
-		hexData, hexDataErr := hex.DecodeString(
-			string(currentSetting[sKey]))
+		hexData, hexDataErr := hex.DecodeString(value)
 
 		if hexDataErr != nil {
 			return prefixerSetting{}, fmt.Errorf(
 				"Invalid value \"%s\" of \"%s\" option: %s. "+
 					"It must be a valid string of hex",
-				string(currentSetting[sKey]), sKey, hexDataErr)
+				value, option.Name, hexDataErr)
 		}
 
-		currentSetting[sKey] = hexData
-	}
-
-	return currentSetting, nil
-}
-
-// prefixerSettingBuilder build prefixerSetting
-func prefixerSettingBuilder(
-	configuration []string, prefixerOptions []string) prefixerSetting {
-	prefixer, prefixerErr := prefixerSettingParser(
-		configuration, prefixerOptions, nil)
-
-	if prefixerErr != nil {
-		panic(fmt.Sprintf("Bad prefixer setting: %s", prefixerErr))
+		*option.Result = hexData
 	}
 
-	return prefixer
+	return result, nil
 }
 
-// prefixerSettingVerifier verify prefixerSetting
-func prefixerSettingVerifier(
-	configuration []string,
-	v func(p prefixerSetting) error,
-	prefixerOptions []string,
-	usageErr error,
-) error {
-	prefixer, prefixerErr := prefixerSettingParser(
-		configuration, prefixerOptions, usageErr)
-
-	if prefixerErr != nil {
-		return prefixerErr
-	}
-
-	if v == nil {
-		return nil
-	}
-
-	vErr := v(prefixer)
-
-	if vErr != nil {
-		return vErr
+// wrap wraps the Codec so it will write and verify the prefixes
+func (p prefixerSetting) wrap(c rw.Codec, cErr error) (rw.Codec, error) {
+	if cErr != nil {
+		return nil, cErr
 	}
 
-	return nil
+	return prefix.New(c, p.Request, p.Respond), nil
 }
@@ -0,0 +1,116 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/roles/common/codec/marker"
+)
+
+// Consts
+const (
+	replayDefaultCapacity      = 65536
+	replayMaxCapacity          = 16777216
+	replayDefaultFalsePositive = 0.000001
+	replayMaxFalsePositive     = 0.1
+
+	replayUsage = "\r\n\r\nReceived data will be rejected as a replay " +
+		"when it's been seen recently. \"Replay-Capacity\" option sets " +
+		"how many connections (default: 65536) are expected within the " +
+		"retention period, and \"Replay-False-Positive\" option sets the " +
+		"chance (default: 0.000001) for a new connection to be rejected " +
+		"by mistake. Larger capacity and smaller chance uses more memory"
+)
+
+// Vars
+var (
+	replayOptions = []string{
+		"Replay-Capacity", "Replay-False-Positive",
+	}
+)
+
+// replaySetting Replay protection Setting
+type replaySetting struct {
+	Capacity      int
+	FalsePositive float64
+}
+
+// replaySettingParser parse replaySetting from Codec options
+func replaySettingParser(setting codecSetting) (replaySetting, error) {
+	result := replaySetting{
+		Capacity:      replayDefaultCapacity,
+		FalsePositive: replayDefaultFalsePositive,
+	}
+
+	capacity := strings.TrimSpace(string(setting["Replay-Capacity"]))
+
+	if len(capacity) > 0 {
+		capacityValue, capacityErr := strconv.ParseUint(capacity, 10, 32)
+
+		if capacityErr != nil || capacityValue <= 0 ||
+			capacityValue > replayMaxCapacity {
+			return replaySetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Replay-Capacity\" option. It "+
+					"must be a number between 1 and %d",
+				capacity, replayMaxCapacity)
+		}
+
+		result.Capacity = int(capacityValue)
+	}
+
+	falsePositive := strings.TrimSpace(
+		string(setting["Replay-False-Positive"]))
+
+	if len(falsePositive) > 0 {
+		fpValue, fpErr := strconv.ParseFloat(falsePositive, 64)
+
+		if fpErr != nil || fpValue <= 0 || fpValue > replayMaxFalsePositive {
+			return replaySetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Replay-False-Positive\" "+
+					"option. It must be a number larger than 0 and not "+
+					"larger than %g", falsePositive, replayMaxFalsePositive)
+		}
+
+		result.FalsePositive = fpValue
+	}
+
+	return result, nil
+}
+
+// marker returns a replay marker which keeps marks for at least the
+// retention. Rejected replays will be counted into the metrics
+func (r replaySetting) marker(
+	retention time.Duration, m metrics.Registry) marker.Marker {
+	mark := marker.Timed(r.Capacity, r.FalsePositive, retention, time.Now)
+
+	if m == nil {
+		return mark
+	}
+
+	return marker.Metered(mark, m.Counter(
+		"coward_codec_replays_rejected_total",
+		"Amount of received data that was rejected as a replay"))
+}
@@ -0,0 +1,101 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/metrics"
+)
+
+func TestReplaySettingParser(t *testing.T) {
+	tests := []struct {
+		Setting       codecSetting
+		Capacity      int
+		FalsePositive float64
+		Failure       bool
+	}{
+		{codecSetting{}, 65536, 0.000001, false},
+		{codecSetting{"Replay-Capacity": []byte("1000000")},
+			1000000, 0.000001, false},
+		{codecSetting{"Replay-False-Positive": []byte("0.001")},
+			65536, 0.001, false},
+		{codecSetting{"Replay-Capacity": []byte("0")}, 0, 0, true},
+		{codecSetting{"Replay-Capacity": []byte("99999999")}, 0, 0, true},
+		{codecSetting{"Replay-False-Positive": []byte("0")}, 0, 0, true},
+		{codecSetting{"Replay-False-Positive": []byte("0.5")}, 0, 0, true},
+	}
+
+	for tIdx, test := range tests {
+		result, resultErr := replaySettingParser(test.Setting)
+
+		if test.Failure {
+			if resultErr == nil {
+				t.Errorf("Test %d: Expecting failure", tIdx)
+
+				return
+			}
+
+			continue
+		}
+
+		if resultErr != nil {
+			t.Errorf("Test %d: Unexpected error: %s", tIdx, resultErr)
+
+			return
+		}
+
+		if result.Capacity != test.Capacity ||
+			result.FalsePositive != test.FalsePositive {
+			t.Errorf("Test %d: Unexpected result: %+v", tIdx, result)
+
+			return
+		}
+	}
+}
+
+func TestReplaySettingMarker(t *testing.T) {
+	registry := metrics.New()
+	mark := replaySetting{
+		Capacity:      1024,
+		FalsePositive: 0.0001,
+	}.marker(time.Minute, registry)
+
+	mark.Mark("Test")
+	mark.Mark("Test")
+
+	rejected := uint64(0)
+
+	registry.Gather(func(f metrics.Family) {
+		if f.Name != "coward_codec_replays_rejected_total" {
+			return
+		}
+
+		rejected = f.Metrics[0].Value
+	})
+
+	if rejected != 1 {
+		t.Errorf("Expecting 1 rejected replay, got %d", rejected)
+
+		return
+	}
+}
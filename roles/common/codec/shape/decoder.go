@@ -0,0 +1,91 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package shape
+
+import (
+	"io"
+)
+
+type decoder struct {
+	s *shape
+	r io.Reader
+}
+
+func (d decoder) skipPadding(r io.Reader) error {
+	for d.s.readPadding > 0 {
+		if len(d.s.readPadBuffer) <= 0 {
+			d.s.readPadBuffer = make([]byte, MaxFrameSize)
+		}
+
+		skipLen := d.s.readPadding
+
+		if skipLen > len(d.s.readPadBuffer) {
+			skipLen = len(d.s.readPadBuffer)
+		}
+
+		rLen, rErr := r.Read(d.s.readPadBuffer[:skipLen])
+
+		d.s.readPadding -= rLen
+
+		if rErr != nil {
+			return rErr
+		}
+	}
+
+	return nil
+}
+
+func (d decoder) Read(b []byte) (int, error) {
+	r := d.s.codec.Decode(d.r)
+
+	for d.s.readRemain <= 0 {
+		sErr := d.skipPadding(r)
+
+		if sErr != nil {
+			return 0, sErr
+		}
+
+		_, rErr := io.ReadFull(r, d.s.readHeader[:])
+
+		if rErr != nil {
+			return 0, rErr
+		}
+
+		d.s.readRemain = int(d.s.readHeader[0])<<8 | int(d.s.readHeader[1])
+		d.s.readPadding = int(d.s.readHeader[2])<<8 | int(d.s.readHeader[3])
+
+		if d.s.readRemain+d.s.readPadding > MaxFrameSize {
+			return 0, ErrInvalidFrame
+		}
+	}
+
+	readLen := len(b)
+
+	if readLen > d.s.readRemain {
+		readLen = d.s.readRemain
+	}
+
+	rLen, rErr := r.Read(b[:readLen])
+
+	d.s.readRemain -= rLen
+
+	return rLen, rErr
+}
@@ -0,0 +1,94 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package shape
+
+import (
+	"io"
+	"time"
+)
+
+type encoder struct {
+	s *shape
+	w io.Writer
+}
+
+func (e encoder) Write(b []byte) (int, error) {
+	return e.WriteAll(b)
+}
+
+func (e encoder) WriteAll(b ...[]byte) (int, error) {
+	if e.s.cfg.Jitter > 0 {
+		time.Sleep(e.s.random(0, e.s.cfg.Jitter))
+	}
+
+	e.s.writeLock.Lock()
+	defer e.s.writeLock.Unlock()
+
+	e.s.writer = e.w
+
+	remain := 0
+
+	for bIdx := range b {
+		remain += len(b[bIdx])
+	}
+
+	// Pack data of all slices into frames, so small slices such as
+	// headers won't be sent in their own frames
+	totalWritten := 0
+	bIdx, bStart := 0, 0
+
+	for remain > 0 {
+		payload, padding := e.s.size(remain)
+
+		for filled := 0; filled < payload; {
+			copied := copy(
+				e.s.frameBuf[FrameHeaderSize+filled:FrameHeaderSize+payload],
+				b[bIdx][bStart:])
+
+			filled += copied
+			bStart += copied
+
+			if bStart >= len(b[bIdx]) {
+				bIdx++
+				bStart = 0
+			}
+		}
+
+		wErr := e.s.writeFrame(payload, padding)
+
+		if wErr != nil {
+			return totalWritten, wErr
+		}
+
+		remain -= payload
+		totalWritten += payload
+	}
+
+	e.s.lastWrite = time.Now()
+
+	if e.s.cfg.CoverMax > 0 && !e.s.covering {
+		e.s.covering = true
+
+		go e.s.cover()
+	}
+
+	return totalWritten, nil
+}
@@ -0,0 +1,212 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package shape
+
+import (
+	"crypto/rand"
+	"io"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Errors
+var (
+	ErrInvalidFrame = transceiver.NewCodecError(
+		"Shaped frame was invalid")
+)
+
+// Config is the configuration of the shaper
+type Config struct {
+	// Sizer decides the size of the frames, when nil, frames will not
+	// be padded
+	Sizer Sizer
+
+	// CoverMin and CoverMax defines the interval of sending cover frames
+	// when there is no data to send. Cover frames is disabled when
+	// CoverMax is 0
+	CoverMin time.Duration
+	CoverMax time.Duration
+
+	// CoverLimit stops sending cover frames when there is no data has
+	// been sent for that long
+	CoverLimit time.Duration
+
+	// Jitter is the maximal random delay before each write
+	Jitter time.Duration
+}
+
+type shape struct {
+	codec         rw.Codec
+	cfg           Config
+	writer        io.Writer
+	writeLock     sync.Mutex
+	lastWrite     time.Time
+	covering      bool
+	failed        bool
+	frameBuf      []byte
+	readHeader    [FrameHeaderSize]byte
+	readRemain    int
+	readPadding   int
+	readPadBuffer []byte
+}
+
+type handshaker struct {
+	*shape
+
+	handshaker transceiver.CodecHandshaker
+}
+
+// New returns a Codec which shapes the data into frames of the size
+// decided by the Sizer before encode them with the codec. If the codec
+// is a transceiver.CodecHandshaker, the handshake will be forwarded to
+// it and the handshake data will not be shaped
+func New(codec rw.Codec, cfg Config) rw.Codec {
+	s := &shape{
+		codec:         codec,
+		cfg:           cfg,
+		writer:        nil,
+		writeLock:     sync.Mutex{},
+		lastWrite:     time.Time{},
+		covering:      false,
+		failed:        false,
+		frameBuf:      make([]byte, MaxFrameSize+FrameHeaderSize),
+		readHeader:    [FrameHeaderSize]byte{},
+		readRemain:    0,
+		readPadding:   0,
+		readPadBuffer: nil,
+	}
+
+	h, isHandshaker := codec.(transceiver.CodecHandshaker)
+
+	if !isHandshaker {
+		return s
+	}
+
+	return handshaker{
+		shape:      s,
+		handshaker: h,
+	}
+}
+
+// Handshake performs the handshake of the shaped codec
+func (h handshaker) Handshake(conn io.ReadWriter, initiator bool) error {
+	return h.handshaker.Handshake(conn, initiator)
+}
+
+func (s *shape) random(min time.Duration, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+
+	return min + time.Duration(mrand.Int63n(int64(max-min)+1))
+}
+
+func (s *shape) size(remain int) (int, int) {
+	if s.cfg.Sizer == nil {
+		if remain > MaxFrameSize {
+			return MaxFrameSize, 0
+		}
+
+		return remain, 0
+	}
+
+	return s.cfg.Sizer.Size(remain)
+}
+
+// writeFrame writes a frame whose payload has already been copied into
+// the frameBuf. writeLock must be held by the caller
+func (s *shape) writeFrame(payload int, padding int) error {
+	frame := s.frameBuf[:FrameHeaderSize+payload+padding]
+
+	frame[0] = byte(payload >> 8)
+	frame[1] = byte(payload)
+	frame[2] = byte(padding >> 8)
+	frame[3] = byte(padding)
+
+	if padding > 0 {
+		_, rErr := rand.Read(frame[FrameHeaderSize+payload:])
+
+		if rErr != nil {
+			return transceiver.WrapCodecError(rErr)
+		}
+	}
+
+	_, wErr := s.codec.Encode(s.writer).Write(frame)
+
+	if wErr != nil {
+		s.failed = true
+	}
+
+	return wErr
+}
+
+// cover sends cover frames while there is no data to send
+func (s *shape) cover() {
+	for {
+		time.Sleep(s.random(s.cfg.CoverMin, s.cfg.CoverMax))
+
+		s.writeLock.Lock()
+
+		idle := time.Since(s.lastWrite)
+
+		if s.failed || idle >= s.cfg.CoverLimit {
+			s.covering = false
+
+			s.writeLock.Unlock()
+
+			return
+		}
+
+		if idle < s.cfg.CoverMin {
+			s.writeLock.Unlock()
+
+			continue
+		}
+
+		_, padding := s.size(0)
+
+		wErr := s.writeFrame(0, padding)
+
+		s.writeLock.Unlock()
+
+		if wErr == nil {
+			continue
+		}
+
+		s.writeLock.Lock()
+		s.covering = false
+		s.writeLock.Unlock()
+
+		return
+	}
+}
+
+func (s *shape) Encode(w io.Writer) rw.WriteWriteAll {
+	return encoder{s: s, w: w}
+}
+
+func (s *shape) Decode(r io.Reader) io.Reader {
+	return decoder{s: s, r: r}
+}
@@ -0,0 +1,206 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package shape
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/plain"
+)
+
+type dummyBuffer struct {
+	buf    bytes.Buffer
+	writes []int
+	lock   sync.Mutex
+}
+
+func (d *dummyBuffer) Write(b []byte) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.writes = append(d.writes, len(b))
+
+	return d.buf.Write(b)
+}
+
+func (d *dummyBuffer) Read(b []byte) (int, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.buf.Read(b)
+}
+
+func testShape(t *testing.T, cfg Config) rw.Codec {
+	p, pErr := plain.New()
+
+	if pErr != nil {
+		t.Fatal("Failed to build plain codec due to error:", pErr)
+	}
+
+	return New(p, cfg)
+}
+
+func TestShapeBuckets(t *testing.T) {
+	cfg := Config{
+		Sizer:      Buckets([]int{1024, 64, 256}),
+		CoverMin:   0,
+		CoverMax:   0,
+		CoverLimit: 0,
+		Jitter:     0,
+	}
+	client := testShape(t, cfg)
+	server := testShape(t, cfg)
+	buf := &dummyBuffer{}
+	data := make([]byte, 1500)
+
+	for dIdx := range data {
+		data[dIdx] = byte(dIdx)
+	}
+
+	client.Encode(buf).WriteAll(data[:10], data[10:])
+
+	expectedWrites := []int{1024, 1024}
+
+	if len(buf.writes) != len(expectedWrites) {
+		t.Errorf("Expecting %d writes, got %d",
+			len(expectedWrites), len(buf.writes))
+
+		return
+	}
+
+	for wIdx := range expectedWrites {
+		if buf.writes[wIdx] != expectedWrites[wIdx] {
+			t.Errorf("Expecting write %d to be %d bytes, got %d",
+				wIdx, expectedWrites[wIdx], buf.writes[wIdx])
+
+			return
+		}
+	}
+
+	client.Encode(buf).Write(data[:50])
+
+	if buf.writes[len(buf.writes)-1] != 64 {
+		t.Errorf("Expecting a 64 bytes write, got %d",
+			buf.writes[len(buf.writes)-1])
+
+		return
+	}
+
+	result := make([]byte, 1550)
+
+	_, rErr := io.ReadFull(server.Decode(buf), result)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(result[:1500], data) ||
+		!bytes.Equal(result[1500:], data[:50]) {
+		t.Error("Failed to read the expected data")
+
+		return
+	}
+}
+
+func TestShapeRange(t *testing.T) {
+	cfg := Config{
+		Sizer:      Range(100, 200),
+		CoverMin:   0,
+		CoverMax:   0,
+		CoverLimit: 0,
+		Jitter:     1 * time.Millisecond,
+	}
+	client := testShape(t, cfg)
+	server := testShape(t, cfg)
+	buf := &dummyBuffer{}
+	data := []byte("Hello World")
+
+	for i := 0; i < 100; i++ {
+		client.Encode(buf).Write(data)
+	}
+
+	for wIdx := range buf.writes {
+		if buf.writes[wIdx] < 100 || buf.writes[wIdx] > 200 {
+			t.Errorf("Expecting write size between 100 and 200, got %d",
+				buf.writes[wIdx])
+
+			return
+		}
+	}
+
+	result := make([]byte, len(data))
+
+	for i := 0; i < 100; i++ {
+		_, rErr := io.ReadFull(server.Decode(buf), result)
+
+		if rErr != nil || !bytes.Equal(result, data) {
+			t.Errorf("Expecting %q, got %q: %s", data, result, rErr)
+
+			return
+		}
+	}
+}
+
+func TestShapeCover(t *testing.T) {
+	cfg := Config{
+		Sizer:      Buckets([]int{128}),
+		CoverMin:   5 * time.Millisecond,
+		CoverMax:   10 * time.Millisecond,
+		CoverLimit: 100 * time.Millisecond,
+		Jitter:     0,
+	}
+	client := testShape(t, cfg)
+	server := testShape(t, cfg)
+	buf := &dummyBuffer{}
+
+	client.Encode(buf).Write([]byte("Hello"))
+
+	time.Sleep(300 * time.Millisecond)
+
+	client.Encode(buf).Write([]byte("World"))
+
+	buf.lock.Lock()
+	writes := len(buf.writes)
+	buf.lock.Unlock()
+
+	if writes <= 2 {
+		t.Errorf("Expecting cover frames to be sent, got %d writes", writes)
+
+		return
+	}
+
+	result := make([]byte, 10)
+
+	_, rErr := io.ReadFull(server.Decode(buf), result)
+
+	if rErr != nil || !bytes.Equal(result, []byte("HelloWorld")) {
+		t.Errorf("Expecting \"HelloWorld\", got %q: %s", result, rErr)
+
+		return
+	}
+}
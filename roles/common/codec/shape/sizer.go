@@ -0,0 +1,105 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package shape
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Consts
+const (
+	// FrameHeaderSize is the size of the header of each frame
+	FrameHeaderSize = 4
+
+	// MinFrameSize is the minimal size of a frame
+	MinFrameSize = 16
+
+	// MaxFrameSize is the maximal size of a frame
+	MaxFrameSize = 4096
+)
+
+// Sizer decides the size of frames
+type Sizer interface {
+	// Size returns the size of the payload and the padding of a frame
+	// which will carry remain bytes of data. When remain is 0, the
+	// frame is a cover frame
+	Size(remain int) (payload int, padding int)
+}
+
+// buckets picks the smallest bucket which can carry all the remaining
+// data, or the largest bucket when the data is too large
+type buckets struct {
+	sizes []int
+}
+
+// ranged picks a random size between min and max
+type ranged struct {
+	min int
+	max int
+}
+
+// Buckets returns a Sizer which shapes frames into fixed sizes
+func Buckets(sizes []int) Sizer {
+	sorted := make([]int, len(sizes))
+
+	copy(sorted, sizes)
+
+	sort.Ints(sorted)
+
+	return buckets{
+		sizes: sorted,
+	}
+}
+
+// Range returns a Sizer which shapes frames into random sizes
+func Range(min int, max int) Sizer {
+	return ranged{
+		min: min,
+		max: max,
+	}
+}
+
+func (b buckets) Size(remain int) (int, int) {
+	for sIdx := range b.sizes {
+		if b.sizes[sIdx]-FrameHeaderSize < remain {
+			continue
+		}
+
+		return remain, b.sizes[sIdx] - FrameHeaderSize - remain
+	}
+
+	return b.sizes[len(b.sizes)-1] - FrameHeaderSize, 0
+}
+
+func (r ranged) Size(remain int) (int, int) {
+	size := r.min - FrameHeaderSize
+
+	if r.max > r.min {
+		size += rand.Intn(r.max - r.min + 1)
+	}
+
+	if remain >= size {
+		return size, 0
+	}
+
+	return remain, size - remain
+}
@@ -0,0 +1,226 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/shape"
+)
+
+// Consts
+const (
+	shaperDefaultCoverLimit = 30 * time.Second
+	shaperMaxJitter         = 1 * time.Second
+
+	shaperUsage = "\r\n\r\nOptionally, data can be shaped into frames " +
+		"to hide the size of the original writes. \"Shape-Sizes\" option " +
+		"defines either a list of fixed frame sizes (for example, " +
+		"\"576, 1200, 1400\") or a random range (for example, " +
+		"\"200-1400\"). \"Shape-Cover\" option enables cover frames that " +
+		"will be sent in given interval (for example, \"1s-5s\") when " +
+		"there is nothing to send, for at most \"Shape-Cover-Limit\" " +
+		"(default: 30s) since the last write. \"Shape-Jitter\" option " +
+		"sets the maximal random delay (for example, \"20ms\") before " +
+		"each write. These options must be the same on both side"
+)
+
+// Vars
+var (
+	shaperOptions = []string{
+		"Shape-Sizes", "Shape-Cover", "Shape-Cover-Limit", "Shape-Jitter",
+	}
+)
+
+// shaperSetting Shaper Setting
+type shaperSetting struct {
+	Enabled bool
+	Config  shape.Config
+}
+
+// shaperDurationRange parses "<Duration>" or "<Duration>-<Duration>"
+func shaperDurationRange(
+	name string, value string) (time.Duration, time.Duration, error) {
+	values := strings.SplitN(value, "-", 2)
+	durations := [2]time.Duration{}
+
+	for vIdx := range values {
+		d, dErr := time.ParseDuration(strings.TrimSpace(values[vIdx]))
+
+		if dErr != nil || d <= 0 {
+			return 0, 0, fmt.Errorf(
+				"Invalid value \"%s\" of \"%s\" option. It must be a "+
+					"duration or a range of durations", value, name)
+		}
+
+		durations[vIdx] = d
+	}
+
+	if len(values) == 1 {
+		durations[1] = durations[0]
+	}
+
+	if durations[0] > durations[1] {
+		return 0, 0, fmt.Errorf(
+			"Invalid value \"%s\" of \"%s\" option. The minimal duration "+
+				"must not be larger than the maximal one", value, name)
+	}
+
+	return durations[0], durations[1], nil
+}
+
+// shaperSizes parses "<Size>, <Size> ..." or "<Size>-<Size>"
+func shaperSizes(value string) (shape.Sizer, error) {
+	var values []string
+
+	isRange := strings.Contains(value, "-")
+
+	if isRange {
+		values = strings.SplitN(value, "-", 2)
+	} else {
+		values = strings.Split(value, ",")
+	}
+
+	sizes := make([]int, len(values))
+
+	for vIdx := range values {
+		size, sizeErr := strconv.ParseUint(
+			strings.TrimSpace(values[vIdx]), 10, 16)
+
+		if sizeErr != nil || size < shape.MinFrameSize ||
+			size > shape.MaxFrameSize {
+			return nil, fmt.Errorf(
+				"Invalid value \"%s\" of \"Shape-Sizes\" option. Sizes "+
+					"must be numbers between %d and %d", value,
+				shape.MinFrameSize, shape.MaxFrameSize)
+		}
+
+		sizes[vIdx] = int(size)
+	}
+
+	if !isRange {
+		return shape.Buckets(sizes), nil
+	}
+
+	if sizes[0] > sizes[1] {
+		return nil, fmt.Errorf(
+			"Invalid value \"%s\" of \"Shape-Sizes\" option. The minimal "+
+				"size must not be larger than the maximal one", value)
+	}
+
+	return shape.Range(sizes[0], sizes[1]), nil
+}
+
+// shaperSettingParser parse shaperSetting from Codec options
+func shaperSettingParser(setting codecSetting) (shaperSetting, error) {
+	result := shaperSetting{
+		Enabled: false,
+		Config: shape.Config{
+			Sizer:      nil,
+			CoverMin:   0,
+			CoverMax:   0,
+			CoverLimit: shaperDefaultCoverLimit,
+			Jitter:     0,
+		},
+	}
+
+	sizes := strings.TrimSpace(string(setting["Shape-Sizes"]))
+
+	if len(sizes) > 0 {
+		var sizerErr error
+
+		result.Config.Sizer, sizerErr = shaperSizes(sizes)
+
+		if sizerErr != nil {
+			return shaperSetting{}, sizerErr
+		}
+
+		result.Enabled = true
+	}
+
+	cover := strings.TrimSpace(string(setting["Shape-Cover"]))
+
+	if len(cover) > 0 {
+		var coverErr error
+
+		result.Config.CoverMin, result.Config.CoverMax, coverErr =
+			shaperDurationRange("Shape-Cover", cover)
+
+		if coverErr != nil {
+			return shaperSetting{}, coverErr
+		}
+
+		result.Enabled = true
+	}
+
+	coverLimit := strings.TrimSpace(string(setting["Shape-Cover-Limit"]))
+
+	if len(coverLimit) > 0 {
+		var coverLimitErr error
+
+		_, result.Config.CoverLimit, coverLimitErr = shaperDurationRange(
+			"Shape-Cover-Limit", coverLimit)
+
+		if coverLimitErr != nil {
+			return shaperSetting{}, coverLimitErr
+		}
+	}
+
+	jitter := strings.TrimSpace(string(setting["Shape-Jitter"]))
+
+	if len(jitter) > 0 {
+		var jitterErr error
+
+		_, result.Config.Jitter, jitterErr = shaperDurationRange(
+			"Shape-Jitter", jitter)
+
+		if jitterErr != nil {
+			return shaperSetting{}, jitterErr
+		}
+
+		if result.Config.Jitter > shaperMaxJitter {
+			return shaperSetting{}, fmt.Errorf(
+				"\"Shape-Jitter\" must not be larger than %s",
+				shaperMaxJitter)
+		}
+
+		result.Enabled = true
+	}
+
+	return result, nil
+}
+
+// wrap wraps the Codec so the data will be shaped before encode
+func (s shaperSetting) wrap(c rw.Codec, cErr error) (rw.Codec, error) {
+	if cErr != nil {
+		return nil, cErr
+	}
+
+	if !s.Enabled {
+		return c, nil
+	}
+
+	return shape.New(c, s.Config), nil
+}
@@ -0,0 +1,170 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+func TestShaperSettingParser(t *testing.T) {
+	tests := []struct {
+		Setting  codecSetting
+		Enabled  bool
+		CoverMin time.Duration
+		CoverMax time.Duration
+		Failure  bool
+	}{
+		{codecSetting{}, false, 0, 0, false},
+		{codecSetting{"Shape-Sizes": []byte("576, 1200,1400")},
+			true, 0, 0, false},
+		{codecSetting{"Shape-Sizes": []byte("200-1400")},
+			true, 0, 0, false},
+		{codecSetting{"Shape-Cover": []byte("1s-5s")},
+			true, 1 * time.Second, 5 * time.Second, false},
+		{codecSetting{"Shape-Cover": []byte("2s")},
+			true, 2 * time.Second, 2 * time.Second, false},
+		{codecSetting{"Shape-Jitter": []byte("20ms")},
+			true, 0, 0, false},
+		{codecSetting{"Shape-Sizes": []byte("1400-200")}, false, 0, 0, true},
+		{codecSetting{"Shape-Sizes": []byte("8")}, false, 0, 0, true},
+		{codecSetting{"Shape-Sizes": []byte("5000")}, false, 0, 0, true},
+		{codecSetting{"Shape-Cover": []byte("5s-1s")}, false, 0, 0, true},
+		{codecSetting{"Shape-Jitter": []byte("2s")}, false, 0, 0, true},
+	}
+
+	for tIdx, test := range tests {
+		result, resultErr := shaperSettingParser(test.Setting)
+
+		if test.Failure {
+			if resultErr == nil {
+				t.Errorf("Test %d: Expecting failure", tIdx)
+
+				return
+			}
+
+			continue
+		}
+
+		if resultErr != nil {
+			t.Errorf("Test %d: Unexpected error: %s", tIdx, resultErr)
+
+			return
+		}
+
+		if result.Enabled != test.Enabled ||
+			result.Config.CoverMin != test.CoverMin ||
+			result.Config.CoverMax != test.CoverMax {
+			t.Errorf("Test %d: Unexpected result %+v", tIdx, result)
+
+			return
+		}
+	}
+}
+
+func TestShaperHandshake(t *testing.T) {
+	builder := aesGCM256X25519Builder([]string{
+		"0123456789abcdef", "Shape-Sizes: 200-1400"},
+		logger.NewDitch(), metrics.NewDitch())
+
+	client, clientErr := builder()
+
+	if clientErr != nil {
+		t.Error("Failed to build codec due to error:", clientErr)
+
+		return
+	}
+
+	server, serverErr := builder()
+
+	if serverErr != nil {
+		t.Error("Failed to build codec due to error:", serverErr)
+
+		return
+	}
+
+	clientHandshaker, isHandshaker := client.(transceiver.CodecHandshaker)
+
+	if !isHandshaker {
+		t.Error("Expecting the shaped codec to be a CodecHandshaker")
+
+		return
+	}
+
+	clientConn, serverConn := net.Pipe()
+
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	serverResult := make(chan error, 1)
+	received := make([]byte, 5)
+
+	go func() {
+		hErr := server.(transceiver.CodecHandshaker).Handshake(
+			serverConn, false)
+
+		if hErr != nil {
+			serverResult <- hErr
+
+			return
+		}
+
+		_, rErr := io.ReadFull(server.Decode(serverConn), received)
+
+		serverResult <- rErr
+	}()
+
+	hErr := clientHandshaker.Handshake(clientConn, true)
+
+	if hErr != nil {
+		t.Error("Failed to handshake due to error:", hErr)
+
+		return
+	}
+
+	_, wErr := client.Encode(clientConn).Write([]byte("Hello"))
+
+	if wErr != nil {
+		t.Error("Failed to write due to error:", wErr)
+
+		return
+	}
+
+	sErr := <-serverResult
+
+	if sErr != nil {
+		t.Error("Failed to receive due to error:", sErr)
+
+		return
+	}
+
+	if string(received) != "Hello" {
+		t.Errorf("Expecting to receive %q, got %q", "Hello", received)
+
+		return
+	}
+}
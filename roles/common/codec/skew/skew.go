@@ -0,0 +1,265 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package skew
+
+import (
+	"io"
+	"sync"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Builder builds a Codec with given key and marker
+type Builder func(k key.Key, mark marker.Marker) (rw.Codec, error)
+
+// Reporter receives the Skew of the key that the remote is using, which
+// tells the offset of the time period (in whole key windows) and the
+// key it's using
+type Reporter func(used key.Skew)
+
+type candidate struct {
+	codec rw.Codec
+	used  key.Skew
+}
+
+type skewed struct {
+	candidates []candidate
+	report     Reporter
+	selected   rw.Codec
+	encoder    rw.Codec
+	recorded   []byte
+	remain     []byte
+	lock       sync.Mutex
+}
+
+// Skewed returns a Codec which tolerates the clock skew of the remote.
+//
+// A Codec will be built for each of the Skews of the key. When the
+// first segment of data is decoding, every one of them will be tried
+// until one of them can successfully decode the data, that Codec will
+// then be used for both decode and encode for the rest of the
+// connection.
+//
+// The Codec built by the builder must return a transceiver.CodecError
+// when the data can't be decoded with the key, and it must discover
+// the failure without reading more data than the remote has sent in
+// it's first segment
+func Skewed(
+	k key.Skewed,
+	builder Builder,
+	mark marker.Marker,
+	report Reporter,
+) (rw.Codec, error) {
+	skews := k.Skews()
+
+	if len(skews) <= 1 {
+		return builder(k, mark)
+	}
+
+	// All candidates will try to mark the same data, so only the first
+	// one will be forwarded to the shared marker
+	connMark := &connMarker{
+		marker: mark,
+		marked: make(map[marker.Mark]struct{}, 1),
+	}
+
+	candidates := make([]candidate, len(skews))
+
+	for sIdx := range skews {
+		c, cErr := builder(skews[sIdx].Key, connMark)
+
+		if cErr != nil {
+			return nil, cErr
+		}
+
+		candidates[sIdx] = candidate{
+			codec: c,
+			used:  skews[sIdx],
+		}
+	}
+
+	return &skewed{
+		candidates: candidates,
+		report:     report,
+		selected:   nil,
+		encoder:    nil,
+		recorded:   nil,
+		remain:     nil,
+		lock:       sync.Mutex{},
+	}, nil
+}
+
+func (s *skewed) selectCandidate(cIdx int, consumed int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.selected = s.candidates[cIdx].codec
+	s.remain = s.recorded[consumed:]
+	s.recorded = nil
+
+	s.report(s.candidates[cIdx].used)
+}
+
+func (s *skewed) Encode(w io.Writer) rw.WriteWriteAll {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.encoder != nil {
+		return s.encoder.Encode(w)
+	}
+
+	// If we must encode before anything has been decoded, use the key of
+	// current time period. The encoder can't be switched after that as
+	// the remote has already received our encoded data
+	if s.selected != nil {
+		s.encoder = s.selected
+	} else {
+		s.encoder = s.candidates[0].codec
+	}
+
+	return s.encoder.Encode(w)
+}
+
+func (s *skewed) Decode(r io.Reader) io.Reader {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.selected == nil {
+		return trial{s: s, r: r}
+	}
+
+	if len(s.remain) > 0 {
+		return s.selected.Decode(replayer{s: s, r: r})
+	}
+
+	return s.selected.Decode(r)
+}
+
+// trial tries to decode data with every candidates
+type trial struct {
+	s *skewed
+	r io.Reader
+}
+
+func (t trial) Read(b []byte) (int, error) {
+	var lastErr error
+
+	for cIdx := range t.s.candidates {
+		rec := &recorder{s: t.s, r: t.r, pos: 0}
+
+		rLen, rErr := t.s.candidates[cIdx].codec.Decode(rec).Read(b)
+
+		if rErr == nil {
+			t.s.selectCandidate(cIdx, rec.pos)
+
+			return rLen, nil
+		}
+
+		_, isCodecErr := rErr.(transceiver.CodecError)
+
+		if !isCodecErr {
+			return rLen, rErr
+		}
+
+		lastErr = rErr
+	}
+
+	return 0, lastErr
+}
+
+// recorder reads the recorded data first, then reads and records data
+// from the remote
+type recorder struct {
+	s   *skewed
+	r   io.Reader
+	pos int
+}
+
+func (r *recorder) Read(b []byte) (int, error) {
+	if r.pos < len(r.s.recorded) {
+		copied := copy(b, r.s.recorded[r.pos:])
+
+		r.pos += copied
+
+		return copied, nil
+	}
+
+	rLen, rErr := r.r.Read(b)
+
+	r.s.recorded = append(r.s.recorded, b[:rLen]...)
+	r.pos += rLen
+
+	return rLen, rErr
+}
+
+// replayer reads the remaining recorded data first, then reads data
+// from the remote
+type replayer struct {
+	s *skewed
+	r io.Reader
+}
+
+func (r replayer) Read(b []byte) (int, error) {
+	r.s.lock.Lock()
+
+	if len(r.s.remain) > 0 {
+		copied := copy(b, r.s.remain)
+
+		r.s.remain = r.s.remain[copied:]
+
+		r.s.lock.Unlock()
+
+		return copied, nil
+	}
+
+	r.s.lock.Unlock()
+
+	return r.r.Read(b)
+}
+
+// connMarker forwards the marks of one connection to the shared marker,
+// and ignores the marks which has already been forwarded by the same
+// connection
+type connMarker struct {
+	marker marker.Marker
+	marked map[marker.Mark]struct{}
+}
+
+func (c *connMarker) Mark(m marker.Mark) error {
+	_, found := c.marked[m]
+
+	if found {
+		return nil
+	}
+
+	mErr := c.marker.Mark(m)
+
+	if mErr != nil {
+		return mErr
+	}
+
+	c.marked[m] = struct{}{}
+
+	return nil
+}
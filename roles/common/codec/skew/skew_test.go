@@ -0,0 +1,218 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package skew
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/aesgcm"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+func testSkewed(
+	t *testing.T,
+	clock time.Time,
+	skew uint8,
+	report Reporter,
+) rw.Codec {
+	c, cErr := Skewed(key.Timed(
+		[]byte("Test Key"), 10*time.Second, skew, func() time.Time {
+			return clock
+		}), func(k key.Key, m marker.Marker) (rw.Codec, error) {
+		return aesgcm.AESGCM(k, 32, m)
+	}, marker.Timed(16, 0.0001, 10*time.Second, time.Now), report)
+
+	if cErr != nil {
+		t.Fatal("Failed to build codec due to error:", cErr)
+	}
+
+	return c
+}
+
+func TestSkewed(t *testing.T) {
+	now := time.Now()
+	reported := time.Duration(-1)
+
+	client := testSkewed(t, now.Add(-20*time.Second), 0, nil)
+	server := testSkewed(t, now, 2, func(used key.Skew) {
+		reported = used.Skew
+	})
+
+	buf := bytes.NewBuffer(nil)
+
+	client.Encode(buf).Write([]byte("Hello"))
+	client.Encode(buf).Write([]byte("World"))
+
+	result := make([]byte, 10)
+
+	_, rErr := io.ReadFull(server.Decode(buf), result[:5])
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	_, rErr = io.ReadFull(server.Decode(buf), result[5:])
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(result, []byte("HelloWorld")) {
+		t.Errorf("Expecting \"HelloWorld\", got %q", result)
+
+		return
+	}
+
+	if reported != -20*time.Second {
+		t.Errorf("Expecting skew %s, got %s", -20*time.Second, reported)
+
+		return
+	}
+
+	server.Encode(buf).Write([]byte("Hi"))
+
+	_, rErr = io.ReadFull(client.Decode(buf), result[:2])
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(result[:2], []byte("Hi")) {
+		t.Errorf("Expecting \"Hi\", got %q", result[:2])
+
+		return
+	}
+}
+
+func TestSkewedTooMuch(t *testing.T) {
+	now := time.Now()
+
+	client := testSkewed(t, now.Add(-40*time.Second), 0, nil)
+	server := testSkewed(t, now, 2, func(key.Skew) {
+		t.Error("Skew must not be reported")
+	})
+
+	buf := bytes.NewBuffer(nil)
+
+	client.Encode(buf).Write([]byte("Hello"))
+
+	_, rErr := server.Decode(buf).Read(make([]byte, 5))
+
+	_, isCodecErr := rErr.(transceiver.CodecError)
+
+	if !isCodecErr {
+		t.Errorf("Expecting a codec error, got %s", rErr)
+
+		return
+	}
+}
+
+func TestSkewedPreviousKey(t *testing.T) {
+	now := time.Now()
+	timer := func() time.Time { return now }
+	reported := key.Skew{Previous: -1}
+	builder := func(k key.Key, m marker.Marker) (rw.Codec, error) {
+		return aesgcm.AESGCM(k, 32, m)
+	}
+
+	client, clientErr := Skewed(key.Timed(
+		[]byte("Previous Key"), 10*time.Second, 0, timer), builder,
+		marker.Timed(16, 0.0001, 10*time.Second, time.Now), nil)
+
+	if clientErr != nil {
+		t.Error("Failed to build codec due to error:", clientErr)
+
+		return
+	}
+
+	server, serverErr := Skewed(key.Rotated(key.Timed(
+		[]byte("Primary Key"), 10*time.Second, 1, timer), []key.Previous{
+		{
+			Key: key.Timed(
+				[]byte("Previous Key"), 10*time.Second, 1, timer),
+			Expiry: now.Add(time.Minute),
+		},
+	}, timer), builder, marker.Timed(
+		16, 0.0001, 10*time.Second, time.Now), func(used key.Skew) {
+		reported = used
+	})
+
+	if serverErr != nil {
+		t.Error("Failed to build codec due to error:", serverErr)
+
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	client.Encode(buf).Write([]byte("Hello"))
+
+	result := make([]byte, 5)
+
+	_, rErr := io.ReadFull(server.Decode(buf), result)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if string(result) != "Hello" {
+		t.Errorf("Expecting to read %s, got %s", "Hello", result)
+
+		return
+	}
+
+	if reported.Previous != 1 || reported.Skew != 0 {
+		t.Errorf("Expecting previous key 1 to be reported, got %d",
+			reported.Previous)
+
+		return
+	}
+
+	server.Encode(buf).Write([]byte("World"))
+
+	_, rErr = io.ReadFull(client.Decode(buf), result)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if string(result) != "World" {
+		t.Errorf("Expecting to read %s, got %s", "World", result)
+
+		return
+	}
+}
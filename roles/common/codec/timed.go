@@ -0,0 +1,427 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/codec/marker"
+	"github.com/reinit/coward/roles/common/codec/skew"
+)
+
+// Consts
+const (
+	timedMinKeyLength  = 16
+	timedDefaultWindow = 10 * time.Second
+	timedMinWindow     = 1 * time.Second
+	timedDefaultSkew   = 1
+	timedMaxSkew       = 16
+	timedMaxPrevious   = 8
+
+	timedReportInterval = 1 * time.Minute
+
+	timedKeyFileSize = key.DerivedSize
+
+	timedKeyUsage = "Input a string of letters as shared key " +
+		"(passphrase), multiple lines will be combined into a single line " +
+		"according to order.\r\n\r\nInstead of the passphrase, a file " +
+		"which contains a raw 32 bytes key can be loaded through the " +
+		"\"Key-File\" option, so the key will not be exposed in the " +
+		"command line.\r\n\r\nOptionally, the length of the time " +
+		"period of the key can be changed through the \"Window\" option " +
+		"(default: 10s), it must be the same on both side. Example:" +
+		"\r\n\r\nKey: <Shared Key>\r\nWindow: 30s" + kdfUsage
+
+	timedWindowUsage = timedKeyUsage + replayUsage + prefixerUsage +
+		shaperUsage
+
+	timedSkewUsage = timedKeyUsage + "\r\n\r\nWhen the clock of the " +
+		"remote was skewed, keys of adjacent time periods will be tried. " +
+		"How many periods to try on each side can be set through the " +
+		"\"Skew\" option (default: 1, max: 16). Example:\r\n\r\nSkew: 2" +
+		"\r\n\r\nTo change the key without breaking the remotes that still " +
+		"using the old one, put the old key in a \"Previous-Key\" option " +
+		"(up to 8). The previous keys will only be accepted from the " +
+		"remote, the key defined by the \"Key\" option is always used to " +
+		"send data. An optional RFC3339 expiry time can be placed before " +
+		"the previous key, after that the key will no longer be accepted. " +
+		"Example:\r\n\r\nKey: <New Shared Key>\r\n" +
+		"Previous-Key: 2018-12-31T00:00:00Z <Old Shared Key>" +
+		replayUsage + prefixerUsage + shaperUsage
+)
+
+// Vars
+var (
+	timedWindowOptions = append(append(append([]string{
+		"Key", "Key-File", "Window", "Request-Prefix", "Respond-Prefix",
+	}, kdfOptions...), replayOptions...), shaperOptions...)
+	timedSkewOptions = append(append(append([]string{
+		"Key", "Key-File", "Previous-Key", "Window", "Skew",
+		"Request-Prefix", "Respond-Prefix",
+	}, kdfOptions...), replayOptions...), shaperOptions...)
+)
+
+// Errors
+var (
+	ErrTimedSharedKeyTooShort = errors.New(
+		"Shared Key was too short. Make it at least 16 characters long")
+
+	ErrTimedKeyFileConflicted = errors.New(
+		"\"Key\" and \"Key-File\" option can't be used at the same time")
+
+	ErrTimedKeyFileKDF = errors.New(
+		"\"KDF\" option can't be used with \"Key-File\" option")
+
+	ErrTimedSkewUnsupported = errors.New(
+		"\"Skew\" option is not supported by this Codec")
+
+	ErrTimedPreviousKeyUnsupported = errors.New(
+		"\"Previous-Key\" option is not supported by this Codec")
+
+	ErrTimedPreviousKeyTooMany = fmt.Errorf(
+		"Too many \"Previous-Key\" options. At most %d previous keys "+
+			"can be defined", timedMaxPrevious)
+)
+
+// timedPreviousKey is a previous key which is still accepted until the
+// Expiry (or forever when it's zero)
+type timedPreviousKey struct {
+	Key    []byte
+	Expiry time.Time
+}
+
+// timedSetting is the setting of the Codecs which uses timed keys
+type timedSetting struct {
+	Key      []byte
+	Window   time.Duration
+	Skew     uint8
+	Previous []timedPreviousKey
+	KDF      kdfSetting
+	Replay   replaySetting
+	Prefixer prefixerSetting
+	Shaper   shaperSetting
+}
+
+// timedSettingParser parses timedSetting
+func timedSettingParser(
+	configuration []string,
+	options []string,
+) (timedSetting, error) {
+	// Always parse with all options so a "Skew" option given to the Codec
+	// which don't support it won't be silently combined into the key
+	setting, settingErr := codecSettingParser(
+		configuration, timedSkewOptions, "Key")
+
+	if settingErr != nil {
+		return timedSetting{}, settingErr
+	}
+
+	previous, previousErr := codecSettingEntries(
+		configuration, timedSkewOptions, "Key", "Previous-Key")
+
+	if previousErr != nil {
+		return timedSetting{}, previousErr
+	}
+
+	skewSupported := false
+
+	for oIdx := range options {
+		if options[oIdx] != "Skew" {
+			continue
+		}
+
+		skewSupported = true
+	}
+
+	kdf, kdfErr := kdfSettingParser(setting)
+
+	if kdfErr != nil {
+		return timedSetting{}, kdfErr
+	}
+
+	replay, replayErr := replaySettingParser(setting)
+
+	if replayErr != nil {
+		return timedSetting{}, replayErr
+	}
+
+	prefixer, prefixerErr := prefixerSettingParser(setting)
+
+	if prefixerErr != nil {
+		return timedSetting{}, prefixerErr
+	}
+
+	shaper, shaperErr := shaperSettingParser(setting)
+
+	if shaperErr != nil {
+		return timedSetting{}, shaperErr
+	}
+
+	result := timedSetting{
+		Key:      setting["Key"],
+		Window:   timedDefaultWindow,
+		Skew:     0,
+		Previous: nil,
+		KDF:      kdf,
+		Replay:   replay,
+		Prefixer: prefixer,
+		Shaper:   shaper,
+	}
+
+	keyFile := strings.TrimSpace(string(setting["Key-File"]))
+
+	if len(keyFile) > 0 {
+		var keyFileErr error
+
+		result.Key, keyFileErr = timedKeyFileLoader(
+			keyFile, result.Key, result.KDF)
+
+		if keyFileErr != nil {
+			return timedSetting{}, keyFileErr
+		}
+	} else if len(result.Key) < timedMinKeyLength {
+		return timedSetting{}, ErrTimedSharedKeyTooShort
+	}
+
+	window := strings.TrimSpace(string(setting["Window"]))
+
+	if len(window) > 0 {
+		var windowErr error
+
+		result.Window, windowErr = time.ParseDuration(window)
+
+		if windowErr != nil {
+			return timedSetting{}, fmt.Errorf(
+				"Invalid value \"%s\" of \"Window\" option: %s",
+				window, windowErr)
+		}
+
+		if result.Window < timedMinWindow {
+			return timedSetting{}, fmt.Errorf(
+				"\"Window\" must be at least %s", timedMinWindow)
+		}
+	}
+
+	skewStr := strings.TrimSpace(string(setting["Skew"]))
+
+	if !skewSupported {
+		if len(skewStr) > 0 {
+			return timedSetting{}, ErrTimedSkewUnsupported
+		}
+
+		if len(previous) > 0 {
+			return timedSetting{}, ErrTimedPreviousKeyUnsupported
+		}
+
+		return result, nil
+	}
+
+	var parseErr error
+
+	result.Previous, parseErr = timedPreviousKeyParser(previous)
+
+	if parseErr != nil {
+		return timedSetting{}, parseErr
+	}
+
+	result.Skew = timedDefaultSkew
+
+	if len(skewStr) <= 0 {
+		return result, nil
+	}
+
+	skewValue, skewErr := strconv.ParseUint(skewStr, 10, 8)
+
+	if skewErr != nil || skewValue > timedMaxSkew {
+		return timedSetting{}, fmt.Errorf(
+			"Invalid value \"%s\" of \"Skew\" option. It must be a "+
+				"number between 0 and %d", skewStr, timedMaxSkew)
+	}
+
+	result.Skew = uint8(skewValue)
+
+	return result, nil
+}
+
+// timedKeyFileLoader loads the raw key from the key file
+func timedKeyFileLoader(
+	keyFile string, passphrase []byte, kdf kdfSetting) ([]byte, error) {
+	if len(passphrase) > 0 {
+		return nil, ErrTimedKeyFileConflicted
+	}
+
+	if kdf.KDF != nil {
+		return nil, ErrTimedKeyFileKDF
+	}
+
+	k, kErr := ioutil.ReadFile(keyFile)
+
+	if kErr != nil {
+		return nil, fmt.Errorf(
+			"Failed to load the key from \"Key-File\" \"%s\": %s",
+			keyFile, kErr)
+	}
+
+	if len(k) != timedKeyFileSize {
+		return nil, fmt.Errorf(
+			"The \"Key-File\" \"%s\" must contain exactly %d bytes of "+
+				"key, got %d bytes", keyFile, timedKeyFileSize, len(k))
+	}
+
+	return k, nil
+}
+
+// timedPreviousKeyParser parses "[<RFC3339 Expiry> ]<Key>" entries
+func timedPreviousKeyParser(
+	entries [][]byte) ([]timedPreviousKey, error) {
+	if len(entries) > timedMaxPrevious {
+		return nil, ErrTimedPreviousKeyTooMany
+	}
+
+	result := make([]timedPreviousKey, len(entries))
+
+	for eIdx := range entries {
+		result[eIdx].Key = entries[eIdx]
+
+		spaceIdx := bytes.IndexByte(entries[eIdx], ' ')
+
+		if spaceIdx > 0 {
+			expiry, expiryErr := time.Parse(
+				time.RFC3339, string(entries[eIdx][:spaceIdx]))
+
+			if expiryErr == nil {
+				result[eIdx].Key = entries[eIdx][spaceIdx+1:]
+				result[eIdx].Expiry = expiry
+			}
+		}
+
+		if len(result[eIdx].Key) < timedMinKeyLength {
+			return nil, ErrTimedSharedKeyTooShort
+		}
+	}
+
+	return result, nil
+}
+
+// timedSettingBuilder builds timedSetting
+func timedSettingBuilder(
+	configuration []string, options []string) timedSetting {
+	setting, settingErr := timedSettingParser(configuration, options)
+
+	if settingErr != nil {
+		panic(fmt.Sprintf("Bad timed setting: %s", settingErr))
+	}
+
+	return setting
+}
+
+// timedSettingVerifier returns a verifier which verifies timedSetting
+func timedSettingVerifier(
+	options []string) func(configuration []string) error {
+	return func(configuration []string) error {
+		_, settingErr := timedSettingParser(configuration, options)
+
+		return settingErr
+	}
+}
+
+// key returns the timed key
+func (t timedSetting) key() key.Skewed {
+	previous := make([]key.Previous, len(t.Previous))
+
+	for pIdx := range t.Previous {
+		previous[pIdx] = key.Previous{
+			Key: key.Timed(t.KDF.derive(t.Previous[pIdx].Key),
+				t.Window, t.Skew, time.Now),
+			Expiry: t.Previous[pIdx].Expiry,
+		}
+	}
+
+	return key.Rotated(key.Timed(t.KDF.derive(t.Key),
+		t.Window, t.Skew, time.Now), previous, time.Now)
+}
+
+// marker returns the replay marker. A mark must be kept until the key
+// which marked data was encrypted with is no longer accepted, which is
+// Skew + 1 periods after the data was sent
+func (t timedSetting) marker(m metrics.Registry) marker.Marker {
+	return t.Replay.marker(t.Window*time.Duration(t.Skew+1), m)
+}
+
+// reporter returns a skew.Reporter which logs the time period offset
+// and the previous key the remote is using. The previous key notice is
+// logged at most once per timedReportInterval
+func (t timedSetting) reporter(log logger.Logger) skew.Reporter {
+	reported := time.Time{}
+	reportLock := sync.Mutex{}
+
+	return func(used key.Skew) {
+		if used.Skew != 0 {
+			log.Debugf("The remote is using the key of the time period "+
+				"which is %s away from the current one", used.Skew)
+		}
+
+		if used.Previous <= 0 {
+			return
+		}
+
+		now := time.Now()
+
+		reportLock.Lock()
+
+		if now.Sub(reported) < timedReportInterval {
+			reportLock.Unlock()
+
+			return
+		}
+
+		reported = now
+
+		reportLock.Unlock()
+
+		expiry := "never expires"
+
+		if !t.Previous[used.Previous-1].Expiry.IsZero() {
+			expiry = "expires at " + t.Previous[used.Previous-1].
+				Expiry.Format(time.RFC3339)
+		}
+
+		log.Infof("The remote is using previous key #%d which %s. "+
+			"Please update the remote to use the current key",
+			used.Previous, expiry)
+	}
+}
+
+// wrap wraps the Codec with the shaper and the prefixer
+func (t timedSetting) wrap(c rw.Codec, cErr error) (rw.Codec, error) {
+	return t.Prefixer.wrap(t.Shaper.wrap(c, cErr))
+}
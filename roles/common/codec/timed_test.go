@@ -0,0 +1,258 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package codec
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestTimedSettingParser(t *testing.T) {
+	tests := []struct {
+		Configuration []string
+		Options       []string
+		Expected      timedSetting
+		Failure       bool
+	}{
+		{
+			[]string{"0123456789", "abcdef: 0123"},
+			timedSkewOptions,
+			timedSetting{
+				Key:    []byte("0123456789abcdef: 0123"),
+				Window: 10 * time.Second,
+				Skew:   1,
+			},
+			false,
+		},
+		{
+			[]string{"Window: 30s", "Key: 0123456789", "abcdef", "Skew: 2"},
+			timedSkewOptions,
+			timedSetting{
+				Key:    []byte("0123456789abcdef"),
+				Window: 30 * time.Second,
+				Skew:   2,
+			},
+			false,
+		},
+		{
+			[]string{"0123456789abcdef", "Window: 5s"},
+			timedWindowOptions,
+			timedSetting{
+				Key:    []byte("0123456789abcdef"),
+				Window: 5 * time.Second,
+				Skew:   0,
+			},
+			false,
+		},
+		{
+			[]string{"0123456789abcdef", "Request-Prefix: 4745",
+				"54", "Respond-Prefix: 48545450"},
+			timedWindowOptions,
+			timedSetting{
+				Key:    []byte("0123456789abcdef"),
+				Window: 10 * time.Second,
+				Skew:   0,
+				Prefixer: prefixerSetting{
+					Request: []byte("GET"),
+					Respond: []byte("HTTP"),
+				},
+			},
+			false,
+		},
+		{
+			[]string{"0123456789abcdef", "Request-Prefix: 4G"},
+			timedWindowOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"0123456789abcdef", "Skew: 2"},
+			timedWindowOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"0123456789abcdef", "Window: 1ms"},
+			timedSkewOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"0123456789abcdef", "Skew: 17"},
+			timedSkewOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"Key: 0123456789", "Window: 30s"},
+			timedSkewOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"0123456789abcdef",
+				"Previous-Key: 2018-12-31T00:00:00Z fedcba", "9876543210",
+				"Previous-Key: 0123456789 abcdef"},
+			timedSkewOptions,
+			timedSetting{
+				Key:    []byte("0123456789abcdef"),
+				Window: 10 * time.Second,
+				Skew:   1,
+				Previous: []timedPreviousKey{
+					{
+						Key: []byte("fedcba9876543210"),
+						Expiry: time.Date(
+							2018, 12, 31, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						Key:    []byte("0123456789 abcdef"),
+						Expiry: time.Time{},
+					},
+				},
+			},
+			false,
+		},
+		{
+			[]string{"0123456789abcdef", "Previous-Key: fedcba9876543210"},
+			timedWindowOptions,
+			timedSetting{},
+			true,
+		},
+		{
+			[]string{"0123456789abcdef",
+				"Previous-Key: 2018-12-31T00:00:00Z fedcba"},
+			timedSkewOptions,
+			timedSetting{},
+			true,
+		},
+	}
+
+	for tIdx, test := range tests {
+		result, resultErr := timedSettingParser(
+			test.Configuration, test.Options)
+
+		if test.Failure {
+			if resultErr == nil {
+				t.Errorf("Test %d: Expecting failure, got %v", tIdx, result)
+
+				return
+			}
+
+			continue
+		}
+
+		if resultErr != nil {
+			t.Errorf("Test %d: Unexpected error: %s", tIdx, resultErr)
+
+			return
+		}
+
+		if !bytes.Equal(result.Key, test.Expected.Key) ||
+			result.Window != test.Expected.Window ||
+			result.Skew != test.Expected.Skew ||
+			!bytes.Equal(result.Prefixer.Request,
+				test.Expected.Prefixer.Request) ||
+			!bytes.Equal(result.Prefixer.Respond,
+				test.Expected.Prefixer.Respond) {
+			t.Errorf("Test %d: Expecting %v, got %v",
+				tIdx, test.Expected, result)
+
+			return
+		}
+
+		if len(result.Previous) != len(test.Expected.Previous) {
+			t.Errorf("Test %d: Expecting %d previous keys, got %d", tIdx,
+				len(test.Expected.Previous), len(result.Previous))
+
+			return
+		}
+
+		for pIdx, expected := range test.Expected.Previous {
+			if !bytes.Equal(result.Previous[pIdx].Key, expected.Key) ||
+				!result.Previous[pIdx].Expiry.Equal(expected.Expiry) {
+				t.Errorf("Test %d: Expecting previous key %v, got %v",
+					tIdx, expected, result.Previous[pIdx])
+
+				return
+			}
+		}
+	}
+}
+
+func TestTimedSettingKeyFile(t *testing.T) {
+	keyFile, keyFileErr := ioutil.TempFile("", "coward-key")
+
+	if keyFileErr != nil {
+		t.Error("Failed to create key file due to error:", keyFileErr)
+
+		return
+	}
+
+	defer os.Remove(keyFile.Name())
+
+	rawKey := bytes.Repeat([]byte{0xAB}, timedKeyFileSize)
+
+	keyFile.Write(rawKey)
+	keyFile.Close()
+
+	setting, settingErr := timedSettingParser(
+		[]string{"Key-File: " + keyFile.Name()}, timedSkewOptions)
+
+	if settingErr != nil {
+		t.Error("Failed to parse setting due to error:", settingErr)
+
+		return
+	}
+
+	if !bytes.Equal(setting.Key, rawKey) {
+		t.Errorf("Expecting key %d, got %d", rawKey, setting.Key)
+
+		return
+	}
+
+	for tIdx, configuration := range [][]string{
+		{"0123456789abcdef", "Key-File: " + keyFile.Name()},
+		{"Key-File: " + keyFile.Name(), "KDF: scrypt"},
+		{"Key-File: " + keyFile.Name() + ".notexist"},
+	} {
+		_, settingErr = timedSettingParser(configuration, timedSkewOptions)
+
+		if settingErr == nil {
+			t.Errorf("Test %d: Expecting failure", tIdx)
+
+			return
+		}
+	}
+
+	os.Truncate(keyFile.Name(), 16)
+
+	_, settingErr = timedSettingParser(
+		[]string{"Key-File: " + keyFile.Name()}, timedSkewOptions)
+
+	if settingErr == nil {
+		t.Error("Expecting failure when the key file is too short")
+
+		return
+	}
+}
@@ -0,0 +1,61 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import "time"
+
+// Consts
+const (
+	// DefaultWindow is the default size of the send and receive window
+	DefaultWindow = 128
+
+	// DefaultMinRTO is the default lower bound of retransmission timeout
+	DefaultMinRTO = 100 * time.Millisecond
+
+	// DefaultFastResend is the default amount of acknowledgements of later
+	// segments that will trigger the retransmission of an earlier one
+	DefaultFastResend = 2
+
+	// DatagramBufferSize is the size of buffer that is big enough to hold
+	// any datagram sent by the ARQ connection
+	DatagramBufferSize = 2048
+)
+
+// Config is the configuration of an ARQ connection. Both sides of the
+// connection must share the same FEC setting
+type Config struct {
+	// Window is the maximum amount of unacknowledged segments that can be
+	// sent, as well as the amount of segments can be buffered for receive
+	Window uint16
+
+	// MinRTO is the lower bound of the retransmission timeout
+	MinRTO time.Duration
+
+	// FastResend is the amount of acknowledgements of later segments
+	// that will trigger the retransmission of an earlier segment without
+	// waiting for it to be timed out. 0 to disable
+	FastResend uint8
+
+	// FEC is the amount of datagrams which will be protected by one XOR
+	// parity datagram, so any one of them can be recovered when lost
+	// without retransmission. 0 to disable
+	FEC uint8
+}
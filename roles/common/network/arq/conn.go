@@ -0,0 +1,861 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Errors
+var (
+	ErrClosed = errors.New(
+		"ARQ connection is closed")
+
+	ErrPeerClosed = errors.New(
+		"ARQ connection has been closed by the peer")
+
+	ErrDeadLink = errors.New(
+		"ARQ connection is dead as a segment has been retransmitted " +
+			"too many times")
+)
+
+type ack struct {
+	sn uint32
+	ts uint32
+}
+
+// Conn is a reliable connection which carries a byte stream over an
+// unreliable datagram connection through Automatic Repeat reQuest
+type Conn struct {
+	conn          net.Conn
+	window        uint16
+	minRTO        time.Duration
+	fastResend    uint32
+	mss           int
+	epoch         time.Time
+	fecEncoder    *fecEncoder
+	fecDecoder    *fecDecoder
+	lock          sync.Mutex
+	conv          uint32
+	convAdopted   bool
+	sndQueue      [][]byte
+	sndBuf        []*segment
+	sndUna        uint32
+	sndNxt        uint32
+	rmtWnd        uint16
+	probeWait     time.Duration
+	probeAt       time.Time
+	rcvNxt        uint32
+	rcvBuf        map[uint32][]byte
+	rcvQueue      []byte
+	acks          []ack
+	tellWindow    bool
+	windowZero    bool
+	srtt          time.Duration
+	rttvar        time.Duration
+	rto           time.Duration
+	remoteFin     bool
+	remoteFinSn   uint32
+	closing       bool
+	err           error
+	readDeadline  time.Time
+	writeDeadline time.Time
+	writeLock     sync.Mutex
+	outBuf        []byte
+	readable      chan struct{}
+	writable      chan struct{}
+	flushing      chan struct{}
+	closed        chan struct{}
+	closeOnce     sync.Once
+}
+
+// Server creates an ARQ connection on the server side of the datagram
+// connection. The conversation ID will be adopted from the first segment
+// received from the client
+func Server(conn net.Conn, cfg Config) *Conn {
+	c := newConn(conn, cfg, 0, false)
+
+	c.start()
+
+	return c
+}
+
+// Client creates an ARQ connection on the client side of the datagram
+// connection
+func Client(conn net.Conn, cfg Config) *Conn {
+	var conv [4]byte
+
+	rand.Read(conv[:])
+
+	c := newConn(conn, cfg, binary.BigEndian.Uint32(conv[:]), true)
+
+	c.start()
+
+	return c
+}
+
+func newConn(
+	conn net.Conn, cfg Config, conv uint32, convAdopted bool) *Conn {
+	var fecEnc *fecEncoder
+	var fecDec *fecDecoder
+
+	mss := mtu - segmentHeaderSize
+
+	if cfg.FEC > 0 {
+		fecEnc = newFECEncoder(cfg.FEC)
+		fecDec = newFECDecoder(cfg.FEC)
+
+		mss -= fecHeaderSize
+	}
+
+	rto := initialRTO
+
+	if cfg.MinRTO > rto {
+		rto = cfg.MinRTO
+	}
+
+	return &Conn{
+		conn:          conn,
+		window:        cfg.Window,
+		minRTO:        cfg.MinRTO,
+		fastResend:    uint32(cfg.FastResend),
+		mss:           mss,
+		epoch:         time.Now(),
+		fecEncoder:    fecEnc,
+		fecDecoder:    fecDec,
+		lock:          sync.Mutex{},
+		conv:          conv,
+		convAdopted:   convAdopted,
+		sndQueue:      make([][]byte, 0, cfg.Window),
+		sndBuf:        make([]*segment, 0, cfg.Window),
+		sndUna:        0,
+		sndNxt:        0,
+		rmtWnd:        cfg.Window,
+		probeWait:     0,
+		probeAt:       time.Time{},
+		rcvNxt:        0,
+		rcvBuf:        make(map[uint32][]byte, cfg.Window),
+		rcvQueue:      nil,
+		acks:          make([]ack, 0, cfg.Window),
+		tellWindow:    false,
+		windowZero:    false,
+		srtt:          0,
+		rttvar:        0,
+		rto:           rto,
+		remoteFin:     false,
+		remoteFinSn:   0,
+		closing:       false,
+		err:           nil,
+		readDeadline:  time.Time{},
+		writeDeadline: time.Time{},
+		writeLock:     sync.Mutex{},
+		outBuf:        make([]byte, 0, segmentHeaderSize+mss),
+		readable:      make(chan struct{}, 1),
+		writable:      make(chan struct{}, 1),
+		flushing:      make(chan struct{}, 1),
+		closed:        make(chan struct{}),
+		closeOnce:     sync.Once{},
+	}
+}
+
+func (c *Conn) start() {
+	go c.reading()
+	go c.updating()
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (c *Conn) timestamp(now time.Time) uint32 {
+	return uint32(now.Sub(c.epoch) / time.Millisecond)
+}
+
+// shutdown closes the connection with given error, all later operations
+// will get that error
+func (c *Conn) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.lock.Lock()
+		c.err = err
+		c.lock.Unlock()
+
+		close(c.closed)
+
+		c.conn.Close()
+	})
+}
+
+func (c *Conn) wait(ch chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+
+	if !deadline.IsZero() {
+		remain := time.Until(deadline)
+
+		if remain <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+
+		timer := time.NewTimer(remain)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+
+	case <-c.closed:
+		return nil
+	}
+}
+
+func (c *Conn) reading() {
+	buf := make([]byte, DatagramBufferSize)
+
+	for {
+		rLen, rErr := c.conn.Read(buf)
+
+		if rErr != nil {
+			c.shutdown(rErr)
+
+			return
+		}
+
+		c.lock.Lock()
+
+		if c.fecDecoder != nil {
+			c.fecDecoder.decode(buf[:rLen], c.input)
+		} else {
+			c.input(buf[:rLen])
+		}
+
+		c.lock.Unlock()
+	}
+}
+
+func (c *Conn) updating() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+
+		case <-ticker.C:
+		case <-c.flushing:
+		}
+
+		c.lock.Lock()
+		flushErr := c.flush(time.Now())
+		c.lock.Unlock()
+
+		if flushErr == nil {
+			continue
+		}
+
+		c.shutdown(flushErr)
+
+		return
+	}
+}
+
+// receiveWindow returns how many segments can still be received
+func (c *Conn) receiveWindow() uint16 {
+	used := len(c.rcvBuf) + (len(c.rcvQueue)+c.mss-1)/c.mss
+
+	if used >= int(c.window) {
+		return 0
+	}
+
+	return c.window - uint16(used)
+}
+
+func (c *Conn) eof() bool {
+	return c.remoteFin && !before(c.rcvNxt, c.remoteFinSn)
+}
+
+func (c *Conn) updateRTT(rtt time.Duration) {
+	if c.srtt == 0 {
+		c.srtt = rtt
+		c.rttvar = rtt / 2
+	} else {
+		delta := rtt - c.srtt
+
+		if delta < 0 {
+			delta = -delta
+		}
+
+		c.rttvar = (3*c.rttvar + delta) / 4
+		c.srtt = (7*c.srtt + rtt) / 8
+	}
+
+	variance := 4 * c.rttvar
+
+	if variance < flushInterval {
+		variance = flushInterval
+	}
+
+	c.rto = c.srtt + variance
+
+	if c.rto < c.minRTO {
+		c.rto = c.minRTO
+	}
+
+	if c.rto > maxRTO {
+		c.rto = maxRTO
+	}
+}
+
+// acknowledge removes all segments before una from the send buffer
+func (c *Conn) acknowledge(una uint32) {
+	removed := 0
+
+	for removed < len(c.sndBuf) && before(c.sndBuf[removed].sn, una) {
+		removed++
+	}
+
+	if removed <= 0 {
+		return
+	}
+
+	c.sndBuf = append(c.sndBuf[:0], c.sndBuf[removed:]...)
+}
+
+func (c *Conn) acked(sn uint32, ts uint32, now time.Time) {
+	for sIdx, seg := range c.sndBuf {
+		if before(seg.sn, sn) {
+			seg.fastack++
+
+			continue
+		}
+
+		if seg.sn != sn {
+			break
+		}
+
+		c.sndBuf = append(c.sndBuf[:sIdx], c.sndBuf[sIdx+1:]...)
+
+		break
+	}
+
+	current := c.timestamp(now)
+
+	if before(current, ts) {
+		return
+	}
+
+	c.updateRTT(time.Duration(current-ts) * time.Millisecond)
+}
+
+// input handles segments in a received datagram
+func (c *Conn) input(b []byte) {
+	now := time.Now()
+	sndBufLen := len(c.sndBuf)
+	rcvNxt := c.rcvNxt
+	remoteFin := c.remoteFin
+
+	for len(b) > 0 {
+		h, hErr := decodeSegmentHeader(b)
+
+		if hErr != nil {
+			break
+		}
+
+		data := b[segmentHeaderSize : segmentHeaderSize+int(h.length)]
+		b = b[segmentHeaderSize+int(h.length):]
+
+		if !c.convAdopted {
+			c.conv = h.conv
+			c.convAdopted = true
+		} else if h.conv != c.conv {
+			break
+		}
+
+		c.rmtWnd = h.wnd
+
+		c.acknowledge(h.una)
+
+		switch h.cmd {
+		case cmdAck:
+			c.acked(h.sn, h.ts, now)
+
+		case cmdPush:
+			if !before(h.sn, c.rcvNxt+uint32(c.window)) {
+				continue
+			}
+
+			c.acks = append(c.acks, ack{sn: h.sn, ts: h.ts})
+
+			if before(h.sn, c.rcvNxt) {
+				continue
+			}
+
+			if _, found := c.rcvBuf[h.sn]; found {
+				continue
+			}
+
+			c.rcvBuf[h.sn] = append(make([]byte, 0, len(data)), data...)
+
+		case cmdWindowAsk:
+			c.tellWindow = true
+
+		case cmdFin:
+			c.remoteFin = true
+			c.remoteFinSn = h.sn
+
+			// The peer only sends Fin after all of it's data has been
+			// acknowledged, so if nothing is received yet, the Fin must
+			// be a stale one from a previous connection
+			if c.rcvNxt == 0 && len(c.rcvBuf) == 0 {
+				c.remoteFinSn = c.rcvNxt
+			}
+		}
+	}
+
+	for {
+		data, found := c.rcvBuf[c.rcvNxt]
+
+		if !found {
+			break
+		}
+
+		delete(c.rcvBuf, c.rcvNxt)
+
+		c.rcvQueue = append(c.rcvQueue, data...)
+		c.rcvNxt++
+	}
+
+	if len(c.sndBuf) > 0 {
+		c.sndUna = c.sndBuf[0].sn
+	} else {
+		c.sndUna = c.sndNxt
+	}
+
+	if c.rcvNxt != rcvNxt || c.remoteFin != remoteFin {
+		signal(c.readable)
+	}
+
+	if len(c.sndBuf) != sndBufLen || c.remoteFin != remoteFin {
+		signal(c.writable)
+	}
+
+	if len(c.acks) > 0 || c.tellWindow {
+		signal(c.flushing)
+	}
+}
+
+// send sends a datagram
+func (c *Conn) send(b []byte) error {
+	if c.fecEncoder == nil {
+		_, wErr := c.conn.Write(b)
+
+		return wErr
+	}
+
+	return c.fecEncoder.encode(b, func(shard []byte) error {
+		_, wErr := c.conn.Write(shard)
+
+		return wErr
+	})
+}
+
+// output appends a segment to the output datagram, the datagram will be
+// sent when it has no room for the segment
+func (c *Conn) output(cmd uint8, sn uint32, ts uint32, data []byte) error {
+	if len(c.outBuf)+segmentHeaderSize+len(data) > cap(c.outBuf) {
+		sendErr := c.outputFlush()
+
+		if sendErr != nil {
+			return sendErr
+		}
+	}
+
+	wnd := c.receiveWindow()
+
+	c.windowZero = wnd == 0
+
+	c.outBuf = segmentHeader{
+		conv:   c.conv,
+		cmd:    cmd,
+		wnd:    wnd,
+		ts:     ts,
+		sn:     sn,
+		una:    c.rcvNxt,
+		length: uint16(len(data)),
+	}.encode(c.outBuf)
+
+	c.outBuf = append(c.outBuf, data...)
+
+	return nil
+}
+
+// outputFlush sends the output datagram
+func (c *Conn) outputFlush() error {
+	if len(c.outBuf) <= 0 {
+		return nil
+	}
+
+	sendErr := c.send(c.outBuf)
+
+	c.outBuf = c.outBuf[:0]
+
+	return sendErr
+}
+
+// flush sends acknowledgements, window probes, new segments and
+// retransmissions
+func (c *Conn) flush(now time.Time) error {
+	// Nothing needs to be sent once the peer is gone
+	if c.remoteFin {
+		return nil
+	}
+
+	current := c.timestamp(now)
+
+	for _, a := range c.acks {
+		outErr := c.output(cmdAck, a.sn, a.ts, nil)
+
+		if outErr != nil {
+			return outErr
+		}
+	}
+
+	c.acks = c.acks[:0]
+
+	if c.rmtWnd > 0 {
+		c.probeWait = 0
+	} else if c.probeWait <= 0 {
+		c.probeWait = probeInitialWait
+		c.probeAt = now.Add(c.probeWait)
+	} else if !now.Before(c.probeAt) {
+		c.probeWait += c.probeWait / 2
+
+		if c.probeWait > probeMaxWait {
+			c.probeWait = probeMaxWait
+		}
+
+		c.probeAt = now.Add(c.probeWait)
+
+		outErr := c.output(cmdWindowAsk, 0, current, nil)
+
+		if outErr != nil {
+			return outErr
+		}
+	}
+
+	if c.tellWindow {
+		c.tellWindow = false
+
+		outErr := c.output(cmdWindowTell, 0, current, nil)
+
+		if outErr != nil {
+			return outErr
+		}
+	}
+
+	sendWindow := uint32(c.window)
+
+	if uint32(c.rmtWnd) < sendWindow {
+		sendWindow = uint32(c.rmtWnd)
+	}
+
+	for len(c.sndQueue) > 0 && c.sndNxt-c.sndUna < sendWindow {
+		c.sndBuf = append(c.sndBuf, &segment{
+			sn:       c.sndNxt,
+			data:     c.sndQueue[0],
+			xmit:     0,
+			rto:      c.rto,
+			resendAt: now,
+			fastack:  0,
+		})
+
+		c.sndQueue[0] = nil
+		c.sndQueue = c.sndQueue[1:]
+		c.sndNxt++
+	}
+
+	for _, seg := range c.sndBuf {
+		switch {
+		case seg.xmit <= 0:
+			seg.rto = c.rto
+
+		case !now.Before(seg.resendAt):
+			seg.rto += seg.rto / 2
+
+			if seg.rto > maxRTO {
+				seg.rto = maxRTO
+			}
+
+		case c.fastResend > 0 && seg.fastack >= c.fastResend:
+
+		default:
+			continue
+		}
+
+		seg.xmit++
+		seg.fastack = 0
+		seg.resendAt = now.Add(seg.rto)
+
+		if seg.xmit > deadLink {
+			return ErrDeadLink
+		}
+
+		outErr := c.output(cmdPush, seg.sn, current, seg.data)
+
+		if outErr != nil {
+			return outErr
+		}
+	}
+
+	return c.outputFlush()
+}
+
+// Read reads data from the connection
+func (c *Conn) Read(b []byte) (int, error) {
+	for {
+		c.lock.Lock()
+
+		if len(c.rcvQueue) > 0 {
+			copied := copy(b, c.rcvQueue)
+
+			c.rcvQueue = c.rcvQueue[copied:]
+
+			// Tell the peer that the window is opened again, otherwise
+			// it will have to wait for the next probe
+			if c.windowZero && c.receiveWindow() > 0 {
+				c.tellWindow = true
+
+				signal(c.flushing)
+			}
+
+			c.lock.Unlock()
+
+			return copied, nil
+		}
+
+		if c.eof() {
+			c.lock.Unlock()
+
+			return 0, io.EOF
+		}
+
+		if c.err != nil {
+			err := c.err
+
+			c.lock.Unlock()
+
+			return 0, err
+		}
+
+		deadline := c.readDeadline
+
+		c.lock.Unlock()
+
+		waitErr := c.wait(c.readable, deadline)
+
+		if waitErr != nil {
+			return 0, waitErr
+		}
+	}
+}
+
+// Write writes data to the connection. It blocks when there are too many
+// segments waiting to be sent or acknowledged
+func (c *Conn) Write(b []byte) (int, error) {
+	// Hold the writeLock during the entire Write so data of concurrent
+	// Writes will not be interleaved
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	written := 0
+
+	for written < len(b) {
+		c.lock.Lock()
+
+		if c.err != nil {
+			err := c.err
+
+			c.lock.Unlock()
+
+			return written, err
+		}
+
+		if c.remoteFin {
+			c.lock.Unlock()
+
+			return written, ErrPeerClosed
+		}
+
+		limit := 2 * int(c.window)
+		appended := false
+
+		for written < len(b) {
+			last := len(c.sndQueue) - 1
+
+			if last < 0 || len(c.sndQueue[last]) >= c.mss {
+				if len(c.sndQueue)+len(c.sndBuf) >= limit {
+					break
+				}
+
+				c.sndQueue = append(c.sndQueue, make([]byte, 0, c.mss))
+
+				last++
+			}
+
+			room := c.mss - len(c.sndQueue[last])
+
+			if room > len(b)-written {
+				room = len(b) - written
+			}
+
+			c.sndQueue[last] = append(
+				c.sndQueue[last], b[written:written+room]...)
+
+			written += room
+			appended = true
+		}
+
+		deadline := c.writeDeadline
+
+		c.lock.Unlock()
+
+		if appended {
+			signal(c.flushing)
+
+			continue
+		}
+
+		waitErr := c.wait(c.writable, deadline)
+
+		if waitErr != nil {
+			return written, waitErr
+		}
+	}
+
+	return written, nil
+}
+
+// Close sends all remaining data and then closes the connection
+func (c *Conn) Close() error {
+	c.lock.Lock()
+
+	if c.closing {
+		c.lock.Unlock()
+
+		return ErrClosed
+	}
+
+	c.closing = true
+
+	c.lock.Unlock()
+
+	linger := time.Now().Add(closeLinger)
+
+	for {
+		c.lock.Lock()
+		pending := c.err == nil && !c.remoteFin &&
+			len(c.sndQueue)+len(c.sndBuf) > 0
+		c.lock.Unlock()
+
+		if !pending {
+			break
+		}
+
+		signal(c.flushing)
+
+		if c.wait(c.writable, linger) != nil {
+			break
+		}
+	}
+
+	c.lock.Lock()
+
+	if c.err == nil && !c.remoteFin {
+		// Fin is not retransmitted, so send it twice in the hope one of
+		// them reaches the peer. The idle timeout of the peer will handle
+		// the rest
+		finSn := c.sndNxt + uint32(len(c.sndQueue))
+
+		c.output(cmdFin, finSn, c.timestamp(time.Now()), nil)
+		c.output(cmdFin, finSn, c.timestamp(time.Now()), nil)
+		c.outputFlush()
+	}
+
+	c.lock.Unlock()
+
+	c.shutdown(ErrClosed)
+
+	return nil
+}
+
+// LocalAddr returns the local address of the datagram connection
+func (c *Conn) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote address of the datagram connection
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetDeadline sets both read and write deadlines
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.readDeadline = t
+	c.lock.Unlock()
+
+	signal(c.readable)
+
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.writeDeadline = t
+	c.lock.Unlock()
+
+	signal(c.writable)
+
+	return nil
+}
@@ -0,0 +1,332 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+type dummyAddr string
+
+func (d dummyAddr) Network() string {
+	return "dummy"
+}
+
+func (d dummyAddr) String() string {
+	return string(d)
+}
+
+// lossyConn is a datagram connection which drops some of the datagrams
+// it sends
+type lossyConn struct {
+	in        chan []byte
+	out       chan []byte
+	loss      float64
+	rand      *rand.Rand
+	randLock  sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (l *lossyConn) Read(b []byte) (int, error) {
+	select {
+	case d := <-l.in:
+		return copy(b, d), nil
+
+	case <-l.closed:
+		return 0, io.EOF
+	}
+}
+
+func (l *lossyConn) Write(b []byte) (int, error) {
+	l.randLock.Lock()
+	drop := l.rand.Float64() < l.loss
+	l.randLock.Unlock()
+
+	if drop {
+		return len(b), nil
+	}
+
+	select {
+	case l.out <- append([]byte{}, b...):
+	case <-l.closed:
+		return 0, io.EOF
+	default:
+	}
+
+	return len(b), nil
+}
+
+func (l *lossyConn) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.closed)
+	})
+
+	return nil
+}
+
+func (l *lossyConn) LocalAddr() net.Addr {
+	return dummyAddr("local")
+}
+
+func (l *lossyConn) RemoteAddr() net.Addr {
+	return dummyAddr("remote")
+}
+
+func (l *lossyConn) SetDeadline(t time.Time) error {
+	return nil
+}
+
+func (l *lossyConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (l *lossyConn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+func testLossyPipe(loss float64, cfg Config) (*Conn, *Conn) {
+	a2b := make(chan []byte, 1024)
+	b2a := make(chan []byte, 1024)
+
+	a := &lossyConn{
+		in:        b2a,
+		out:       a2b,
+		loss:      loss,
+		rand:      rand.New(rand.NewSource(1)),
+		randLock:  sync.Mutex{},
+		closed:    make(chan struct{}),
+		closeOnce: sync.Once{},
+	}
+
+	b := &lossyConn{
+		in:        a2b,
+		out:       b2a,
+		loss:      loss,
+		rand:      rand.New(rand.NewSource(2)),
+		randLock:  sync.Mutex{},
+		closed:    make(chan struct{}),
+		closeOnce: sync.Once{},
+	}
+
+	return Server(a, cfg), Client(b, cfg)
+}
+
+func testConnTransfer(t *testing.T, loss float64, cfg Config) {
+	server, client := testLossyPipe(loss, cfg)
+
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+
+		io.Copy(server, server)
+	}()
+
+	data := make([]byte, 512*1024)
+
+	for dIdx := range data {
+		data[dIdx] = byte(dIdx % 251)
+	}
+
+	go client.Write(data)
+
+	client.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+	received := make([]byte, len(data))
+
+	_, rErr := io.ReadFull(client, received)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(received, data) {
+		t.Error("Received data is different from sent")
+
+		return
+	}
+}
+
+func TestConnTransfer(t *testing.T) {
+	testConnTransfer(t, 0, Config{
+		Window:     DefaultWindow,
+		MinRTO:     DefaultMinRTO,
+		FastResend: DefaultFastResend,
+		FEC:        0,
+	})
+}
+
+func TestConnTransferLossy(t *testing.T) {
+	testConnTransfer(t, 0.05, Config{
+		Window:     DefaultWindow,
+		MinRTO:     DefaultMinRTO,
+		FastResend: DefaultFastResend,
+		FEC:        0,
+	})
+}
+
+func TestConnTransferLossyWithFEC(t *testing.T) {
+	testConnTransfer(t, 0.05, Config{
+		Window:     DefaultWindow,
+		MinRTO:     DefaultMinRTO,
+		FastResend: 0,
+		FEC:        4,
+	})
+}
+
+func TestConnClose(t *testing.T) {
+	server, client := testLossyPipe(0.05, Config{
+		Window:     DefaultWindow,
+		MinRTO:     DefaultMinRTO,
+		FastResend: DefaultFastResend,
+		FEC:        0,
+	})
+
+	defer server.Close()
+
+	data := bytes.Repeat([]byte("Hello World"), 10000)
+
+	go func() {
+		client.Write(data)
+		client.Close()
+	}()
+
+	server.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+	received := bytes.NewBuffer(make([]byte, 0, len(data)))
+
+	_, rErr := io.Copy(received, server)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(received.Bytes(), data) {
+		t.Error("Received data is different from sent")
+
+		return
+	}
+
+	_, wErr := server.Write([]byte("Hello World"))
+
+	if wErr != ErrPeerClosed {
+		t.Errorf("Expecting error %s, got %s", ErrPeerClosed, wErr)
+
+		return
+	}
+}
+
+func TestConnReadDeadline(t *testing.T) {
+	server, client := testLossyPipe(0, Config{
+		Window:     DefaultWindow,
+		MinRTO:     DefaultMinRTO,
+		FastResend: DefaultFastResend,
+		FEC:        0,
+	})
+
+	defer server.Close()
+	defer client.Close()
+
+	client.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+
+	_, rErr := client.Read(make([]byte, 1))
+
+	if rErr != os.ErrDeadlineExceeded {
+		t.Errorf("Expecting error %s, got %s", os.ErrDeadlineExceeded, rErr)
+
+		return
+	}
+}
+
+func TestFECRecover(t *testing.T) {
+	const shards = 4
+
+	payloads := [][]byte{
+		[]byte("Hello"),
+		[]byte("World, Hello"),
+		[]byte(""),
+		[]byte("Hi"),
+	}
+
+	for lost := range payloads {
+		encoder := newFECEncoder(shards)
+		decoder := newFECDecoder(shards)
+		encoded := make([][]byte, 0, shards+1)
+
+		for _, p := range payloads {
+			encoder.encode(p, func(b []byte) error {
+				encoded = append(encoded, b)
+
+				return nil
+			})
+		}
+
+		if len(encoded) != shards+1 {
+			t.Errorf("Expecting %d shards, got %d", shards+1, len(encoded))
+
+			return
+		}
+
+		delivered := make([][]byte, 0, shards)
+
+		for eIdx, e := range encoded {
+			if eIdx == lost {
+				continue
+			}
+
+			decErr := decoder.decode(e, func(b []byte) {
+				delivered = append(delivered, append([]byte{}, b...))
+			})
+
+			if decErr != nil {
+				t.Error("Failed to decode due to error:", decErr)
+
+				return
+			}
+		}
+
+		if len(delivered) != shards {
+			t.Errorf("Expecting %d payloads, got %d",
+				shards, len(delivered))
+
+			return
+		}
+
+		if !bytes.Equal(delivered[shards-1], payloads[lost]) {
+			t.Errorf("Expecting recovered payload %d to be %v, got %v",
+				lost, payloads[lost], delivered[shards-1])
+
+			return
+		}
+	}
+}
@@ -0,0 +1,260 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Errors
+var (
+	ErrFECShardInvalid = errors.New(
+		"ARQ FEC shard was invalid")
+)
+
+// Consts
+const (
+	fecHeaderSize = 5
+	fecMaxGroups  = 64
+)
+
+// fecEncoder wraps outgoing datagrams into FEC data shards, and sends
+// a XOR parity shard after every group of data shards.
+//
+// The parity shard is the XOR of all data shards in the group, each of
+// them prefixed with it's length, and padded to the longest one
+type fecEncoder struct {
+	shards    uint8
+	group     uint32
+	index     uint8
+	parity    []byte
+	parityLen int
+}
+
+func newFECEncoder(shards uint8) *fecEncoder {
+	return &fecEncoder{
+		shards:    shards,
+		group:     0,
+		index:     0,
+		parity:    make([]byte, 2+mtu),
+		parityLen: 0,
+	}
+}
+
+func fecXOR(dst []byte, src []byte) {
+	for sIdx := range src {
+		dst[sIdx] ^= src[sIdx]
+	}
+}
+
+func (e *fecEncoder) shard(index uint8, payload []byte) []byte {
+	shard := make([]byte, fecHeaderSize+len(payload))
+
+	binary.BigEndian.PutUint32(shard[0:4], e.group)
+	shard[4] = index
+	copy(shard[fecHeaderSize:], payload)
+
+	return shard
+}
+
+func (e *fecEncoder) encode(payload []byte, send func([]byte) error) error {
+	var length [2]byte
+
+	binary.BigEndian.PutUint16(length[:], uint16(len(payload)))
+
+	fecXOR(e.parity[0:2], length[:])
+	fecXOR(e.parity[2:], payload)
+
+	if 2+len(payload) > e.parityLen {
+		e.parityLen = 2 + len(payload)
+	}
+
+	sendErr := send(e.shard(e.index, payload))
+
+	if sendErr != nil {
+		return sendErr
+	}
+
+	e.index++
+
+	if e.index < e.shards {
+		return nil
+	}
+
+	parity := e.shard(e.shards, e.parity[:e.parityLen])
+
+	for pIdx := range e.parity[:e.parityLen] {
+		e.parity[pIdx] = 0
+	}
+
+	e.parityLen = 0
+	e.index = 0
+	e.group++
+
+	return send(parity)
+}
+
+// fecGroup is a group of received shards
+type fecGroup struct {
+	shards   [][]byte
+	received uint8
+	parity   []byte
+	done     bool
+}
+
+// fecDecoder unwraps incoming FEC shards, and recovers the lost data
+// shard of a group when all the other shards of it has been received
+type fecDecoder struct {
+	shards  uint8
+	groups  map[uint32]*fecGroup
+	latest  uint32
+	started bool
+}
+
+func newFECDecoder(shards uint8) *fecDecoder {
+	return &fecDecoder{
+		shards:  shards,
+		groups:  make(map[uint32]*fecGroup, fecMaxGroups),
+		latest:  0,
+		started: false,
+	}
+}
+
+func (d *fecDecoder) evict() {
+	for gID := range d.groups {
+		if d.latest-gID < fecMaxGroups {
+			continue
+		}
+
+		delete(d.groups, gID)
+	}
+}
+
+func (d *fecDecoder) decode(b []byte, deliver func([]byte)) error {
+	if len(b) < fecHeaderSize {
+		return ErrFECShardInvalid
+	}
+
+	groupID := binary.BigEndian.Uint32(b[0:4])
+	index := b[4]
+	payload := b[fecHeaderSize:]
+
+	if index > d.shards {
+		return ErrFECShardInvalid
+	}
+
+	if index < d.shards {
+		deliver(payload)
+	}
+
+	switch {
+	case !d.started || before(d.latest, groupID):
+		d.started = true
+		d.latest = groupID
+
+		d.evict()
+
+	case d.latest-groupID >= fecMaxGroups:
+		return nil
+	}
+
+	group, found := d.groups[groupID]
+
+	if !found {
+		group = &fecGroup{
+			shards:   make([][]byte, d.shards),
+			received: 0,
+			parity:   nil,
+			done:     false,
+		}
+
+		d.groups[groupID] = group
+	}
+
+	if group.done {
+		return nil
+	}
+
+	if index == d.shards {
+		if group.parity != nil {
+			return nil
+		}
+
+		group.parity = append([]byte{}, payload...)
+	} else {
+		if group.shards[index] != nil {
+			return nil
+		}
+
+		group.shards[index] = append([]byte{}, payload...)
+		group.received++
+	}
+
+	if group.received >= d.shards {
+		group.done = true
+		group.shards = nil
+		group.parity = nil
+
+		return nil
+	}
+
+	if group.parity == nil || group.received+1 < d.shards {
+		return nil
+	}
+
+	recovered := group.parity
+	group.done = true
+	group.parity = nil
+
+	for _, shard := range group.shards {
+		if shard == nil {
+			continue
+		}
+
+		if 2+len(shard) > len(recovered) {
+			return ErrFECShardInvalid
+		}
+
+		var length [2]byte
+
+		binary.BigEndian.PutUint16(length[:], uint16(len(shard)))
+
+		fecXOR(recovered[0:2], length[:])
+		fecXOR(recovered[2:], shard)
+	}
+
+	group.shards = nil
+
+	if len(recovered) < 2 {
+		return ErrFECShardInvalid
+	}
+
+	recoveredLen := int(binary.BigEndian.Uint16(recovered[0:2]))
+
+	if recoveredLen > len(recovered)-2 {
+		return ErrFECShardInvalid
+	}
+
+	deliver(recovered[2 : 2+recoveredLen])
+
+	return nil
+}
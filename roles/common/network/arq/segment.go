@@ -0,0 +1,115 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// Errors
+var (
+	ErrSegmentInvalid = errors.New(
+		"ARQ segment was invalid")
+)
+
+// Consts
+const (
+	mtu               = 1400
+	segmentHeaderSize = 21
+	initialRTO        = 200 * time.Millisecond
+	maxRTO            = 60 * time.Second
+	flushInterval     = 10 * time.Millisecond
+	probeInitialWait  = 1 * time.Second
+	probeMaxWait      = 10 * time.Second
+	deadLink          = 20
+	closeLinger       = 3 * time.Second
+
+	cmdPush       uint8 = 1
+	cmdAck        uint8 = 2
+	cmdWindowAsk  uint8 = 3
+	cmdWindowTell uint8 = 4
+	cmdFin        uint8 = 5
+)
+
+// segmentHeader is the header of a segment. Multiple segments can be
+// sent in one datagram
+type segmentHeader struct {
+	conv   uint32
+	cmd    uint8
+	wnd    uint16
+	ts     uint32
+	sn     uint32
+	una    uint32
+	length uint16
+}
+
+func (h segmentHeader) encode(b []byte) []byte {
+	var buf [segmentHeaderSize]byte
+
+	binary.BigEndian.PutUint32(buf[0:4], h.conv)
+	buf[4] = h.cmd
+	binary.BigEndian.PutUint16(buf[5:7], h.wnd)
+	binary.BigEndian.PutUint32(buf[7:11], h.ts)
+	binary.BigEndian.PutUint32(buf[11:15], h.sn)
+	binary.BigEndian.PutUint32(buf[15:19], h.una)
+	binary.BigEndian.PutUint16(buf[19:21], h.length)
+
+	return append(b, buf[:]...)
+}
+
+func decodeSegmentHeader(b []byte) (segmentHeader, error) {
+	if len(b) < segmentHeaderSize {
+		return segmentHeader{}, ErrSegmentInvalid
+	}
+
+	h := segmentHeader{
+		conv:   binary.BigEndian.Uint32(b[0:4]),
+		cmd:    b[4],
+		wnd:    binary.BigEndian.Uint16(b[5:7]),
+		ts:     binary.BigEndian.Uint32(b[7:11]),
+		sn:     binary.BigEndian.Uint32(b[11:15]),
+		una:    binary.BigEndian.Uint32(b[15:19]),
+		length: binary.BigEndian.Uint16(b[19:21]),
+	}
+
+	if int(h.length) > len(b)-segmentHeaderSize {
+		return segmentHeader{}, ErrSegmentInvalid
+	}
+
+	return h, nil
+}
+
+// segment is an unacknowledged outgoing segment
+type segment struct {
+	sn       uint32
+	data     []byte
+	xmit     uint32
+	rto      time.Duration
+	resendAt time.Time
+	fastack  uint32
+}
+
+// before returns whether or not sequence number a is before b
+func before(a, b uint32) bool {
+	return int32(a-b) < 0
+}
@@ -0,0 +1,813 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package bond
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/rw"
+)
+
+// Errors
+var (
+	ErrClosed = errors.New(
+		"Bonded connection is closed")
+
+	ErrNoLinkAvailable = errors.New(
+		"No link of the bonded connection is available")
+
+	ErrFrameInvalid = errors.New(
+		"Bonded connection has received an invalid frame")
+
+	ErrTooManyLinks = errors.New(
+		"Too many links for the bonded connection")
+)
+
+// Consts
+const (
+	// IDSize is the size of the ID of a bonded connection
+	IDSize = 16
+
+	// MaxLinks is the max amount of links a bonded connection can have
+	MaxLinks = 16
+)
+
+// Consts
+const (
+	frameHeaderSize = 7
+	frameMaxData    = 16 * 1024
+	window          = 4 * 1024 * 1024
+	ackEvery        = 8
+	ackInterval     = 50 * time.Millisecond
+	closeLinger     = 3 * time.Second
+
+	// The sender stops sending once there are window bytes waiting to be
+	// acknowledged, so no more than this can be in flight from a peer
+	// that behaves
+	rcvBufMax = window + frameMaxData
+
+	frameData  byte = 1
+	frameAck   byte = 2
+	frameClose byte = 3
+)
+
+// ID is the ID of a bonded connection, every link of the connection
+// sends it as hello so they can be bonded together by the remote
+type ID [IDSize]byte
+
+// frame is a frame sent through a link
+type frame struct {
+	kind byte
+	seq  uint32
+	data []byte
+	link *link
+}
+
+// link is one of the underlying connections of a bonded connection
+type link struct {
+	conn   net.Conn
+	queue  []*frame
+	queued int
+	signal chan struct{}
+	failed bool
+}
+
+// Conn is a bonded connection which stripes a byte stream across
+// multiple underlying connections (links) as sequenced frames, and
+// reorders them on receive.
+//
+// Frames are sent through the link that has least data waiting to be
+// sent, so faster links will carry more data. Frames that have been
+// sent through a broken link but not yet acknowledged will be resent
+// through the remaining links
+type Conn struct {
+	lock           sync.Mutex
+	writeLock      sync.Mutex
+	links          []*link
+	sndNext        uint32
+	unacked        []*frame
+	unackedBytes   int
+	rcvNext        uint32
+	rcvBuf         map[uint32][]byte
+	rcvBufBytes    int
+	rcvQueue       []byte
+	acksDue        int
+	ackHeld        bool
+	remoteClose    bool
+	remoteCloseSeq uint32
+	closing        bool
+	err            error
+	readDeadline   time.Time
+	writeDeadline  time.Time
+	localAddr      net.Addr
+	remoteAddr     net.Addr
+	readable       chan struct{}
+	writable       chan struct{}
+	closed         chan struct{}
+	closeOnce      sync.Once
+	onClose        func()
+}
+
+// NewID generates a random ID
+func NewID() (ID, error) {
+	id := ID{}
+
+	_, rErr := rand.Read(id[:])
+
+	return id, rErr
+}
+
+// WriteHello sends the ID through a link
+func WriteHello(conn net.Conn, id ID) error {
+	_, wErr := conn.Write(id[:])
+
+	return wErr
+}
+
+// ReadHello reads the ID from a link
+func ReadHello(conn net.Conn) (ID, error) {
+	id := ID{}
+
+	_, rErr := io.ReadFull(conn, id[:])
+
+	return id, rErr
+}
+
+// coded is a link which runs through a Codec
+type coded struct {
+	net.Conn
+
+	reader io.Reader
+	writer io.Writer
+}
+
+// Coded returns a link which encodes and decodes all of it's data with
+// the Codec, so the link can not be attached or injected by anyone who
+// doesn't have the key of the Codec
+func Coded(conn net.Conn, cc rw.Codec) net.Conn {
+	return coded{
+		Conn:   conn,
+		reader: cc.Decode(conn),
+		writer: cc.Encode(conn),
+	}
+}
+
+// Read reads and decodes data from the link
+func (c coded) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+// Write encodes and writes data to the link
+func (c coded) Write(b []byte) (int, error) {
+	return c.writer.Write(b)
+}
+
+// New creates a bonded connection on given links, more links can be
+// attached later through Attach. onClose will be called once after the
+// connection is closed
+func New(links []net.Conn, onClose func()) *Conn {
+	c := &Conn{
+		lock:           sync.Mutex{},
+		writeLock:      sync.Mutex{},
+		links:          make([]*link, 0, len(links)),
+		sndNext:        0,
+		unacked:        make([]*frame, 0, 64),
+		unackedBytes:   0,
+		rcvNext:        0,
+		rcvBuf:         make(map[uint32][]byte, 64),
+		rcvBufBytes:    0,
+		rcvQueue:       nil,
+		acksDue:        0,
+		ackHeld:        false,
+		remoteClose:    false,
+		remoteCloseSeq: 0,
+		closing:        false,
+		err:            nil,
+		readDeadline:   time.Time{},
+		writeDeadline:  time.Time{},
+		localAddr:      links[0].LocalAddr(),
+		remoteAddr:     links[0].RemoteAddr(),
+		readable:       make(chan struct{}, 1),
+		writable:       make(chan struct{}, 1),
+		closed:         make(chan struct{}),
+		closeOnce:      sync.Once{},
+		onClose:        onClose,
+	}
+
+	for lIdx := range links {
+		c.Attach(links[lIdx])
+	}
+
+	go c.ticking()
+
+	return c
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func before(a, b uint32) bool {
+	return int32(a-b) < 0
+}
+
+// Attach adds a link to the connection
+func (c *Conn) Attach(conn net.Conn) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+
+	if len(c.links) >= MaxLinks {
+		return ErrTooManyLinks
+	}
+
+	l := &link{
+		conn:   conn,
+		queue:  make([]*frame, 0, 64),
+		queued: 0,
+		signal: make(chan struct{}, 1),
+		failed: false,
+	}
+
+	c.links = append(c.links, l)
+
+	go c.reading(l)
+	go c.writing(l)
+
+	return nil
+}
+
+// Links returns how many links are currently alive
+func (c *Conn) Links() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return len(c.links)
+}
+
+// shutdown closes the connection with given error, all later operations
+// will get that error
+func (c *Conn) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.lock.Lock()
+
+		c.err = err
+
+		for _, l := range c.links {
+			l.failed = true
+			l.conn.Close()
+		}
+
+		c.links = nil
+
+		c.lock.Unlock()
+
+		close(c.closed)
+
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+}
+
+// linkFailed removes a broken link, and resends the data frames that
+// were sent through it but not yet acknowledged
+func (c *Conn) linkFailed(l *link, err error) {
+	c.lock.Lock()
+
+	if l.failed {
+		c.lock.Unlock()
+
+		return
+	}
+
+	l.failed = true
+	l.queue = nil
+	l.queued = 0
+
+	l.conn.Close()
+
+	for lIdx := range c.links {
+		if c.links[lIdx] != l {
+			continue
+		}
+
+		c.links = append(c.links[:lIdx], c.links[lIdx+1:]...)
+
+		break
+	}
+
+	if len(c.links) > 0 {
+		for _, f := range c.unacked {
+			if f.link != l {
+				continue
+			}
+
+			c.enqueue(f, false)
+		}
+
+		c.lock.Unlock()
+
+		return
+	}
+
+	c.lock.Unlock()
+
+	c.shutdown(err)
+}
+
+// enqueue queues a frame to the link which has least data waiting to be
+// sent
+func (c *Conn) enqueue(f *frame, urgent bool) error {
+	var selected *link
+
+	for _, l := range c.links {
+		if selected != nil && selected.queued <= l.queued {
+			continue
+		}
+
+		selected = l
+	}
+
+	if selected == nil {
+		return ErrNoLinkAvailable
+	}
+
+	if f.kind == frameData {
+		f.link = selected
+	}
+
+	c.enqueueTo(selected, f, urgent)
+
+	return nil
+}
+
+func (c *Conn) enqueueTo(l *link, f *frame, urgent bool) {
+	if urgent {
+		l.queue = append([]*frame{f}, l.queue...)
+	} else {
+		l.queue = append(l.queue, f)
+	}
+
+	l.queued += len(f.data)
+
+	signal(l.signal)
+}
+
+// ack acknowledges all received data frames, unless too much of them are
+// still waiting to be read
+func (c *Conn) ack() {
+	if len(c.rcvQueue) >= window {
+		c.ackHeld = true
+
+		return
+	}
+
+	c.ackHeld = false
+	c.acksDue = 0
+
+	c.enqueue(&frame{
+		kind: frameAck,
+		seq:  c.rcvNext,
+		data: nil,
+		link: nil,
+	}, true)
+}
+
+func (c *Conn) ticking() {
+	ticker := time.NewTicker(ackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.closed:
+			return
+
+		case <-ticker.C:
+		}
+
+		c.lock.Lock()
+
+		if c.acksDue > 0 || c.ackHeld {
+			c.ack()
+		}
+
+		c.lock.Unlock()
+	}
+}
+
+func (c *Conn) writing(l *link) {
+	for {
+		select {
+		case <-l.signal:
+		case <-c.closed:
+			return
+		}
+
+		for {
+			c.lock.Lock()
+
+			if l.failed || len(l.queue) <= 0 {
+				c.lock.Unlock()
+
+				break
+			}
+
+			f := l.queue[0]
+
+			l.queue[0] = nil
+			l.queue = l.queue[1:]
+			l.queued -= len(f.data)
+
+			c.lock.Unlock()
+
+			buf := make([]byte, frameHeaderSize+len(f.data))
+
+			buf[0] = f.kind
+			binary.BigEndian.PutUint32(buf[1:5], f.seq)
+			binary.BigEndian.PutUint16(buf[5:7], uint16(len(f.data)))
+			copy(buf[frameHeaderSize:], f.data)
+
+			_, wErr := l.conn.Write(buf)
+
+			if wErr == nil {
+				continue
+			}
+
+			c.linkFailed(l, wErr)
+
+			return
+		}
+	}
+}
+
+func (c *Conn) reading(l *link) {
+	header := [frameHeaderSize]byte{}
+
+	for {
+		_, rErr := io.ReadFull(l.conn, header[:])
+
+		if rErr != nil {
+			c.linkFailed(l, rErr)
+
+			return
+		}
+
+		kind := header[0]
+		seq := binary.BigEndian.Uint32(header[1:5])
+		data := make([]byte, binary.BigEndian.Uint16(header[5:7]))
+
+		_, rErr = io.ReadFull(l.conn, data)
+
+		if rErr != nil {
+			c.linkFailed(l, rErr)
+
+			return
+		}
+
+		c.lock.Lock()
+		inErr := c.input(kind, seq, data)
+		c.lock.Unlock()
+
+		if inErr == nil {
+			continue
+		}
+
+		c.linkFailed(l, inErr)
+
+		return
+	}
+}
+
+// input handles a received frame
+func (c *Conn) input(kind byte, seq uint32, data []byte) error {
+	switch kind {
+	case frameData:
+		c.acksDue++
+
+		if before(seq, c.rcvNext) {
+			break
+		}
+
+		if _, found := c.rcvBuf[seq]; found {
+			break
+		}
+
+		if len(data) <= 0 || len(data) > frameMaxData ||
+			seq-c.rcvNext >= rcvBufMax ||
+			c.rcvBufBytes+len(data) > rcvBufMax {
+			return ErrFrameInvalid
+		}
+
+		c.rcvBuf[seq] = data
+		c.rcvBufBytes += len(data)
+
+		for {
+			d, found := c.rcvBuf[c.rcvNext]
+
+			if !found {
+				break
+			}
+
+			delete(c.rcvBuf, c.rcvNext)
+
+			c.rcvBufBytes -= len(d)
+
+			c.rcvQueue = append(c.rcvQueue, d...)
+			c.rcvNext++
+		}
+
+		signal(c.readable)
+
+	case frameAck:
+		acked := 0
+
+		for acked < len(c.unacked) && before(c.unacked[acked].seq, seq) {
+			c.unackedBytes -= len(c.unacked[acked].data)
+			c.unacked[acked] = nil
+
+			acked++
+		}
+
+		c.unacked = c.unacked[acked:]
+
+		signal(c.writable)
+
+	case frameClose:
+		c.remoteClose = true
+		c.remoteCloseSeq = seq
+
+		signal(c.readable)
+
+	default:
+		return ErrFrameInvalid
+	}
+
+	if c.acksDue >= ackEvery {
+		c.ack()
+	}
+
+	return nil
+}
+
+func (c *Conn) wait(ch chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+
+	if !deadline.IsZero() {
+		remain := time.Until(deadline)
+
+		if remain <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+
+		timer := time.NewTimer(remain)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-ch:
+		return nil
+
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+
+	case <-c.closed:
+		return nil
+	}
+}
+
+func (c *Conn) eof() bool {
+	return c.remoteClose && !before(c.rcvNext, c.remoteCloseSeq)
+}
+
+// Read reads data from the connection
+func (c *Conn) Read(b []byte) (int, error) {
+	for {
+		c.lock.Lock()
+
+		if len(c.rcvQueue) > 0 {
+			copied := copy(b, c.rcvQueue)
+
+			c.rcvQueue = c.rcvQueue[copied:]
+
+			if c.ackHeld && len(c.rcvQueue) < window {
+				c.ack()
+			}
+
+			c.lock.Unlock()
+
+			return copied, nil
+		}
+
+		if c.eof() {
+			c.lock.Unlock()
+
+			return 0, io.EOF
+		}
+
+		if c.err != nil {
+			err := c.err
+
+			c.lock.Unlock()
+
+			return 0, err
+		}
+
+		deadline := c.readDeadline
+
+		c.lock.Unlock()
+
+		waitErr := c.wait(c.readable, deadline)
+
+		if waitErr != nil {
+			return 0, waitErr
+		}
+	}
+}
+
+// Write writes data to the connection. It blocks when there are too much
+// data waiting to be acknowledged
+func (c *Conn) Write(b []byte) (int, error) {
+	// Hold the writeLock during the entire Write so data of concurrent
+	// Writes will not be interleaved
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	written := 0
+
+	for written < len(b) {
+		c.lock.Lock()
+
+		if c.err != nil {
+			err := c.err
+
+			c.lock.Unlock()
+
+			return written, err
+		}
+
+		if c.unackedBytes >= window {
+			deadline := c.writeDeadline
+
+			c.lock.Unlock()
+
+			waitErr := c.wait(c.writable, deadline)
+
+			if waitErr != nil {
+				return written, waitErr
+			}
+
+			continue
+		}
+
+		size := len(b) - written
+
+		if size > frameMaxData {
+			size = frameMaxData
+		}
+
+		f := &frame{
+			kind: frameData,
+			seq:  c.sndNext,
+			data: append(make([]byte, 0, size), b[written:written+size]...),
+			link: nil,
+		}
+
+		enqErr := c.enqueue(f, false)
+
+		if enqErr != nil {
+			c.lock.Unlock()
+
+			return written, enqErr
+		}
+
+		c.sndNext++
+		c.unacked = append(c.unacked, f)
+		c.unackedBytes += size
+
+		c.lock.Unlock()
+
+		written += size
+	}
+
+	return written, nil
+}
+
+// Close waits for all sent data to be acknowledged, and then closes the
+// connection and all of it's links
+func (c *Conn) Close() error {
+	c.lock.Lock()
+
+	if c.closing {
+		c.lock.Unlock()
+
+		return ErrClosed
+	}
+
+	c.closing = true
+
+	// Send the Close through all links, so the peer can learn about it
+	// even when some of the links are broken
+	for _, l := range c.links {
+		c.enqueueTo(l, &frame{
+			kind: frameClose,
+			seq:  c.sndNext,
+			data: nil,
+			link: nil,
+		}, false)
+	}
+
+	c.lock.Unlock()
+
+	linger := time.Now().Add(closeLinger)
+
+	for {
+		c.lock.Lock()
+		pending := c.err == nil && len(c.unacked) > 0
+		c.lock.Unlock()
+
+		if !pending {
+			break
+		}
+
+		if c.wait(c.writable, linger) != nil {
+			break
+		}
+	}
+
+	c.shutdown(ErrClosed)
+
+	return nil
+}
+
+// LocalAddr returns the local address of the first link
+func (c *Conn) LocalAddr() net.Addr {
+	return c.localAddr
+}
+
+// RemoteAddr returns the remote address of the first link
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+// SetDeadline sets both read and write deadlines
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.SetReadDeadline(t)
+
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.readDeadline = t
+	c.lock.Unlock()
+
+	signal(c.readable)
+
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.lock.Lock()
+	c.writeDeadline = t
+	c.lock.Unlock()
+
+	signal(c.writable)
+
+	return nil
+}
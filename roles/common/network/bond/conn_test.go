@@ -0,0 +1,238 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package bond
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+)
+
+func testBondPipe(links int) (*Conn, *Conn, []net.Conn) {
+	aLinks := make([]net.Conn, links)
+	bLinks := make([]net.Conn, links)
+
+	for lIdx := range aLinks {
+		aLinks[lIdx], bLinks[lIdx] = net.Pipe()
+	}
+
+	return New(aLinks, nil), New(bLinks, nil), aLinks
+}
+
+func testRandomData(size int) []byte {
+	data := make([]byte, size)
+
+	rand.New(rand.NewSource(1)).Read(data)
+
+	return data
+}
+
+func TestConnTransfer(t *testing.T) {
+	a, b, _ := testBondPipe(3)
+
+	defer a.Close()
+	defer b.Close()
+
+	data := testRandomData(2 * 1024 * 1024)
+	result := make(chan []byte, 2)
+
+	for _, c := range []*Conn{a, b} {
+		go func(c *Conn) {
+			go c.Write(data)
+
+			received := make([]byte, len(data))
+
+			io.ReadFull(c, received)
+
+			result <- received
+		}(c)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case received := <-result:
+			if !bytes.Equal(received, data) {
+				t.Error("Received data is not the same as the sent one")
+
+				return
+			}
+
+		case <-time.After(10 * time.Second):
+			t.Error("Transfer timed out")
+
+			return
+		}
+	}
+}
+
+func TestConnLinkDrop(t *testing.T) {
+	a, b, aLinks := testBondPipe(3)
+
+	defer a.Close()
+	defer b.Close()
+
+	data := testRandomData(4 * 1024 * 1024)
+
+	go a.Write(data)
+
+	received := make([]byte, len(data))
+
+	_, rErr := io.ReadFull(b, received[:len(data)/4])
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	aLinks[1].Close()
+
+	b.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	_, rErr = io.ReadFull(b, received[len(data)/4:])
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(received, data) {
+		t.Error("Received data is not the same as the sent one")
+
+		return
+	}
+
+	for i := 0; i < 100 && a.Links() != 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if a.Links() != 2 {
+		t.Errorf("Expecting 2 links remaining, got %d", a.Links())
+
+		return
+	}
+}
+
+func TestConnClose(t *testing.T) {
+	a, b, _ := testBondPipe(2)
+
+	defer b.Close()
+
+	data := testRandomData(256 * 1024)
+
+	go func() {
+		a.Write(data)
+		a.Close()
+	}()
+
+	b.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	received, rErr := io.ReadAll(b)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(received, data) {
+		t.Error("Received data is not the same as the sent one")
+
+		return
+	}
+}
+
+func TestConnAllLinksDown(t *testing.T) {
+	a, b, aLinks := testBondPipe(2)
+
+	defer a.Close()
+	defer b.Close()
+
+	for _, l := range aLinks {
+		l.Close()
+	}
+
+	b.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	_, rErr := b.Read(make([]byte, 1))
+
+	if rErr == nil || rErr == ErrClosed {
+		t.Error("Expecting a link error, got:", rErr)
+
+		return
+	}
+}
+
+func TestConnMaxLinks(t *testing.T) {
+	a, b, _ := testBondPipe(MaxLinks)
+
+	defer a.Close()
+	defer b.Close()
+
+	extra, _ := net.Pipe()
+
+	defer extra.Close()
+
+	attachErr := a.Attach(extra)
+
+	if attachErr != ErrTooManyLinks {
+		t.Errorf("Expecting error %s, got %s", ErrTooManyLinks, attachErr)
+
+		return
+	}
+}
+
+func TestConnFrameOutOfWindow(t *testing.T) {
+	local, remote := net.Pipe()
+	spare, spareRemote := net.Pipe()
+
+	defer remote.Close()
+	defer spareRemote.Close()
+
+	go io.Copy(io.Discard, spareRemote)
+
+	c := New([]net.Conn{local, spare}, nil)
+
+	defer c.Close()
+
+	header := [frameHeaderSize]byte{}
+
+	header[0] = frameData
+	binary.BigEndian.PutUint32(header[1:5], rcvBufMax)
+	binary.BigEndian.PutUint16(header[5:7], 1)
+
+	remote.Write(append(header[:], 0))
+
+	for i := 0; i < 100 && c.Links() != 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if c.Links() != 1 {
+		t.Errorf("Expecting 1 link remaining, got %d", c.Links())
+
+		return
+	}
+}
@@ -78,6 +78,7 @@ type Server interface {
 // Serving represents a running Server
 type Serving interface {
 	Listening() net.Addr
+	Health() error
 	Close() error
 }
 
@@ -0,0 +1,112 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/arq"
+)
+
+type dialer struct {
+	host        string
+	port        uint16
+	timeout     time.Duration
+	config      arq.Config
+	connWrapper network.ConnectionWrapper
+}
+
+type dial struct {
+	resolved    net.IP
+	useResolved bool
+	host        string
+	port        uint16
+	timeout     time.Duration
+	config      arq.Config
+	connWrapper network.ConnectionWrapper
+}
+
+// New returns a new reliable UDP Dialer
+func New(
+	host string,
+	port uint16,
+	timeout time.Duration,
+	config arq.Config,
+	connWrapper network.ConnectionWrapper,
+) network.Dialer {
+	return dialer{
+		host:        host,
+		port:        port,
+		timeout:     timeout,
+		config:      config,
+		connWrapper: connWrapper,
+	}
+}
+
+func (d dialer) Dialer() network.Dial {
+	return &dial{
+		resolved:    nil,
+		useResolved: false,
+		host:        d.host,
+		port:        d.port,
+		timeout:     d.timeout,
+		config:      d.config,
+		connWrapper: d.connWrapper,
+	}
+}
+
+func (d *dial) resolvedAddress() string {
+	var address string
+
+	if d.resolved != nil && d.useResolved {
+		address = net.JoinHostPort(
+			d.resolved.String(), strconv.FormatUint(uint64(d.port), 10))
+	} else {
+		address = net.JoinHostPort(
+			d.host, strconv.FormatUint(uint64(d.port), 10))
+	}
+
+	return address
+}
+
+// Dial creates a new reliable UDP connection. As there is no handshake,
+// the remote will only be aware of the connection once data is written
+func (d *dial) Dial() (network.Connection, error) {
+	dialed, dialErr := net.DialTimeout("udp", d.resolvedAddress(), d.timeout)
+
+	if dialErr != nil {
+		d.useResolved = !d.useResolved
+
+		return nil, dialErr
+	}
+
+	d.useResolved = true
+	d.resolved = dialed.RemoteAddr().(*net.UDPAddr).IP
+
+	return d.connWrapper(arq.Client(dialed, d.config)), nil
+}
+
+func (d *dial) String() string {
+	return d.resolvedAddress()
+}
@@ -0,0 +1,176 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package bond
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/bond"
+	"github.com/reinit/coward/roles/common/transceiver"
+	"github.com/reinit/coward/roles/common/transceiver/connection"
+)
+
+type dialer struct {
+	links       []network.Dialer
+	codec       transceiver.CodecBuilder
+	timeout     time.Duration
+	connWrapper network.ConnectionWrapper
+}
+
+type dial struct {
+	links       []network.Dial
+	codec       transceiver.CodecBuilder
+	timeout     time.Duration
+	connWrapper network.ConnectionWrapper
+}
+
+// New returns a new bonding Dialer. Every Dial will dial all links and
+// bond the successfully established ones into one connection. Links are
+// encoded with the codec, and must get their hello sent within timeout
+func New(
+	links []network.Dialer,
+	codec transceiver.CodecBuilder,
+	timeout time.Duration,
+	connWrapper network.ConnectionWrapper,
+) network.Dialer {
+	return dialer{
+		links:       links,
+		codec:       codec,
+		timeout:     timeout,
+		connWrapper: connWrapper,
+	}
+}
+
+func (d dialer) Dialer() network.Dial {
+	links := make([]network.Dial, len(d.links))
+
+	for lIdx := range d.links {
+		links[lIdx] = d.links[lIdx].Dialer()
+	}
+
+	return &dial{
+		links:       links,
+		codec:       d.codec,
+		timeout:     d.timeout,
+		connWrapper: d.connWrapper,
+	}
+}
+
+// Dial dials all links at the same time. It only fails when none of the
+// links can be established
+func (d *dial) Dial() (network.Connection, error) {
+	id, idErr := bond.NewID()
+
+	if idErr != nil {
+		return nil, idErr
+	}
+
+	conns := make([]net.Conn, len(d.links))
+	errs := make([]error, len(d.links))
+	wg := sync.WaitGroup{}
+
+	for lIdx := range d.links {
+		wg.Add(1)
+
+		go func(lIdx int) {
+			defer wg.Done()
+
+			conn, dialErr := d.links[lIdx].Dial()
+
+			if dialErr != nil {
+				errs[lIdx] = dialErr
+
+				return
+			}
+
+			link, helloErr := d.hello(conn, id)
+
+			if helloErr != nil {
+				conn.Close()
+
+				errs[lIdx] = helloErr
+
+				return
+			}
+
+			conns[lIdx] = link
+		}(lIdx)
+	}
+
+	wg.Wait()
+
+	established := make([]net.Conn, 0, len(conns))
+
+	var lastErr error
+
+	for lIdx := range conns {
+		if conns[lIdx] == nil {
+			lastErr = errs[lIdx]
+
+			continue
+		}
+
+		established = append(established, conns[lIdx])
+	}
+
+	if len(established) <= 0 {
+		return nil, lastErr
+	}
+
+	return d.connWrapper(bond.New(established, nil)), nil
+}
+
+// hello sets up the codec on the link and sends the ID through it
+func (d *dial) hello(conn network.Connection, id bond.ID) (net.Conn, error) {
+	conn.SetDeadline(time.Now().Add(d.timeout))
+
+	cc, ccErr := connection.Codec(d.codec, conn, true, d.timeout)
+
+	if ccErr != nil {
+		return nil, ccErr
+	}
+
+	link := bond.Coded(conn, cc)
+
+	helloErr := bond.WriteHello(link, id)
+
+	if helloErr != nil {
+		return nil, helloErr
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	return link, nil
+}
+
+func (d *dial) String() string {
+	links := make([]string, len(d.links))
+
+	for lIdx := range d.links {
+		links[lIdx] = d.links[lIdx].String()
+	}
+
+	return strings.Join(links, "+")
+}
@@ -0,0 +1,123 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package hop
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+
+	"github.com/reinit/coward/roles/common/codec/key"
+	"github.com/reinit/coward/roles/common/network"
+)
+
+// Consts
+const (
+	// DefaultWindow is the default length of the time period
+	DefaultWindow = 60 * time.Second
+)
+
+type dialer struct {
+	ports   []network.Dialer
+	key     key.Key
+	counter *uint32
+}
+
+type dial struct {
+	ports   []network.Dial
+	key     key.Key
+	counter *uint32
+	current int
+}
+
+// New returns a new port hopping Dialer. Each Dial will pick one of the
+// ports according to the shared key, the current time period and how many
+// connections has been dialed, so successive connections will be spread
+// across the ports, and the picks changes every time period
+func New(
+	ports []network.Dialer,
+	sharedKey []byte,
+	window time.Duration,
+) network.Dialer {
+	return dialer{
+		ports:   ports,
+		key:     key.Timed(sharedKey, window, 0, time.Now),
+		counter: new(uint32),
+	}
+}
+
+func (d dialer) Dialer() network.Dial {
+	ports := make([]network.Dial, len(d.ports))
+
+	for pIdx := range d.ports {
+		ports[pIdx] = d.ports[pIdx].Dialer()
+	}
+
+	return &dial{
+		ports:   ports,
+		key:     d.key,
+		counter: d.counter,
+		current: 0,
+	}
+}
+
+// pick selects the port for the next connection
+func (d *dial) pick() (int, error) {
+	periodKey, keyErr := d.key.Get(sha256.Size)
+
+	if keyErr != nil {
+		return 0, keyErr
+	}
+
+	counter := [4]byte{}
+
+	binary.BigEndian.PutUint32(counter[:], atomic.AddUint32(d.counter, 1))
+
+	hasher := hmac.New(sha256.New, periodKey)
+
+	_, wErr := hasher.Write(counter[:])
+
+	if wErr != nil {
+		return 0, wErr
+	}
+
+	sum := hasher.Sum(nil)
+
+	return int(binary.BigEndian.Uint64(sum[:8]) % uint64(len(d.ports))), nil
+}
+
+func (d *dial) Dial() (network.Connection, error) {
+	picked, pickErr := d.pick()
+
+	if pickErr != nil {
+		return nil, pickErr
+	}
+
+	d.current = picked
+
+	return d.ports[picked].Dial()
+}
+
+func (d *dial) String() string {
+	return d.ports[d.current].String()
+}
@@ -26,21 +26,26 @@ import (
 	"time"
 
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 )
 
 type dialer struct {
+	local       net.IP
 	host        string
 	port        uint16
 	timeout     time.Duration
+	options     sockopt.Options
 	connWrapper network.ConnectionWrapper
 }
 
 type dial struct {
 	resolved    net.IP
 	useResolved bool
+	local       net.IP
 	host        string
 	port        uint16
 	timeout     time.Duration
+	options     sockopt.Options
 	connWrapper network.ConnectionWrapper
 }
 
@@ -49,12 +54,28 @@ func New(
 	host string,
 	port uint16,
 	timeout time.Duration,
+	options sockopt.Options,
+	connWrapper network.ConnectionWrapper,
+) network.Dialer {
+	return NewFrom(nil, host, port, timeout, options, connWrapper)
+}
+
+// NewFrom returns a new TCP Dialer which dials from the given local IP
+// address. A nil local IP lets the system to pick one
+func NewFrom(
+	local net.IP,
+	host string,
+	port uint16,
+	timeout time.Duration,
+	options sockopt.Options,
 	connWrapper network.ConnectionWrapper,
 ) network.Dialer {
 	return dialer{
+		local:       local,
 		host:        host,
 		port:        port,
 		timeout:     timeout,
+		options:     options,
 		connWrapper: connWrapper,
 	}
 }
@@ -63,9 +84,11 @@ func (d dialer) Dialer() network.Dial {
 	return &dial{
 		resolved:    nil,
 		useResolved: false,
+		local:       d.local,
 		host:        d.host,
 		port:        d.port,
 		timeout:     d.timeout,
+		options:     d.options,
 		connWrapper: d.connWrapper,
 	}
 }
@@ -85,7 +108,19 @@ func (d *dial) resolvedAddress() string {
 }
 
 func (d *dial) Dial() (network.Connection, error) {
-	dialed, dialErr := net.DialTimeout("tcp", d.resolvedAddress(), d.timeout)
+	dialer := net.Dialer{
+		Timeout: d.timeout,
+		Control: d.options.Control(),
+	}
+
+	if d.local != nil {
+		dialer.LocalAddr = &net.TCPAddr{
+			IP:   d.local,
+			Port: 0,
+		}
+	}
+
+	dialed, dialErr := dialer.Dial("tcp", d.resolvedAddress())
 
 	if dialErr != nil {
 		d.useResolved = !d.useResolved
@@ -96,7 +131,13 @@ func (d *dial) Dial() (network.Connection, error) {
 	d.useResolved = true
 	d.resolved = dialed.RemoteAddr().(*net.TCPAddr).IP
 
-	dialed.(*net.TCPConn).SetNoDelay(false)
+	optErr := d.options.Conn(dialed.(*net.TCPConn))
+
+	if optErr != nil {
+		dialed.Close()
+
+		return nil, optErr
+	}
 
 	return d.connWrapper(dialed), nil
 }
@@ -0,0 +1,207 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package tls
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+)
+
+// Errors
+var (
+	ErrCertificateKeyMismatched = errors.New(
+		"TLS Certificate and Key must be specified together")
+
+	ErrCAInvalid = errors.New(
+		"No valid certificate was found in the TLS CA file")
+
+	ErrPinInvalid = errors.New(
+		"TLS Pin must be a Base64 encoded SHA-256 hash of the public key")
+
+	ErrPinMismatched = errors.New(
+		"None of the certificates of the server matches the TLS Pin")
+
+	ErrPeerCertificateMissing = errors.New(
+		"Server has not provided any certificate")
+)
+
+// Config is the client side TLS configuration
+type Config struct {
+	ServerName  string
+	ALPN        []string
+	CA          string
+	Pins        []string
+	Certificate string
+	Key         string
+}
+
+// Pin returns the pin of a certificate, which is the Base64 encoded
+// SHA-256 hash of the certificate's SubjectPublicKeyInfo
+func Pin(cert *x509.Certificate) string {
+	hashed := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	return base64.StdEncoding.EncodeToString(hashed[:])
+}
+
+// Build loads the certificates and builds a crypto/tls configuration.
+//
+// When Pins is specified, the server certificate will only be verified
+// against the CA when CA is also specified, otherwise the pins will be
+// the only thing that been verified (So self-signed certificate can be
+// used on the server)
+func (c Config) Build() (*tls.Config, error) {
+	if (c.Certificate == "") != (c.Key == "") {
+		return nil, ErrCertificateKeyMismatched
+	}
+
+	cfg := &tls.Config{
+		ServerName: c.ServerName,
+		NextProtos: c.ALPN,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.Certificate != "" {
+		cert, certErr := tls.LoadX509KeyPair(c.Certificate, c.Key)
+
+		if certErr != nil {
+			return nil, certErr
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if c.CA != "" {
+		caData, caErr := ioutil.ReadFile(c.CA)
+
+		if caErr != nil {
+			return nil, caErr
+		}
+
+		cfg.RootCAs = x509.NewCertPool()
+
+		if !cfg.RootCAs.AppendCertsFromPEM(caData) {
+			return nil, ErrCAInvalid
+		}
+	}
+
+	if len(c.Pins) <= 0 {
+		return cfg, nil
+	}
+
+	pins := make([][]byte, len(c.Pins))
+
+	for pIdx := range c.Pins {
+		pin, pinErr := base64.StdEncoding.DecodeString(c.Pins[pIdx])
+
+		if pinErr != nil || len(pin) != sha256.Size {
+			return nil, ErrPinInvalid
+		}
+
+		pins[pIdx] = pin
+	}
+
+	verifier := pinVerifier{
+		pins:  pins,
+		roots: cfg.RootCAs,
+	}
+
+	// Standard verification is replaced by the verifier
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = verifier.verify
+
+	return cfg, nil
+}
+
+// pinVerifier verifies server certificates against the pins
+type pinVerifier struct {
+	pins  [][]byte
+	roots *x509.CertPool
+}
+
+// match returns whether or not the certificate matches any of the pins
+func (p pinVerifier) match(cert *x509.Certificate) bool {
+	hashed := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+
+	for pIdx := range p.pins {
+		if !bytes.Equal(hashed[:], p.pins[pIdx]) {
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// verify verifies the certificates sent by the server.
+//
+// Without roots, only the leaf certificate is matched against the pins,
+// as it's the only one the server has proven to own the key of. Other
+// certificates sent by the server are just unverified data. With roots,
+// the pins are matched against the chains built by the verification
+func (p pinVerifier) verify(state tls.ConnectionState) error {
+	certs := state.PeerCertificates
+
+	if len(certs) <= 0 {
+		return ErrPeerCertificateMissing
+	}
+
+	if p.roots == nil {
+		if !p.match(certs[0]) {
+			return ErrPinMismatched
+		}
+
+		return nil
+	}
+
+	intermediates := x509.NewCertPool()
+
+	for cIdx := range certs[1:] {
+		intermediates.AddCert(certs[cIdx+1])
+	}
+
+	chains, verifyErr := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       state.ServerName,
+		Roots:         p.roots,
+		Intermediates: intermediates,
+	})
+
+	if verifyErr != nil {
+		return verifyErr
+	}
+
+	for chIdx := range chains {
+		for cIdx := range chains[chIdx] {
+			if !p.match(chains[chIdx][cIdx]) {
+				continue
+			}
+
+			return nil
+		}
+	}
+
+	return ErrPinMismatched
+}
@@ -0,0 +1,75 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package unix
+
+import (
+	"net"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+)
+
+type dialer struct {
+	path        string
+	timeout     time.Duration
+	connWrapper network.ConnectionWrapper
+}
+
+type dial struct {
+	path        string
+	timeout     time.Duration
+	connWrapper network.ConnectionWrapper
+}
+
+// New returns a new unix domain socket Dialer
+func New(
+	path string,
+	timeout time.Duration,
+	connWrapper network.ConnectionWrapper,
+) network.Dialer {
+	return dialer{
+		path:        path,
+		timeout:     timeout,
+		connWrapper: connWrapper,
+	}
+}
+
+func (d dialer) Dialer() network.Dial {
+	return &dial{
+		path:        d.path,
+		timeout:     d.timeout,
+		connWrapper: d.connWrapper,
+	}
+}
+
+func (d *dial) Dial() (network.Connection, error) {
+	dialed, dialErr := net.DialTimeout("unix", d.path, d.timeout)
+
+	if dialErr != nil {
+		return nil, dialErr
+	}
+
+	return d.connWrapper(dialed), nil
+}
+
+func (d *dial) String() string {
+	return d.path
+}
@@ -0,0 +1,117 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/reinit/coward/common/ticker"
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/arq"
+	"github.com/reinit/coward/roles/common/network/listener/udp"
+)
+
+// Consts
+const (
+	tickDelay = 1 * time.Second
+)
+
+// listener is a reliable UDP listener
+type listener struct {
+	host              net.IP
+	port              uint16
+	capacity          uint32
+	config            arq.Config
+	connectionWrapper network.ConnectionWrapper
+}
+
+// acceptor is a reliable UDP acceptor
+type acceptor struct {
+	network.Acceptor
+
+	ticker ticker.RequestCloser
+}
+
+// New creates a new reliable UDP listener which accepts at most capacity
+// clients. Clients are demultiplexed by their address through the UDP
+// listener
+func New(
+	host net.IP,
+	port uint16,
+	capacity uint32,
+	config arq.Config,
+	connectionWrapper network.ConnectionWrapper,
+) network.Listener {
+	return listener{
+		host:              host,
+		port:              port,
+		capacity:          capacity,
+		config:            config,
+		connectionWrapper: connectionWrapper,
+	}
+}
+
+// Listen listens a UDP port for reliable UDP connections
+func (t listener) Listen() (network.Acceptor, error) {
+	tk, tkErr := ticker.New(tickDelay, 16).Serve()
+
+	if tkErr != nil {
+		return nil, tkErr
+	}
+
+	accepting, listenErr := udp.New(
+		t.host,
+		t.port,
+		0,
+		t.capacity,
+		make([]byte, arq.DatagramBufferSize),
+		tk,
+		func(conn net.Conn) network.Connection {
+			return t.connectionWrapper(arq.Server(conn, t.config))
+		},
+	).Listen()
+
+	if listenErr != nil {
+		tk.Close()
+
+		return nil, listenErr
+	}
+
+	return acceptor{
+		Acceptor: accepting,
+		ticker:   tk,
+	}, nil
+}
+
+// String returns current Listener information in string
+func (t listener) String() string {
+	return net.JoinHostPort(
+		t.host.String(), strconv.FormatUint(uint64(t.port), 10))
+}
+
+// Close closes the reliable UDP listener
+func (a acceptor) Close() error {
+	defer a.ticker.Close()
+
+	return a.Acceptor.Close()
+}
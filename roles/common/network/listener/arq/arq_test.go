@@ -0,0 +1,121 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package arq
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network/arq"
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	arqdial "github.com/reinit/coward/roles/common/network/dialer/arq"
+)
+
+func TestARQListenDial(t *testing.T) {
+	cfg := arq.Config{
+		Window:     arq.DefaultWindow,
+		MinRTO:     arq.DefaultMinRTO,
+		FastResend: arq.DefaultFastResend,
+		FEC:        4,
+	}
+
+	acc, lErr := New(net.ParseIP("127.0.0.1"), 0, 16, cfg, tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	go func() {
+		for {
+			conn, accErr := acc.Accept()
+
+			if accErr != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	_, spPort, spErr := net.SplitHostPort(acc.Addr().String())
+
+	if spErr != nil {
+		t.Error("Failed to split listening host port:", spErr)
+
+		return
+	}
+
+	portN, portNErr := strconv.ParseUint(spPort, 10, 16)
+
+	if portNErr != nil {
+		t.Error("Failed to convert port string to number:", portNErr)
+
+		return
+	}
+
+	for cIdx := 0; cIdx < 2; cIdx++ {
+		dialed, dialErr := arqdial.New(
+			"127.0.0.1", uint16(portN), 3*time.Second, cfg, tcp.Wrap,
+		).Dialer().Dial()
+
+		if dialErr != nil {
+			t.Error("Failed to dial due to error:", dialErr)
+
+			return
+		}
+
+		dialed.SetDeadline(time.Now().Add(10 * time.Second))
+
+		data := bytes.Repeat([]byte("Hello World"), 20000)
+
+		go dialed.Write(data)
+
+		received := make([]byte, len(data))
+
+		_, rErr := io.ReadFull(dialed, received)
+
+		dialed.Close()
+
+		if rErr != nil {
+			t.Error("Failed to read due to error:", rErr)
+
+			return
+		}
+
+		if !bytes.Equal(received, data) {
+			t.Error("Received data is different from sent")
+
+			return
+		}
+	}
+}
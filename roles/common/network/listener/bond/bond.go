@@ -0,0 +1,215 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package bond
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/bond"
+	"github.com/reinit/coward/roles/common/transceiver"
+	"github.com/reinit/coward/roles/common/transceiver/connection"
+)
+
+// Consts
+const (
+	acceptErrorWait = 300 * time.Millisecond
+)
+
+// listener is a bonding listener
+type listener struct {
+	listener          network.Listener
+	codec             transceiver.CodecBuilder
+	helloTimeout      time.Duration
+	connectionWrapper network.ConnectionWrapper
+}
+
+// acceptor is a bonding acceptor
+type acceptor struct {
+	acceptor          network.Acceptor
+	codec             transceiver.CodecBuilder
+	helloTimeout      time.Duration
+	connectionWrapper network.ConnectionWrapper
+	bondsLock         *sync.Mutex
+	bonds             map[bond.ID]*bond.Conn
+	accepted          chan network.Connection
+	closed            chan struct{}
+}
+
+// New creates a new bonding listener on top of an existing listener.
+// Connections accepted by the underlying listener are links, and links
+// which sent the same ID as hello will be bonded into one connection.
+// Links are encoded with the codec, so links that can't pass the codec
+// will be rejected
+func New(
+	l network.Listener,
+	codec transceiver.CodecBuilder,
+	helloTimeout time.Duration,
+	connectionWrapper network.ConnectionWrapper,
+) network.Listener {
+	return listener{
+		listener:          l,
+		codec:             codec,
+		helloTimeout:      helloTimeout,
+		connectionWrapper: connectionWrapper,
+	}
+}
+
+// Listen starts the underlying listener and accepts links from it
+func (t listener) Listen() (network.Acceptor, error) {
+	accepting, listenErr := t.listener.Listen()
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	a := acceptor{
+		acceptor:          accepting,
+		codec:             t.codec,
+		helloTimeout:      t.helloTimeout,
+		connectionWrapper: t.connectionWrapper,
+		bondsLock:         &sync.Mutex{},
+		bonds:             make(map[bond.ID]*bond.Conn, 64),
+		accepted:          make(chan network.Connection),
+		closed:            make(chan struct{}),
+	}
+
+	go a.serve()
+
+	return a, nil
+}
+
+// String returns current Listener information in string
+func (t listener) String() string {
+	return t.listener.String()
+}
+
+func (a acceptor) serve() {
+	for {
+		conn, accErr := a.acceptor.Accept()
+
+		if accErr == nil {
+			go a.handshake(conn)
+
+			continue
+		}
+
+		select {
+		case <-a.acceptor.Closed():
+			return
+
+		case <-a.closed:
+			return
+
+		default:
+			time.Sleep(acceptErrorWait)
+		}
+	}
+}
+
+// handshake reads the hello of a link, and then bonds it with the other
+// links that has the same ID
+func (a acceptor) handshake(conn network.Connection) {
+	conn.SetDeadline(time.Now().Add(a.helloTimeout))
+
+	cc, ccErr := connection.Codec(a.codec, conn, false, a.helloTimeout)
+
+	if ccErr != nil {
+		conn.Close()
+
+		return
+	}
+
+	link := bond.Coded(conn, cc)
+
+	id, helloErr := bond.ReadHello(link)
+
+	if helloErr != nil {
+		conn.Close()
+
+		return
+	}
+
+	conn.SetDeadline(time.Time{})
+
+	a.bondsLock.Lock()
+
+	bonded, found := a.bonds[id]
+
+	if found {
+		a.bondsLock.Unlock()
+
+		if bonded.Attach(link) != nil {
+			conn.Close()
+		}
+
+		return
+	}
+
+	bonded = bond.New([]net.Conn{link}, func() {
+		a.bondsLock.Lock()
+		defer a.bondsLock.Unlock()
+
+		delete(a.bonds, id)
+	})
+
+	a.bonds[id] = bonded
+
+	a.bondsLock.Unlock()
+
+	select {
+	case a.accepted <- a.connectionWrapper(bonded):
+	case <-a.closed:
+		bonded.Close()
+	}
+}
+
+// Addr returns the current address this listener is listen on
+func (a acceptor) Addr() net.Addr {
+	return a.acceptor.Addr()
+}
+
+// Accept accepts a bonded connection. Only the first link of a bonded
+// connection will result an Accept, later links will be attached to it
+func (a acceptor) Accept() (network.Connection, error) {
+	select {
+	case accepted := <-a.accepted:
+		return accepted, nil
+
+	case <-a.closed:
+		return nil, io.EOF
+	}
+}
+
+// Closed return whether or not current acceptor is closed
+func (a acceptor) Closed() chan struct{} {
+	return a.closed
+}
+
+// Close closes the bonding listener
+func (a acceptor) Close() error {
+	close(a.closed)
+
+	return a.acceptor.Close()
+}
@@ -0,0 +1,220 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package bond
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/roles/common/codec"
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	bonddial "github.com/reinit/coward/roles/common/network/dialer/bond"
+	tcpdial "github.com/reinit/coward/roles/common/network/dialer/tcp"
+	tcplisten "github.com/reinit/coward/roles/common/network/listener/tcp"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+func testBondCodec(key string) transceiver.CodecBuilder {
+	return codec.AESGCM256X25519().Build(
+		[]string{key}, logger.NewDitch(), metrics.NewDitch())
+}
+
+func testBondLinks(addr net.Addr, links int) ([]network.Dialer, error) {
+	_, spPort, spErr := net.SplitHostPort(addr.String())
+
+	if spErr != nil {
+		return nil, spErr
+	}
+
+	portN, portNErr := strconv.ParseUint(spPort, 10, 16)
+
+	if portNErr != nil {
+		return nil, portNErr
+	}
+
+	dialers := make([]network.Dialer, links)
+
+	for lIdx := range dialers {
+		dialers[lIdx] = tcpdial.NewFrom(
+			net.ParseIP("127.0.0.1"),
+			"127.0.0.1",
+			uint16(portN),
+			3*time.Second,
+			sockopt.Options{},
+			tcp.Wrap)
+	}
+
+	return dialers, nil
+}
+
+func TestBondListenDial(t *testing.T) {
+	acc, lErr := New(
+		tcplisten.New(
+			net.ParseIP("127.0.0.1"), 0, sockopt.Options{}, tcp.Wrap),
+		testBondCodec("0123456789abcdef"),
+		3*time.Second,
+		tcp.Wrap,
+	).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	accepted := make(chan network.Connection, 4)
+
+	go func() {
+		for {
+			conn, accErr := acc.Accept()
+
+			if accErr != nil {
+				return
+			}
+
+			accepted <- conn
+
+			go func() {
+				defer conn.Close()
+
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	links, linksErr := testBondLinks(acc.Addr(), 3)
+
+	if linksErr != nil {
+		t.Error("Failed to build links due to error:", linksErr)
+
+		return
+	}
+
+	for cIdx := 0; cIdx < 2; cIdx++ {
+		dialed, dialErr := bonddial.New(
+			links, testBondCodec("0123456789abcdef"), 3*time.Second, tcp.Wrap,
+		).Dialer().Dial()
+
+		if dialErr != nil {
+			t.Error("Failed to dial due to error:", dialErr)
+
+			return
+		}
+
+		dialed.SetDeadline(time.Now().Add(10 * time.Second))
+
+		data := bytes.Repeat([]byte("Hello World"), 200000)
+
+		go dialed.Write(data)
+
+		received := make([]byte, len(data))
+
+		_, rErr := io.ReadFull(dialed, received)
+
+		dialed.Close()
+
+		if rErr != nil {
+			t.Error("Failed to read due to error:", rErr)
+
+			return
+		}
+
+		if !bytes.Equal(received, data) {
+			t.Error("Received data is different from sent")
+
+			return
+		}
+	}
+
+	// Links of the same connection must be bonded together, so only one
+	// connection will be accepted for each Dial
+	if len(accepted) != 2 {
+		t.Errorf("Expecting 2 accepted connections, got %d", len(accepted))
+
+		return
+	}
+}
+
+func TestBondListenRejectForeignLinks(t *testing.T) {
+	acc, lErr := New(
+		tcplisten.New(
+			net.ParseIP("127.0.0.1"), 0, sockopt.Options{}, tcp.Wrap),
+		testBondCodec("0123456789abcdef"),
+		3*time.Second,
+		tcp.Wrap,
+	).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	accepted := make(chan network.Connection, 1)
+
+	go func() {
+		conn, accErr := acc.Accept()
+
+		if accErr != nil {
+			return
+		}
+
+		accepted <- conn
+	}()
+
+	links, linksErr := testBondLinks(acc.Addr(), 1)
+
+	if linksErr != nil {
+		t.Error("Failed to build links due to error:", linksErr)
+
+		return
+	}
+
+	dialed, dialErr := bonddial.New(
+		links, testBondCodec("fedcba9876543210"), 3*time.Second, tcp.Wrap,
+	).Dialer().Dial()
+
+	// The link may already be refused during the handshake of the Codec
+	if dialErr == nil {
+		defer dialed.Close()
+	}
+
+	select {
+	case conn := <-accepted:
+		conn.Close()
+
+		t.Error("Expecting link with a different key to be rejected")
+
+	case <-time.After(1 * time.Second):
+	}
+}
@@ -0,0 +1,164 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package hop
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+)
+
+// Consts
+const (
+	acceptErrorWait = 300 * time.Millisecond
+)
+
+// listener is a port hopping listener
+type listener struct {
+	listeners []network.Listener
+}
+
+// acceptor is a port hopping acceptor
+type acceptor struct {
+	acceptors []network.Acceptor
+	accepted  chan network.Connection
+	closed    chan struct{}
+}
+
+// New creates a new port hopping listener which listens on all the given
+// listeners (usually one for each port of a port range) at the same time,
+// and accepts connections from any of them
+func New(listeners []network.Listener) network.Listener {
+	return listener{
+		listeners: listeners,
+	}
+}
+
+// Listen starts all the underlying listeners
+func (t listener) Listen() (network.Acceptor, error) {
+	a := acceptor{
+		acceptors: make([]network.Acceptor, 0, len(t.listeners)),
+		accepted:  make(chan network.Connection),
+		closed:    make(chan struct{}),
+	}
+
+	for lIdx := range t.listeners {
+		accepting, listenErr := t.listeners[lIdx].Listen()
+
+		if listenErr != nil {
+			for aIdx := range a.acceptors {
+				a.acceptors[aIdx].Close()
+			}
+
+			return nil, listenErr
+		}
+
+		a.acceptors = append(a.acceptors, accepting)
+	}
+
+	for aIdx := range a.acceptors {
+		go a.serve(a.acceptors[aIdx])
+	}
+
+	return a, nil
+}
+
+// String returns current Listener information in string
+func (t listener) String() string {
+	if len(t.listeners) <= 1 {
+		return t.listeners[0].String()
+	}
+
+	return t.listeners[0].String() + " (+" +
+		strconv.FormatUint(uint64(len(t.listeners)-1), 10) + ")"
+}
+
+func (a acceptor) serve(accepting network.Acceptor) {
+	for {
+		conn, accErr := accepting.Accept()
+
+		if accErr == nil {
+			select {
+			case a.accepted <- conn:
+			case <-a.closed:
+				conn.Close()
+
+				return
+			}
+
+			continue
+		}
+
+		select {
+		case <-accepting.Closed():
+			return
+
+		case <-a.closed:
+			return
+
+		default:
+			time.Sleep(acceptErrorWait)
+		}
+	}
+}
+
+// Addr returns the address of the first port this listener is listen on
+func (a acceptor) Addr() net.Addr {
+	return a.acceptors[0].Addr()
+}
+
+// Accept accepts a connection from any of the underlying listeners
+func (a acceptor) Accept() (network.Connection, error) {
+	select {
+	case accepted := <-a.accepted:
+		return accepted, nil
+
+	case <-a.closed:
+		return nil, io.EOF
+	}
+}
+
+// Closed return whether or not current acceptor is closed
+func (a acceptor) Closed() chan struct{} {
+	return a.closed
+}
+
+// Close closes all the underlying listeners
+func (a acceptor) Close() error {
+	close(a.closed)
+
+	var closeErr error
+
+	for aIdx := range a.acceptors {
+		cErr := a.acceptors[aIdx].Close()
+
+		if cErr == nil || closeErr != nil {
+			continue
+		}
+
+		closeErr = cErr
+	}
+
+	return closeErr
+}
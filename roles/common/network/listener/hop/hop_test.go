@@ -0,0 +1,155 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package hop
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	hopdial "github.com/reinit/coward/roles/common/network/dialer/hop"
+	tcpdial "github.com/reinit/coward/roles/common/network/dialer/tcp"
+	tcplisten "github.com/reinit/coward/roles/common/network/listener/tcp"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+)
+
+func testFreePorts(count int) ([]uint16, error) {
+	ports := make([]uint16, 0, count)
+
+	for len(ports) < count {
+		l, lErr := net.Listen("tcp", "127.0.0.1:0")
+
+		if lErr != nil {
+			return nil, lErr
+		}
+
+		ports = append(ports, uint16(l.Addr().(*net.TCPAddr).Port))
+
+		l.Close()
+	}
+
+	return ports, nil
+}
+
+func TestHopListenDial(t *testing.T) {
+	const dials = 32
+
+	ports, portsErr := testFreePorts(4)
+
+	if portsErr != nil {
+		t.Error("Failed to find free ports due to error:", portsErr)
+
+		return
+	}
+
+	listeners := make([]network.Listener, len(ports))
+	dialers := make([]network.Dialer, len(ports))
+
+	for pIdx := range ports {
+		listeners[pIdx] = tcplisten.New(
+			net.ParseIP("127.0.0.1"), ports[pIdx], sockopt.Options{}, tcp.Wrap)
+		dialers[pIdx] = tcpdial.New(
+			"127.0.0.1", ports[pIdx], 3*time.Second, sockopt.Options{},
+			tcp.Wrap)
+	}
+
+	acc, lErr := New(listeners).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	accepted := make(chan network.Connection, dials)
+
+	go func() {
+		for {
+			conn, accErr := acc.Accept()
+
+			if accErr != nil {
+				return
+			}
+
+			accepted <- conn
+		}
+	}()
+
+	key := []byte("Hello World, Hello World")
+	hopping := hopdial.New(dialers, key, time.Minute).Dialer()
+	replay := hopdial.New(dialers, key, time.Minute).Dialer()
+	used := make(map[int]struct{}, len(ports))
+
+	for dIdx := 0; dIdx < dials; dIdx++ {
+		dialed, dialErr := hopping.Dial()
+
+		if dialErr != nil {
+			t.Error("Failed to dial due to error:", dialErr)
+
+			return
+		}
+
+		dialed.Close()
+
+		replayed, replayErr := replay.Dial()
+
+		if replayErr != nil {
+			t.Error("Failed to dial due to error:", replayErr)
+
+			return
+		}
+
+		replayed.Close()
+
+		if hopping.String() != replayed.RemoteAddr().String() {
+			t.Errorf("Expecting the same key to pick the same port %s, "+
+				"got %s", hopping.String(), replayed.RemoteAddr())
+
+			return
+		}
+
+		used[dialed.RemoteAddr().(*net.TCPAddr).Port] = struct{}{}
+	}
+
+	if len(used) <= 1 {
+		t.Errorf("Expecting connections to be spread across ports, "+
+			"only %d port was used", len(used))
+
+		return
+	}
+
+	for aIdx := 0; aIdx < dials*2; aIdx++ {
+		select {
+		case conn := <-accepted:
+			conn.Close()
+
+		case <-time.After(3 * time.Second):
+			t.Errorf("Expecting %d accepted connections, got %d",
+				dials*2, aIdx)
+
+			return
+		}
+	}
+}
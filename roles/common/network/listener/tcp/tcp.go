@@ -24,19 +24,23 @@ import (
 	"net"
 	"strconv"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 )
 
 // listener is a TCP listener
 type listener struct {
 	host              net.IP
 	port              uint16
+	options           sockopt.Options
 	connectionWrapper network.ConnectionWrapper
 }
 
 // acceptor is a TCP acceptor
 type acceptor struct {
 	listener          *net.TCPListener
+	options           sockopt.Options
 	connectionWrapper network.ConnectionWrapper
 	closed            chan struct{}
 }
@@ -45,18 +49,20 @@ type acceptor struct {
 func New(
 	host net.IP,
 	port uint16,
+	options sockopt.Options,
 	connectionWrapper network.ConnectionWrapper,
 ) network.Listener {
 	return listener{
 		host:              host,
 		port:              port,
+		options:           options,
 		connectionWrapper: connectionWrapper,
 	}
 }
 
 // Listen listens a TCP port
 func (t listener) Listen() (network.Acceptor, error) {
-	listener, listenErr := net.ListenTCP("tcp", &net.TCPAddr{
+	listener, listenErr := inherit.ListenTCP(&net.TCPAddr{
 		IP:   t.host,
 		Port: int(t.port), // Safe when not running on a system that below 16b
 		Zone: "",
@@ -66,8 +72,18 @@ func (t listener) Listen() (network.Acceptor, error) {
 		return nil, listenErr
 	}
 
+	optErr := t.options.Listener(listener)
+
+	if optErr != nil {
+		inherit.Forget(listener)
+		listener.Close()
+
+		return nil, optErr
+	}
+
 	return acceptor{
 		listener:          listener,
+		options:           t.options,
 		connectionWrapper: t.connectionWrapper,
 		closed:            make(chan struct{}),
 	}, nil
@@ -98,8 +114,7 @@ func (a acceptor) Accept() (network.Connection, error) {
 		return nil, optErr
 	}
 
-	// Delay data for sending on server, so the TCP can work more efficienly
-	optErr = accepted.SetNoDelay(false)
+	optErr = a.options.Conn(accepted)
 
 	if optErr != nil {
 		return nil, optErr
@@ -117,5 +132,7 @@ func (a acceptor) Closed() chan struct{} {
 func (a acceptor) Close() error {
 	close(a.closed)
 
+	inherit.Forget(a.listener)
+
 	return a.listener.Close()
 }
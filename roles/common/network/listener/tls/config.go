@@ -0,0 +1,85 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package tls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+)
+
+// Errors
+var (
+	ErrCertificateUnspecified = errors.New(
+		"TLS Certificate and Key must be specified")
+
+	ErrClientCAInvalid = errors.New(
+		"No valid certificate was found in the TLS Client CA file")
+)
+
+// Config is the server side TLS configuration
+type Config struct {
+	Certificate string
+	Key         string
+	ClientCA    string
+	ALPN        []string
+}
+
+// Build loads the certificates and builds a crypto/tls configuration
+func (c Config) Build() (*tls.Config, error) {
+	if c.Certificate == "" || c.Key == "" {
+		return nil, ErrCertificateUnspecified
+	}
+
+	cert, certErr := tls.LoadX509KeyPair(c.Certificate, c.Key)
+
+	if certErr != nil {
+		return nil, certErr
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   c.ALPN,
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.NoClientCert,
+	}
+
+	if c.ClientCA == "" {
+		return cfg, nil
+	}
+
+	caData, caErr := ioutil.ReadFile(c.ClientCA)
+
+	if caErr != nil {
+		return nil, caErr
+	}
+
+	cfg.ClientCAs = x509.NewCertPool()
+
+	if !cfg.ClientCAs.AppendCertsFromPEM(caData) {
+		return nil, ErrClientCAInvalid
+	}
+
+	cfg.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return cfg, nil
+}
@@ -0,0 +1,425 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	tlsdial "github.com/reinit/coward/roles/common/network/dialer/tls"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+)
+
+type testCertificate struct {
+	cert     *x509.Certificate
+	certFile string
+	keyFile  string
+}
+
+func generateTestCertificate(
+	t *testing.T,
+	dir string,
+	name string,
+) testCertificate {
+	key, keyErr := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if keyErr != nil {
+		t.Fatal("Failed to generate key due to error:", keyErr)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject: pkix.Name{
+			CommonName: name,
+		},
+		NotBefore: time.Now().Add(-1 * time.Hour),
+		NotAfter:  time.Now().Add(1 * time.Hour),
+		KeyUsage: x509.KeyUsageDigitalSignature |
+			x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{
+			x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{name},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, derErr := x509.CreateCertificate(
+		rand.Reader, template, template, &key.PublicKey, key)
+
+	if derErr != nil {
+		t.Fatal("Failed to create certificate due to error:", derErr)
+	}
+
+	cert, certErr := x509.ParseCertificate(der)
+
+	if certErr != nil {
+		t.Fatal("Failed to parse certificate due to error:", certErr)
+	}
+
+	keyDer, keyDerErr := x509.MarshalECPrivateKey(key)
+
+	if keyDerErr != nil {
+		t.Fatal("Failed to marshal key due to error:", keyDerErr)
+	}
+
+	result := testCertificate{
+		cert:     cert,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+
+	writeErr := ioutil.WriteFile(result.certFile, pem.EncodeToMemory(
+		&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+
+	if writeErr != nil {
+		t.Fatal("Failed to write certificate due to error:", writeErr)
+	}
+
+	writeErr = ioutil.WriteFile(result.keyFile, pem.EncodeToMemory(
+		&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+
+	if writeErr != nil {
+		t.Fatal("Failed to write key due to error:", writeErr)
+	}
+
+	return result
+}
+
+func testTLSEcho(
+	t *testing.T,
+	server Config,
+	client tlsdial.Config,
+) error {
+	serverCfg, serverCfgErr := server.Build()
+
+	if serverCfgErr != nil {
+		t.Fatal("Failed to build server config due to error:", serverCfgErr)
+	}
+
+	clientCfg, clientCfgErr := client.Build()
+
+	if clientCfgErr != nil {
+		t.Fatal("Failed to build client config due to error:", clientCfgErr)
+	}
+
+	acc, lErr := New(
+		net.ParseIP("127.0.0.1"), 0, serverCfg, sockopt.Options{}, tcp.Wrap,
+	).Listen()
+
+	if lErr != nil {
+		t.Fatal("Failed to listen due to error:", lErr)
+	}
+
+	defer acc.Close()
+
+	go func() {
+		conn, accErr := acc.Accept()
+
+		if accErr != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		io.Copy(conn, conn)
+	}()
+
+	_, spPort, spErr := net.SplitHostPort(acc.Addr().String())
+
+	if spErr != nil {
+		t.Fatal("Failed to split listening host port:", spErr)
+	}
+
+	portN, portNErr := strconv.ParseUint(spPort, 10, 16)
+
+	if portNErr != nil {
+		t.Fatal("Failed to convert port string to number:", portNErr)
+	}
+
+	dialed, dialErr := tlsdial.New(
+		"127.0.0.1", uint16(portN), 3*time.Second, clientCfg,
+		sockopt.Options{}, tcp.Wrap,
+	).Dialer().Dial()
+
+	if dialErr != nil {
+		return dialErr
+	}
+
+	defer dialed.Close()
+
+	dialed.SetDeadline(time.Now().Add(3 * time.Second))
+
+	_, wErr := dialed.Write([]byte("Hello World"))
+
+	if wErr != nil {
+		return wErr
+	}
+
+	buf := make([]byte, 11)
+
+	_, rErr := io.ReadFull(dialed, buf)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	if string(buf) != "Hello World" {
+		t.Errorf("Expecting to receive %q, got %q", "Hello World", buf)
+	}
+
+	return nil
+}
+
+func TestTLSListenDial(t *testing.T) {
+	dir, dirErr := ioutil.TempDir("", "coward-tls-test")
+
+	if dirErr != nil {
+		t.Fatal("Failed to create temp dir due to error:", dirErr)
+	}
+
+	defer os.RemoveAll(dir)
+
+	server := generateTestCertificate(t, dir, "server.test")
+	other := generateTestCertificate(t, dir, "other.test")
+	client := generateTestCertificate(t, dir, "client.test")
+
+	// A leaf certificate of other key followed by the pinned certificate
+	forgedChain := filepath.Join(dir, "forged.crt")
+	forgedData := []byte{}
+
+	for _, certFile := range []string{other.certFile, server.certFile} {
+		certData, certDataErr := ioutil.ReadFile(certFile)
+
+		if certDataErr != nil {
+			t.Fatal("Failed to read certificate due to error:", certDataErr)
+		}
+
+		forgedData = append(forgedData, certData...)
+	}
+
+	writeErr := ioutil.WriteFile(forgedChain, forgedData, 0600)
+
+	if writeErr != nil {
+		t.Fatal("Failed to write certificate due to error:", writeErr)
+	}
+
+	tests := []struct {
+		Name    string
+		Server  Config
+		Client  tlsdial.Config
+		Success bool
+	}{
+		{
+			Name: "CA",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+				ALPN:        []string{"coward"},
+			},
+			Client: tlsdial.Config{
+				ServerName: "server.test",
+				ALPN:       []string{"coward"},
+				CA:         server.certFile,
+			},
+			Success: true,
+		},
+		{
+			Name: "CA with wrong server name",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				ServerName: "other.test",
+				CA:         server.certFile,
+			},
+			Success: false,
+		},
+		{
+			Name: "Untrusted",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				ServerName: "server.test",
+				CA:         other.certFile,
+			},
+			Success: false,
+		},
+		{
+			Name: "Pinned",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				Pins: []string{
+					tlsdial.Pin(other.cert), tlsdial.Pin(server.cert)},
+			},
+			Success: true,
+		},
+		{
+			Name: "Pinned with CA",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				ServerName: "server.test",
+				CA:         server.certFile,
+				Pins:       []string{tlsdial.Pin(server.cert)},
+			},
+			Success: true,
+		},
+		{
+			Name: "Pinned with CA and wrong server name",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				ServerName: "other.test",
+				CA:         server.certFile,
+				Pins:       []string{tlsdial.Pin(server.cert)},
+			},
+			Success: false,
+		},
+		{
+			Name: "Pin mismatched",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+			},
+			Client: tlsdial.Config{
+				Pins: []string{tlsdial.Pin(other.cert)},
+			},
+			Success: false,
+		},
+		{
+			Name: "Pinned certificate appended to the chain",
+			Server: Config{
+				Certificate: forgedChain,
+				Key:         other.keyFile,
+			},
+			Client: tlsdial.Config{
+				Pins: []string{tlsdial.Pin(server.cert)},
+			},
+			Success: false,
+		},
+		{
+			Name: "Client certificate",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+				ClientCA:    client.certFile,
+			},
+			Client: tlsdial.Config{
+				Pins:        []string{tlsdial.Pin(server.cert)},
+				Certificate: client.certFile,
+				Key:         client.keyFile,
+			},
+			Success: true,
+		},
+		{
+			Name: "Client certificate untrusted",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+				ClientCA:    client.certFile,
+			},
+			Client: tlsdial.Config{
+				Pins:        []string{tlsdial.Pin(server.cert)},
+				Certificate: other.certFile,
+				Key:         other.keyFile,
+			},
+			Success: false,
+		},
+		{
+			Name: "Client certificate missing",
+			Server: Config{
+				Certificate: server.certFile,
+				Key:         server.keyFile,
+				ClientCA:    client.certFile,
+			},
+			Client: tlsdial.Config{
+				Pins: []string{tlsdial.Pin(server.cert)},
+			},
+			Success: false,
+		},
+	}
+
+	for tIdx, test := range tests {
+		echoErr := testTLSEcho(t, test.Server, test.Client)
+
+		if test.Success && echoErr != nil {
+			t.Errorf("Test %d (%s) expected to success, got error: %s",
+				tIdx, test.Name, echoErr)
+
+			continue
+		}
+
+		if !test.Success && echoErr == nil {
+			t.Errorf("Test %d (%s) expected to fail, but succeed",
+				tIdx, test.Name)
+
+			continue
+		}
+	}
+}
+
+func TestTLSConfigBuild(t *testing.T) {
+	_, buildErr := Config{}.Build()
+
+	if buildErr != ErrCertificateUnspecified {
+		t.Errorf("Expecting error %s, got %s",
+			ErrCertificateUnspecified, buildErr)
+	}
+
+	_, buildErr = tlsdial.Config{Certificate: "cert.pem"}.Build()
+
+	if buildErr != tlsdial.ErrCertificateKeyMismatched {
+		t.Errorf("Expecting error %s, got %s",
+			tlsdial.ErrCertificateKeyMismatched, buildErr)
+	}
+
+	_, buildErr = tlsdial.Config{Pins: []string{"AAAA"}}.Build()
+
+	if buildErr != tlsdial.ErrPinInvalid {
+		t.Errorf("Expecting error %s, got %s",
+			tlsdial.ErrPinInvalid, buildErr)
+	}
+}
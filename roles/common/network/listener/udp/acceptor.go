@@ -24,8 +24,10 @@ import (
 	"errors"
 	"io"
 	"net"
+	"sync"
 	"time"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/roles/common/network"
 )
@@ -46,6 +48,7 @@ type acceptor struct {
 	clients           clients
 	rbufferCompleted  chan struct{}
 	closed            chan struct{}
+	closeOnce         sync.Once
 }
 
 func (a *acceptor) Addr() net.Addr {
@@ -98,7 +101,9 @@ func (a *acceptor) Accept() (network.Connection, error) {
 				deadlineTicker:      a.deadlineTick,
 				ipPort:              ipPort,
 				currentReader:       nil,
+				readerLock:          sync.Mutex{},
 				readerDeliver:       make(chan *rbuffer, 1),
+				kicked:              false,
 				readDeadline:        time.Time{},
 				readDeadlineEnabled: false,
 				closed:              false,
@@ -120,14 +125,21 @@ func (a *acceptor) Closed() chan struct{} {
 
 // Close closes the UDP listener
 func (a *acceptor) Close() error {
+	// Mark the acceptor as closed before closing the listener, so the
+	// failed Accept can tell it's caused by the close. Closing again will
+	// only get the error from the closed listener
+	a.closeOnce.Do(func() {
+		close(a.closed)
+	})
+
+	inherit.Forget(a.listener)
+
 	cErr := a.listener.Close()
 
 	if cErr != nil {
 		return cErr
 	}
 
-	close(a.closed)
-
 	a.clients.Clear(func(cc *conn) {
 		cc.Kick()
 	})
@@ -24,6 +24,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/reinit/coward/common/ticker"
@@ -47,7 +48,9 @@ type conn struct {
 	deadlineTicker      ticker.Requester
 	ipPort              network.ConnectionID
 	currentReader       *rbuffer
+	readerLock          sync.Mutex
 	readerDeliver       chan *rbuffer
+	kicked              bool
 	readDeadline        time.Time
 	readDeadlineEnabled bool
 	closed              bool
@@ -87,8 +90,12 @@ func (c *conn) SetWriteDeadline(t time.Time) error {
 }
 
 func (c *conn) getReader() (io.Reader, error) {
-	if c.currentReader != nil {
-		return c.currentReader, nil
+	c.readerLock.Lock()
+	currentReader := c.currentReader
+	c.readerLock.Unlock()
+
+	if currentReader != nil {
+		return currentReader, nil
 	}
 
 	var deadlineWait ticker.Wait
@@ -130,6 +137,16 @@ func (c *conn) getReader() (io.Reader, error) {
 				return nil, io.EOF
 			}
 
+			c.readerLock.Lock()
+			defer c.readerLock.Unlock()
+
+			// Kicked while the reader is being delivered
+			if c.kicked {
+				r.Clear()
+
+				return nil, io.EOF
+			}
+
 			c.currentReader = r
 
 			return c.currentReader, nil
@@ -155,11 +172,17 @@ func (c *conn) Read(b []byte) (int, error) {
 			return 0, readerErr
 		}
 
+		c.readerLock.Lock()
+
 		rLen, rErr := reader.Read(b)
 
 		if rErr == errRBufferNoMoreData {
 			c.currentReader = nil
+		}
+
+		c.readerLock.Unlock()
 
+		if rErr == errRBufferNoMoreData {
 			continue
 		}
 
@@ -172,6 +195,11 @@ func (c *conn) Write(b []byte) (int, error) {
 }
 
 func (c *conn) Kick() error {
+	c.readerLock.Lock()
+	defer c.readerLock.Unlock()
+
+	c.kicked = true
+
 	select {
 	case n := <-c.readerDeliver:
 		close(c.readerDeliver)
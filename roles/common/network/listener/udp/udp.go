@@ -26,6 +26,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/roles/common/network"
 )
@@ -63,7 +64,7 @@ func New(
 
 // Listen start listening
 func (t listener) Listen() (network.Acceptor, error) {
-	listen, listenErr := net.ListenUDP("udp", &net.UDPAddr{
+	listen, listenErr := inherit.ListenUDP(&net.UDPAddr{
 		IP:   t.host,
 		Port: int(t.port),
 		Zone: "",
@@ -87,6 +88,7 @@ func (t listener) Listen() (network.Acceptor, error) {
 		},
 		rbufferCompleted: make(chan struct{}, 1),
 		closed:           make(chan struct{}, 1),
+		closeOnce:        sync.Once{},
 	}, nil
 }
 
@@ -61,6 +61,14 @@ func TestUDPListenUpDown(t *testing.T) {
 
 		return
 	}
+
+	closeErr = cc.Close()
+
+	if closeErr == nil {
+		t.Error("Expecting closing again to fail, but it succeed")
+
+		return
+	}
 }
 
 func TestUDPListen(t *testing.T) {
@@ -0,0 +1,77 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+//go:build !windows
+
+package unix
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+)
+
+func TestUnixListenUmask(t *testing.T) {
+	previousUmask := syscall.Umask(0022)
+
+	defer syscall.Umask(previousUmask)
+
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	acc, lErr := New(path, Config{
+		Mode: 0,
+		UID:  os.Getuid(),
+		GID:  -1,
+	}, tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	currentUmask := syscall.Umask(0022)
+
+	if currentUmask != 0022 {
+		t.Errorf("Expecting umask %o to be restored, got %o",
+			0022, currentUmask)
+
+		return
+	}
+
+	info, statErr := os.Stat(path)
+
+	if statErr != nil {
+		t.Error("Failed to stat the socket file due to error:", statErr)
+
+		return
+	}
+
+	if info.Mode().Perm() != 0755 {
+		t.Errorf("Expecting socket mode 0755, got %o", info.Mode().Perm())
+
+		return
+	}
+}
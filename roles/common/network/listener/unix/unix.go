@@ -0,0 +1,332 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package unix
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/inherit"
+	"github.com/reinit/coward/roles/common/network"
+)
+
+// Errors
+var (
+	ErrNotSocket = errors.New(
+		"The path already exists and is not a socket")
+
+	ErrSocketInUse = errors.New(
+		"The socket is already in use by another process")
+
+	ErrInvalidMode = errors.New(
+		"Socket mode must be an octal number between 0 and 0777")
+
+	ErrInvalidOwner = errors.New(
+		"Socket owner must be in \"<User>[:<Group>]\" format")
+)
+
+// Consts
+const (
+	staleProbeTimeout = 1 * time.Second
+
+	// privateUmask makes the newly created socket file only accessible by
+	// it's owner
+	privateUmask = 0177
+
+	// socketMode is the permission of the socket file before the umask is
+	// applied
+	socketMode os.FileMode = 0777
+)
+
+// Vars
+var (
+	// umaskLock prevents concurrent Listens from restoring each other's
+	// umask
+	umaskLock = sync.Mutex{}
+)
+
+// Config is the settings of the socket file
+type Config struct {
+	// Mode is the permission of the socket file, 0 to keep the one that is
+	// given by the umask
+	Mode os.FileMode
+
+	// UID and GID is the owner of the socket file, -1 to keep current one
+	UID int
+	GID int
+}
+
+// listener is a unix domain socket listener
+type listener struct {
+	path              string
+	config            Config
+	connectionWrapper network.ConnectionWrapper
+}
+
+// acceptor is a unix domain socket acceptor
+type acceptor struct {
+	listener          *net.UnixListener
+	connectionWrapper network.ConnectionWrapper
+	closed            chan struct{}
+}
+
+// ParseMode parses an octal permission string such as "0660"
+func ParseMode(mode string) (os.FileMode, error) {
+	parsed, parseErr := strconv.ParseUint(mode, 8, 32)
+
+	if parseErr != nil || parsed > 0777 {
+		return 0, ErrInvalidMode
+	}
+
+	return os.FileMode(parsed), nil
+}
+
+// ParseOwner parses an owner string in "<User>[:<Group>]" format, both the
+// name and the numeric ID are accepted. GID will be -1 when the group is
+// omitted
+func ParseOwner(owner string) (int, int, error) {
+	userName := owner
+	groupName := ""
+
+	if sepIdx := strings.IndexByte(owner, ':'); sepIdx >= 0 {
+		userName = owner[:sepIdx]
+		groupName = owner[sepIdx+1:]
+
+		if groupName == "" {
+			return -1, -1, ErrInvalidOwner
+		}
+	}
+
+	if userName == "" {
+		return -1, -1, ErrInvalidOwner
+	}
+
+	uid, uidErr := strconv.ParseUint(userName, 10, 31)
+
+	if uidErr != nil {
+		u, lookupErr := user.Lookup(userName)
+
+		if lookupErr != nil {
+			return -1, -1, lookupErr
+		}
+
+		uid, uidErr = strconv.ParseUint(u.Uid, 10, 31)
+
+		if uidErr != nil {
+			return -1, -1, uidErr
+		}
+	}
+
+	if groupName == "" {
+		return int(uid), -1, nil
+	}
+
+	gid, gidErr := strconv.ParseUint(groupName, 10, 31)
+
+	if gidErr != nil {
+		g, lookupErr := user.LookupGroup(groupName)
+
+		if lookupErr != nil {
+			return -1, -1, lookupErr
+		}
+
+		gid, gidErr = strconv.ParseUint(g.Gid, 10, 31)
+
+		if gidErr != nil {
+			return -1, -1, gidErr
+		}
+	}
+
+	return int(uid), int(gid), nil
+}
+
+// New creates a new unix domain socket listener
+func New(
+	path string,
+	config Config,
+	connectionWrapper network.ConnectionWrapper,
+) network.Listener {
+	return listener{
+		path:              path,
+		config:            config,
+		connectionWrapper: connectionWrapper,
+	}
+}
+
+// removeStale removes the socket file that left by a previous run. It
+// will refuse to remove files that are not sockets, or sockets that are
+// still being served
+func (t listener) removeStale() error {
+	info, statErr := os.Lstat(t.path)
+
+	if statErr != nil {
+		if os.IsNotExist(statErr) {
+			return nil
+		}
+
+		return statErr
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return ErrNotSocket
+	}
+
+	probe, probeErr := net.DialTimeout("unix", t.path, staleProbeTimeout)
+
+	if probeErr == nil {
+		probe.Close()
+
+		return ErrSocketInUse
+	}
+
+	return os.Remove(t.path)
+}
+
+// Listen creates the socket file and listens on it
+func (t listener) Listen() (network.Acceptor, error) {
+	// Inherited socket is already set up by the one who created it
+	if listener, adopted := inherit.AdoptUnix(t.path); adopted {
+		return acceptor{
+			listener:          listener,
+			connectionWrapper: t.connectionWrapper,
+			closed:            make(chan struct{}),
+		}, nil
+	}
+
+	removeErr := t.removeStale()
+
+	if removeErr != nil {
+		return nil, removeErr
+	}
+
+	restricted := t.config.Mode != 0 || t.config.UID >= 0 || t.config.GID >= 0
+
+	if !restricted {
+		listener, listenErr := inherit.ListenUnix(&net.UnixAddr{
+			Name: t.path,
+			Net:  "unix",
+		})
+
+		if listenErr != nil {
+			return nil, listenErr
+		}
+
+		return acceptor{
+			listener:          listener,
+			connectionWrapper: t.connectionWrapper,
+			closed:            make(chan struct{}),
+		}, nil
+	}
+
+	// Create the socket file with only the owner allowed, so nobody can
+	// connect to it before it's owner and permission is set. The umask is
+	// shared by the entire process, other files created in the meantime
+	// will be restricted as well, which is harmless
+	umaskLock.Lock()
+
+	previousUmask := umask(privateUmask)
+
+	listener, listenErr := inherit.ListenUnix(&net.UnixAddr{
+		Name: t.path,
+		Net:  "unix",
+	})
+
+	umask(previousUmask)
+
+	umaskLock.Unlock()
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	if t.config.UID >= 0 || t.config.GID >= 0 {
+		chownErr := os.Chown(t.path, t.config.UID, t.config.GID)
+
+		if chownErr != nil {
+			inherit.Forget(listener)
+			listener.Close()
+
+			return nil, chownErr
+		}
+	}
+
+	mode := t.config.Mode
+
+	if mode == 0 {
+		mode = socketMode &^ os.FileMode(previousUmask)
+	}
+
+	chmodErr := os.Chmod(t.path, mode)
+
+	if chmodErr != nil {
+		inherit.Forget(listener)
+		listener.Close()
+
+		return nil, chmodErr
+	}
+
+	return acceptor{
+		listener:          listener,
+		connectionWrapper: t.connectionWrapper,
+		closed:            make(chan struct{}),
+	}, nil
+}
+
+// String returns current Listener information in string
+func (t listener) String() string {
+	return t.path
+}
+
+// Addr returns the current address this listener is listen on
+func (a acceptor) Addr() net.Addr {
+	return a.listener.Addr()
+}
+
+// Accept accepts a unix domain socket connection
+func (a acceptor) Accept() (network.Connection, error) {
+	accepted, acceptErr := a.listener.AcceptUnix()
+
+	if acceptErr != nil {
+		return nil, acceptErr
+	}
+
+	return a.connectionWrapper(accepted), nil
+}
+
+// Closed return whether or not current acceptor is closed
+func (a acceptor) Closed() chan struct{} {
+	return a.closed
+}
+
+// Close closes the listener, and removes the socket file
+func (a acceptor) Close() error {
+	close(a.closed)
+
+	inherit.Forget(a.listener)
+
+	return a.listener.Close()
+}
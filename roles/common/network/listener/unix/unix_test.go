@@ -0,0 +1,219 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package unix
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	unixdial "github.com/reinit/coward/roles/common/network/dialer/unix"
+)
+
+func TestUnixListenDial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.sock")
+
+	acc, lErr := New(path, Config{
+		Mode: 0600,
+		UID:  -1,
+		GID:  -1,
+	}, tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	info, statErr := os.Stat(path)
+
+	if statErr != nil {
+		t.Error("Failed to stat the socket file due to error:", statErr)
+
+		return
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expecting socket mode 0600, got %o", info.Mode().Perm())
+
+		return
+	}
+
+	go func() {
+		for {
+			conn, accErr := acc.Accept()
+
+			if accErr != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	dialed, dialErr := unixdial.New(
+		path, 3*time.Second, tcp.Wrap).Dialer().Dial()
+
+	if dialErr != nil {
+		t.Error("Failed to dial due to error:", dialErr)
+
+		return
+	}
+
+	defer dialed.Close()
+
+	dialed.SetDeadline(time.Now().Add(10 * time.Second))
+
+	data := bytes.Repeat([]byte("Hello World"), 20000)
+
+	go dialed.Write(data)
+
+	received := make([]byte, len(data))
+
+	_, rErr := io.ReadFull(dialed, received)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(received, data) {
+		t.Error("Received data is different from sent")
+
+		return
+	}
+
+	_, lErr = New(path, Config{
+		Mode: 0,
+		UID:  -1,
+		GID:  -1,
+	}, tcp.Wrap).Listen()
+
+	if lErr != ErrSocketInUse {
+		t.Errorf("Expecting error %s, got %s", ErrSocketInUse, lErr)
+
+		return
+	}
+}
+
+func TestUnixListenStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "test.sock")
+	cfg := Config{
+		Mode: 0,
+		UID:  -1,
+		GID:  -1,
+	}
+
+	acc, lErr := New(path, cfg, tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	// Leave the socket file behind like a crashed process would do
+	acc.(acceptor).listener.SetUnlinkOnClose(false)
+	acc.Close()
+
+	acc, lErr = New(path, cfg, tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen on stale socket due to error:", lErr)
+
+		return
+	}
+
+	acc.Close()
+
+	regular := filepath.Join(dir, "regular")
+
+	wErr := os.WriteFile(regular, []byte("Hello World"), 0600)
+
+	if wErr != nil {
+		t.Error("Failed to create regular file due to error:", wErr)
+
+		return
+	}
+
+	_, lErr = New(regular, cfg, tcp.Wrap).Listen()
+
+	if lErr != ErrNotSocket {
+		t.Errorf("Expecting error %s, got %s", ErrNotSocket, lErr)
+
+		return
+	}
+}
+
+func TestParseModeOwner(t *testing.T) {
+	mode, modeErr := ParseMode("0660")
+
+	if modeErr != nil || mode != 0660 {
+		t.Errorf("Expecting mode 0660, got %o (%v)", mode, modeErr)
+
+		return
+	}
+
+	_, modeErr = ParseMode("0999")
+
+	if modeErr != ErrInvalidMode {
+		t.Errorf("Expecting error %s, got %v", ErrInvalidMode, modeErr)
+
+		return
+	}
+
+	uid, gid, ownerErr := ParseOwner("1000")
+
+	if ownerErr != nil || uid != 1000 || gid != -1 {
+		t.Errorf("Expecting owner 1000:-1, got %d:%d (%v)",
+			uid, gid, ownerErr)
+
+		return
+	}
+
+	uid, gid, ownerErr = ParseOwner("root:0")
+
+	if ownerErr != nil || uid != 0 || gid != 0 {
+		t.Errorf("Expecting owner 0:0, got %d:%d (%v)", uid, gid, ownerErr)
+
+		return
+	}
+
+	_, _, ownerErr = ParseOwner("1000:")
+
+	if ownerErr != ErrInvalidOwner {
+		t.Errorf("Expecting error %s, got %v", ErrInvalidOwner, ownerErr)
+
+		return
+	}
+}
@@ -0,0 +1,160 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"crypto/tls"
+	"net"
+	"strconv"
+
+	"github.com/reinit/coward/common/inherit"
+	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/network/websocket"
+)
+
+// listener is a WebSocket listener
+type listener struct {
+	host              net.IP
+	port              uint16
+	path              string
+	tlsConfig         *tls.Config
+	options           sockopt.Options
+	connectionWrapper network.ConnectionWrapper
+}
+
+// acceptor is a WebSocket acceptor
+type acceptor struct {
+	listener          *net.TCPListener
+	path              string
+	tlsConfig         *tls.Config
+	options           sockopt.Options
+	connectionWrapper network.ConnectionWrapper
+	closed            chan struct{}
+}
+
+// New creates a new WebSocket listener. The handshake request must be
+// sent to the given path, or any path when path is empty. When tlsConfig
+// is not nil, the WebSocket will be carried by TLS
+func New(
+	host net.IP,
+	port uint16,
+	path string,
+	tlsConfig *tls.Config,
+	options sockopt.Options,
+	connectionWrapper network.ConnectionWrapper,
+) network.Listener {
+	return listener{
+		host:              host,
+		port:              port,
+		path:              path,
+		tlsConfig:         tlsConfig,
+		options:           options,
+		connectionWrapper: connectionWrapper,
+	}
+}
+
+// Listen listens a TCP port for WebSocket connections
+func (t listener) Listen() (network.Acceptor, error) {
+	listener, listenErr := inherit.ListenTCP(&net.TCPAddr{
+		IP:   t.host,
+		Port: int(t.port), // Safe when not running on a system that below 16b
+		Zone: "",
+	})
+
+	if listenErr != nil {
+		return nil, listenErr
+	}
+
+	optErr := t.options.Listener(listener)
+
+	if optErr != nil {
+		inherit.Forget(listener)
+		listener.Close()
+
+		return nil, optErr
+	}
+
+	return acceptor{
+		listener:          listener,
+		path:              t.path,
+		tlsConfig:         t.tlsConfig,
+		options:           t.options,
+		connectionWrapper: t.connectionWrapper,
+		closed:            make(chan struct{}),
+	}, nil
+}
+
+// String returns current Listener information in string
+func (t listener) String() string {
+	return net.JoinHostPort(
+		t.host.String(), strconv.FormatUint(uint64(t.port), 10))
+}
+
+// Addr returns the current address this listener is listen on
+func (a acceptor) Addr() net.Addr {
+	return a.listener.Addr()
+}
+
+// Accept accepts a WebSocket connection.
+//
+// Like the TLS handshake, the WebSocket handshake will be carried out
+// during the first Read or Write of the connection
+func (a acceptor) Accept() (network.Connection, error) {
+	accepted, acceptErr := a.listener.AcceptTCP()
+
+	if acceptErr != nil {
+		return nil, acceptErr
+	}
+
+	optErr := accepted.SetLinger(0)
+
+	if optErr != nil {
+		return nil, optErr
+	}
+
+	optErr = a.options.Conn(accepted)
+
+	if optErr != nil {
+		return nil, optErr
+	}
+
+	if a.tlsConfig == nil {
+		return a.connectionWrapper(websocket.Server(accepted, a.path)), nil
+	}
+
+	return a.connectionWrapper(websocket.Server(
+		tls.Server(accepted, a.tlsConfig), a.path)), nil
+}
+
+// Closed return whether or not current acceptor is closed
+func (a acceptor) Closed() chan struct{} {
+	return a.closed
+}
+
+// Close closes the WebSocket listener
+func (a acceptor) Close() error {
+	close(a.closed)
+
+	inherit.Forget(a.listener)
+
+	return a.listener.Close()
+}
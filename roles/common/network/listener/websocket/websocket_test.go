@@ -0,0 +1,134 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/roles/common/network/connection/tcp"
+	wsdial "github.com/reinit/coward/roles/common/network/dialer/websocket"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/network/websocket"
+)
+
+func TestWebSocketListenDial(t *testing.T) {
+	acc, lErr := New(
+		net.ParseIP("127.0.0.1"), 0, "/coward", nil, sockopt.Options{},
+		tcp.Wrap).Listen()
+
+	if lErr != nil {
+		t.Error("Failed to listen due to error:", lErr)
+
+		return
+	}
+
+	defer acc.Close()
+
+	go func() {
+		for {
+			conn, accErr := acc.Accept()
+
+			if accErr != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	_, spPort, spErr := net.SplitHostPort(acc.Addr().String())
+
+	if spErr != nil {
+		t.Error("Failed to split listening host port:", spErr)
+
+		return
+	}
+
+	portN, portNErr := strconv.ParseUint(spPort, 10, 16)
+
+	if portNErr != nil {
+		t.Error("Failed to convert port string to number:", portNErr)
+
+		return
+	}
+
+	dialed, dialErr := wsdial.New(
+		"127.0.0.1", uint16(portN), 3*time.Second, websocket.Config{
+			Path: "/coward",
+			Host: "example.com",
+			Headers: http.Header{
+				"X-Forwarded-For": []string{"127.0.0.2"},
+			},
+		}, nil, sockopt.Options{}, tcp.Wrap).Dialer().Dial()
+
+	if dialErr != nil {
+		t.Error("Failed to dial due to error:", dialErr)
+
+		return
+	}
+
+	defer dialed.Close()
+
+	dialed.SetDeadline(time.Now().Add(3 * time.Second))
+
+	_, wErr := dialed.Write([]byte("Hello World"))
+
+	if wErr != nil {
+		t.Error("Failed to write due to error:", wErr)
+
+		return
+	}
+
+	buf := make([]byte, 11)
+
+	_, rErr := io.ReadFull(dialed, buf)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if string(buf) != "Hello World" {
+		t.Errorf("Expecting to receive %q, got %q", "Hello World", buf)
+	}
+
+	_, dialErr = wsdial.New(
+		"127.0.0.1", uint16(portN), 3*time.Second, websocket.Config{
+			Path:    "/other",
+			Host:    "",
+			Headers: nil,
+		}, nil, sockopt.Options{}, tcp.Wrap).Dialer().Dial()
+
+	if dialErr != websocket.ErrHandshakeInvalidResponse {
+		t.Errorf("Expecting dial to fail with error %s, got %s",
+			websocket.ErrHandshakeInvalidResponse, dialErr)
+	}
+}
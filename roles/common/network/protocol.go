@@ -36,6 +36,7 @@ const (
 	UnspecifiedProto Protocol = 0x00
 	TCP              Protocol = 0x01
 	UDP              Protocol = 0x02
+	Unix             Protocol = 0x03
 )
 
 // FromString select Protocol from a string
@@ -47,6 +48,9 @@ func (p *Protocol) FromString(n string) error {
 	case "udp":
 		*p = UDP
 
+	case "unix":
+		*p = Unix
+
 	default:
 		return ErrorProtocolUnknown
 	}
@@ -63,6 +67,9 @@ func (p Protocol) String() string {
 	case UDP:
 		return "UDP"
 
+	case Unix:
+		return "Unix"
+
 	default:
 		return ""
 	}
@@ -23,10 +23,14 @@ package server
 import (
 	"errors"
 	"net"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
 )
@@ -38,11 +42,18 @@ var (
 
 	ErrNotServing = errors.New(
 		"Not serving")
+
+	ErrListenerClosed = errors.New(
+		"Listener has been closed")
+
+	ErrAcceptFailing = errors.New(
+		"Failing to accept incoming connections")
 )
 
 // client registeration data
 type client struct {
 	Connection network.Connection
+	Session    session.Session
 	Result     chan error
 }
 
@@ -54,41 +65,59 @@ type leave struct {
 
 // server implements network.Server
 type server struct {
-	listener   network.Listener
+	listeners  []network.Listener
 	handler    network.Handler
 	logger     logger.Logger
+	metrics    metrics.Registry
+	sessions   session.Sessions
 	runner     worker.Runner
 	cfg        Config
 	accept     chan network.Connection
 	leave      chan leave
 	serving    bool
+	failing    int32
 	downLock   sync.Mutex
 	downWait   sync.WaitGroup
 	downNotify chan struct{}
 }
 
 type serving struct {
-	accepter network.Acceptor
-	server   *server
+	accepters []network.Acceptor
+	server    *server
 }
 
-// New creates a new network.Server
+// New creates a new network.Server which accepts connections from all
+// the given listeners. Connections accepted from all of the listeners
+// are handled by the same runner and counted towards the same capacity
 func New(
-	listener network.Listener,
+	listeners []network.Listener,
 	handler network.Handler,
 	logger logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	runner worker.Runner,
 	cfg Config,
 ) network.Server {
+	names := make([]string, len(listeners))
+
+	for lIdx := range listeners {
+		names[lIdx] = listeners[lIdx].String()
+	}
+
+	name := strings.Join(names, ", ")
+
 	return &server{
-		listener:   listener,
+		listeners:  listeners,
 		handler:    handler,
-		logger:     logger.Context("Server (" + listener.String() + ")"),
+		logger:     logger.Context("Server (" + name + ")"),
+		metrics:    m.With(metrics.L("listener", name)),
+		sessions:   s,
 		runner:     runner,
 		cfg:        cfg,
 		accept:     make(chan network.Connection),
 		leave:      make(chan leave, cfg.MaxConnections),
 		serving:    false,
+		failing:    0,
 		downLock:   sync.Mutex{},
 		downNotify: make(chan struct{}, 1),
 		downWait:   sync.WaitGroup{},
@@ -111,6 +140,10 @@ func (s *server) acceptor() {
 	closing := false
 	currentClients := uint64(0)
 	maxClients := uint64(s.cfg.MaxConnections)
+	accepted := s.metrics.Counter("coward_server_connections_total",
+		"Total number of connections accepted by the server")
+	rejected := s.metrics.Counter("coward_server_rejections_total",
+		"Total number of connections rejected by the server")
 
 	for {
 		select {
@@ -139,6 +172,8 @@ func (s *server) acceptor() {
 			if currentClients >= maxClients {
 				cl.Close()
 
+				rejected.Add(1)
+
 				log.Debugf("Failed to handle client \"%s\" because server "+
 					"has reached it's capacity", cl.RemoteAddr())
 
@@ -157,9 +192,12 @@ func (s *server) acceptor() {
 				continue
 			}
 
+			clSession := s.sessions.Open(session.Client, 0,
+				cl.RemoteAddr().String(), "", cl.Close)
+
 			h := handle{
 				ID:         connectionID,
-				Connection: cl,
+				Connection: network.Track(cl, clSession),
 				Leave:      s.leave,
 				Handler:    s.handler,
 			}
@@ -171,6 +209,9 @@ func (s *server) acceptor() {
 
 			if runJoinErr != nil {
 				cl.Close()
+				clSession.Close()
+
+				rejected.Add(1)
 
 				log.Debugf("Failed to handle client \"%s\" due to error: %s",
 					cl.RemoteAddr(), runJoinErr)
@@ -180,8 +221,11 @@ func (s *server) acceptor() {
 
 			currentClients++
 
+			accepted.Add(1)
+
 			clients[connectionID] = client{
 				Connection: cl,
+				Session:    clSession,
 				Result:     runResult,
 			}
 
@@ -197,6 +241,8 @@ func (s *server) acceptor() {
 
 			currentClients--
 
+			cli.Session.Close()
+
 			delete(clients, cl.ID)
 
 			select {
@@ -233,8 +279,16 @@ func (s *server) acceptor() {
 // serve listens the accepter and send accepted connection to acceptor
 func (s *server) serve(acc network.Acceptor) error {
 	log := s.logger.Context("Serving")
+	failures := s.metrics.Counter("coward_server_accept_failures_total",
+		"Total number of failed attempts to accept incoming connections")
+
+	failing := false
 
 	defer func() {
+		if failing {
+			atomic.AddInt32(&s.failing, -1)
+		}
+
 		log.Debugf("Closed")
 
 		s.downWait.Done()
@@ -243,12 +297,26 @@ func (s *server) serve(acc network.Acceptor) error {
 	for {
 		cli, accErr := acc.Accept()
 
+		if accErr == nil && failing {
+			atomic.AddInt32(&s.failing, -1)
+
+			failing = false
+		}
+
 		if accErr != nil {
 			select {
 			case <-acc.Closed():
 				return accErr
 
 			default:
+				if !failing {
+					atomic.AddInt32(&s.failing, 1)
+
+					failing = true
+				}
+
+				failures.Add(1)
+
 				log.Warningf("Failed to accept incomming connection "+
 					"due to error: %s", accErr)
 
@@ -276,28 +344,64 @@ func (s *server) Serve() (network.Serving, error) {
 		return nil, ErrAlreadyServing
 	}
 
-	acc, listenErr := s.listener.Listen()
+	accs := make([]network.Acceptor, 0, len(s.listeners))
+
+	for lIdx := range s.listeners {
+		acc, listenErr := s.listeners[lIdx].Listen()
+
+		if listenErr == nil {
+			accs = append(accs, acc)
+
+			continue
+		}
+
+		for aIdx := range accs {
+			accs[aIdx].Close()
+		}
 
-	if listenErr != nil {
 		return nil, listenErr
 	}
 
-	s.downWait.Add(2)
+	s.downWait.Add(1 + len(accs))
 
 	go s.acceptor()
-	go s.serve(acc)
+
+	for aIdx := range accs {
+		go s.serve(accs[aIdx])
+	}
 
 	s.serving = true
 
 	return serving{
-		accepter: acc,
-		server:   s,
+		accepters: accs,
+		server:    s,
 	}, nil
 }
 
-// Listening returns local address that current server is listening to
+// Listening returns local address that current server is listening to.
+// When the server is listening on multiple addresses, the first one
+// will be returned
 func (s serving) Listening() net.Addr {
-	return s.accepter.Addr()
+	return s.accepters[0].Addr()
+}
+
+// Health returns an error when any of the listeners has been closed or
+// is failing to accept incoming connections
+func (s serving) Health() error {
+	for aIdx := range s.accepters {
+		select {
+		case <-s.accepters[aIdx].Closed():
+			return ErrListenerClosed
+
+		default:
+		}
+	}
+
+	if atomic.LoadInt32(&s.server.failing) > 0 {
+		return ErrAcceptFailing
+	}
+
+	return nil
 }
 
 // Close shutdown current server
@@ -315,7 +419,17 @@ func (s serving) Close() error {
 		<-s.server.downNotify
 	}()
 
-	cErr := s.accepter.Close()
+	var cErr error
+
+	for aIdx := range s.accepters {
+		aErr := s.accepters[aIdx].Close()
+
+		if aErr == nil || cErr != nil {
+			continue
+		}
+
+		cErr = aErr
+	}
 
 	if cErr != nil {
 		return cErr
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -99,10 +101,12 @@ func TestServerUpDown(t *testing.T) {
 		return
 	}
 
-	s := New(&dummyListener{}, &dummyIncoming{}, logger.NewDitch(), r, Config{
-		AcceptErrorWait: 1 * time.Second,
-		MaxConnections:  1024,
-	})
+	s := New([]network.Listener{&dummyListener{}, &dummyListener{}},
+		&dummyIncoming{}, logger.NewDitch(),
+		metrics.NewDitch(), session.NewDitch(), r, Config{
+			AcceptErrorWait: 1 * time.Second,
+			MaxConnections:  1024,
+		})
 
 	for i := 0; i < 100; i++ {
 		serve, serveErr := s.Serve()
@@ -130,6 +134,14 @@ func TestServerUpDown(t *testing.T) {
 		return
 	}
 
+	healthErr := serve.Health()
+
+	if healthErr != nil {
+		t.Error("Expecting a serving server to be healthy, got:", healthErr)
+
+		return
+	}
+
 	closeErr := serve.Close()
 
 	if closeErr != nil {
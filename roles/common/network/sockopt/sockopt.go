@@ -0,0 +1,140 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package sockopt
+
+import (
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// Errors
+var (
+	ErrUnsupported = errors.New(
+		"Socket Mark and TCP Fast Open are not supported on this platform")
+)
+
+// Options of the TCP sockets. Zero value leaves the related option to
+// the system default
+type Options struct {
+	KeepAlive     time.Duration
+	SendBuffer    int
+	ReceiveBuffer int
+	NoDelay       bool
+	Mark          uint32
+	FastOpen      bool
+}
+
+// Verify checks whether or not the options can be applied on current
+// platform
+func (o Options) Verify() error {
+	if !o.raw() || rawSupported {
+		return nil
+	}
+
+	return ErrUnsupported
+}
+
+// raw returns whether or not there are options which must be set
+// directly on the socket before it's connected or listening
+func (o Options) raw() bool {
+	return o.Mark != 0 || o.FastOpen
+}
+
+// Conn applies the options to an established TCP connection
+func (o Options) Conn(conn *net.TCPConn) error {
+	if o.KeepAlive > 0 {
+		optErr := conn.SetKeepAlive(true)
+
+		if optErr != nil {
+			return optErr
+		}
+
+		optErr = conn.SetKeepAlivePeriod(o.KeepAlive)
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	if o.SendBuffer > 0 {
+		optErr := conn.SetWriteBuffer(o.SendBuffer)
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	if o.ReceiveBuffer > 0 {
+		optErr := conn.SetReadBuffer(o.ReceiveBuffer)
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	// Unless asked otherwise, delay data for sending, so the TCP can
+	// work more efficienly
+	return conn.SetNoDelay(o.NoDelay)
+}
+
+// Listener applies the options to a listening TCP socket
+func (o Options) Listener(listener *net.TCPListener) error {
+	if !o.raw() {
+		return nil
+	}
+
+	raw, rawErr := listener.SyscallConn()
+
+	if rawErr != nil {
+		return rawErr
+	}
+
+	return control(raw, o.listen)
+}
+
+// Control returns a function for the Control field of net.Dialer, which
+// applies the options that must be set before the socket is connected
+func (o Options) Control() func(string, string, syscall.RawConn) error {
+	if !o.raw() {
+		return nil
+	}
+
+	return func(network, address string, raw syscall.RawConn) error {
+		return control(raw, o.dial)
+	}
+}
+
+// control runs the setter on the file descriptor of the socket
+func control(raw syscall.RawConn, setter func(fd uintptr) error) error {
+	var setErr error
+
+	ctlErr := raw.Control(func(fd uintptr) {
+		setErr = setter(fd)
+	})
+
+	if ctlErr != nil {
+		return ctlErr
+	}
+
+	return setErr
+}
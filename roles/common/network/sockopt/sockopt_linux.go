@@ -0,0 +1,83 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package sockopt
+
+import (
+	"syscall"
+)
+
+// Consts
+const (
+	rawSupported = true
+
+	// Not all of them are defined by the syscall package
+	tcpFastOpen        = 0x17
+	tcpFastOpenConnect = 0x1e
+
+	// fastOpenQueueLength is the max amount of pending Fast Open requests
+	fastOpenQueueLength = 256
+)
+
+// listen sets the options of a listening socket
+func (o Options) listen(fd uintptr) error {
+	if o.Mark != 0 {
+		optErr := syscall.SetsockoptInt(
+			int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, int(o.Mark))
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	if o.FastOpen {
+		optErr := syscall.SetsockoptInt(
+			int(fd), syscall.IPPROTO_TCP, tcpFastOpen, fastOpenQueueLength)
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	return nil
+}
+
+// dial sets the options of a socket before it's connected
+func (o Options) dial(fd uintptr) error {
+	if o.Mark != 0 {
+		optErr := syscall.SetsockoptInt(
+			int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, int(o.Mark))
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	if o.FastOpen {
+		optErr := syscall.SetsockoptInt(
+			int(fd), syscall.IPPROTO_TCP, tcpFastOpenConnect, 1)
+
+		if optErr != nil {
+			return optErr
+		}
+	}
+
+	return nil
+}
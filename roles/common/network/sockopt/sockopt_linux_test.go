@@ -0,0 +1,165 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package sockopt
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func testGetsockopt(
+	raw syscall.RawConn, level int, opt int) (int, error) {
+	var value int
+	var getErr error
+
+	ctlErr := raw.Control(func(fd uintptr) {
+		value, getErr = syscall.GetsockoptInt(int(fd), level, opt)
+	})
+
+	if ctlErr != nil {
+		return 0, ctlErr
+	}
+
+	return value, getErr
+}
+
+func TestOptions(t *testing.T) {
+	options := Options{
+		KeepAlive:     7 * time.Second,
+		SendBuffer:    65536,
+		ReceiveBuffer: 65536,
+		NoDelay:       true,
+		Mark:          0,
+		FastOpen:      true,
+	}
+
+	listener, listenErr := net.ListenTCP("tcp", &net.TCPAddr{
+		IP:   net.ParseIP("127.0.0.1"),
+		Port: 0,
+		Zone: "",
+	})
+
+	if listenErr != nil {
+		t.Error("Failed to listen due to error:", listenErr)
+
+		return
+	}
+
+	defer listener.Close()
+
+	optErr := options.Listener(listener)
+
+	if optErr != nil {
+		t.Error("Failed to apply listener options due to error:", optErr)
+
+		return
+	}
+
+	listenRaw, _ := listener.SyscallConn()
+
+	fastOpen, getErr := testGetsockopt(
+		listenRaw, syscall.IPPROTO_TCP, tcpFastOpen)
+
+	if getErr != nil || fastOpen != fastOpenQueueLength {
+		t.Errorf("Expecting TCP Fast Open queue to be %d, got %d (%v)",
+			fastOpenQueueLength, fastOpen, getErr)
+
+		return
+	}
+
+	dialer := net.Dialer{
+		Timeout: 3 * time.Second,
+		Control: options.Control(),
+	}
+
+	dialed, dialErr := dialer.Dial("tcp", listener.Addr().String())
+
+	if dialErr != nil {
+		t.Error("Failed to dial due to error:", dialErr)
+
+		return
+	}
+
+	defer dialed.Close()
+
+	optErr = options.Conn(dialed.(*net.TCPConn))
+
+	if optErr != nil {
+		t.Error("Failed to apply connection options due to error:", optErr)
+
+		return
+	}
+
+	raw, _ := dialed.(*net.TCPConn).SyscallConn()
+
+	noDelay, getErr := testGetsockopt(
+		raw, syscall.IPPROTO_TCP, syscall.TCP_NODELAY)
+
+	if getErr != nil || noDelay == 0 {
+		t.Errorf("Expecting TCP_NODELAY to be set, got %d (%v)",
+			noDelay, getErr)
+
+		return
+	}
+
+	keepAlive, getErr := testGetsockopt(
+		raw, syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+
+	if getErr != nil || keepAlive != 7 {
+		t.Errorf("Expecting keepalive idle time to be %d, got %d (%v)",
+			7, keepAlive, getErr)
+
+		return
+	}
+
+	// Linux doubles the buffer size for bookkeeping overhead
+	rcvBuf, getErr := testGetsockopt(
+		raw, syscall.SOL_SOCKET, syscall.SO_RCVBUF)
+
+	if getErr != nil || rcvBuf < options.ReceiveBuffer {
+		t.Errorf("Expecting receive buffer to be at least %d, got %d (%v)",
+			options.ReceiveBuffer, rcvBuf, getErr)
+
+		return
+	}
+
+	options.NoDelay = false
+
+	optErr = options.Conn(dialed.(*net.TCPConn))
+
+	if optErr != nil {
+		t.Error("Failed to apply connection options due to error:", optErr)
+
+		return
+	}
+
+	noDelay, getErr = testGetsockopt(
+		raw, syscall.IPPROTO_TCP, syscall.TCP_NODELAY)
+
+	if getErr != nil || noDelay != 0 {
+		t.Errorf("Expecting TCP_NODELAY to be cleared, got %d (%v)",
+			noDelay, getErr)
+
+		return
+	}
+}
@@ -0,0 +1,58 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package network
+
+import "github.com/reinit/coward/common/session"
+
+// tracked is a Connection that records it's transferred bytes into
+// a session.Session
+type tracked struct {
+	Connection
+
+	session session.Session
+}
+
+// Track wraps the Connection so bytes been transferred through it will
+// be recorded into the given session.Session
+func Track(c Connection, s session.Session) Connection {
+	return tracked{
+		Connection: c,
+		session:    s,
+	}
+}
+
+// Read reads data from the Connection
+func (t tracked) Read(b []byte) (int, error) {
+	rLen, rErr := t.Connection.Read(b)
+
+	t.session.Received(rLen)
+
+	return rLen, rErr
+}
+
+// Write writes data to the Connection
+func (t tracked) Write(b []byte) (int, error) {
+	wLen, wErr := t.Connection.Write(b)
+
+	t.session.Sent(wLen)
+
+	return wLen, wErr
+}
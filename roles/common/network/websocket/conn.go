@@ -0,0 +1,384 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Errors
+var (
+	ErrFrameUnmasked = errors.New(
+		"WebSocket frame sent by the client must be masked")
+
+	ErrFrameMasked = errors.New(
+		"WebSocket frame sent by the server must not be masked")
+
+	ErrFrameReservedBitsSet = errors.New(
+		"WebSocket frame has reserved bits set")
+
+	ErrFrameControlInvalid = errors.New(
+		"WebSocket control frame was invalid")
+
+	ErrFrameOpcodeUnsupported = errors.New(
+		"WebSocket frame opcode is unsupported")
+)
+
+// Consts
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+
+	frameFin         = 0x80
+	frameReserved    = 0x70
+	frameOpcode      = 0x0f
+	frameMask        = 0x80
+	frameLength      = 0x7f
+	frameLength16    = 126
+	frameLength64    = 127
+	frameMaxHeader   = 14
+	frameMaxControl  = 125
+	closeWaitTimeout = 1 * time.Second
+)
+
+// Conn is a WebSocket connection which carries a byte stream in binary
+// frames
+type Conn struct {
+	net.Conn
+
+	reader        *bufio.Reader
+	client        bool
+	handshaker    func(c *Conn) error
+	handshakeLock sync.Mutex
+	handshaked    bool
+	handshakeErr  error
+	established   uint32
+	readLock      sync.Mutex
+	remaining     uint64
+	mask          [4]byte
+	maskIdx       int
+	masked        bool
+	closeReceived bool
+	writeLock     sync.Mutex
+	writeBuf      []byte
+	closeSent     bool
+}
+
+// Handshake performs the WebSocket handshake if it hasn't been done yet.
+// It will be called automatically during the first Read or Write
+func (c *Conn) Handshake() error {
+	c.handshakeLock.Lock()
+	defer c.handshakeLock.Unlock()
+
+	if c.handshaked {
+		return c.handshakeErr
+	}
+
+	c.handshaked = true
+	c.handshakeErr = c.handshaker(c)
+
+	if c.handshakeErr == nil {
+		atomic.StoreUint32(&c.established, 1)
+	}
+
+	return c.handshakeErr
+}
+
+// Read reads payload data of the WebSocket frames
+func (c *Conn) Read(b []byte) (int, error) {
+	hsErr := c.Handshake()
+
+	if hsErr != nil {
+		return 0, hsErr
+	}
+
+	c.readLock.Lock()
+	defer c.readLock.Unlock()
+
+	for c.remaining <= 0 {
+		if c.closeReceived {
+			return 0, io.EOF
+		}
+
+		hdErr := c.readHeader()
+
+		if hdErr != nil {
+			return 0, hdErr
+		}
+	}
+
+	if uint64(len(b)) > c.remaining {
+		b = b[:c.remaining]
+	}
+
+	rLen, rErr := c.reader.Read(b)
+
+	if c.masked {
+		for bIdx := range b[:rLen] {
+			b[bIdx] ^= c.mask[c.maskIdx]
+
+			c.maskIdx = (c.maskIdx + 1) % len(c.mask)
+		}
+	}
+
+	c.remaining -= uint64(rLen)
+
+	return rLen, rErr
+}
+
+// readHeader reads the header of next frame. Control frames will be
+// handled here. The header is only consumed after it has been completely
+// received, so it's safe to call again after a timeout
+func (c *Conn) readHeader() error {
+	head, peekErr := c.reader.Peek(2)
+
+	if peekErr != nil {
+		return peekErr
+	}
+
+	if head[0]&frameReserved != 0 {
+		return ErrFrameReservedBitsSet
+	}
+
+	masked := head[1]&frameMask != 0
+
+	if c.client && masked {
+		return ErrFrameMasked
+	}
+
+	if !c.client && !masked {
+		return ErrFrameUnmasked
+	}
+
+	opcode := head[0] & frameOpcode
+	fin := head[0]&frameFin != 0
+	headerLen := 2
+	length := uint64(head[1] & frameLength)
+
+	switch length {
+	case frameLength16:
+		headerLen += 2
+
+	case frameLength64:
+		headerLen += 8
+	}
+
+	if masked {
+		headerLen += 4
+	}
+
+	header, peekErr := c.reader.Peek(headerLen)
+
+	if peekErr != nil {
+		return peekErr
+	}
+
+	switch length {
+	case frameLength16:
+		length = uint64(binary.BigEndian.Uint16(header[2:4]))
+
+	case frameLength64:
+		length = binary.BigEndian.Uint64(header[2:10])
+	}
+
+	var mask [4]byte
+
+	if masked {
+		copy(mask[:], header[headerLen-4:headerLen])
+	}
+
+	switch opcode {
+	case opContinuation:
+		fallthrough
+	case opText:
+		fallthrough
+	case opBinary:
+		c.reader.Discard(headerLen)
+
+		c.remaining = length
+		c.mask = mask
+		c.maskIdx = 0
+		c.masked = masked
+
+		return nil
+
+	case opClose:
+		fallthrough
+	case opPing:
+		fallthrough
+	case opPong:
+		if !fin || length > frameMaxControl {
+			return ErrFrameControlInvalid
+		}
+
+	default:
+		return ErrFrameOpcodeUnsupported
+	}
+
+	// Wait until the entire control frame is received
+	frame, peekErr := c.reader.Peek(headerLen + int(length))
+
+	if peekErr != nil {
+		return peekErr
+	}
+
+	payload := make([]byte, length)
+
+	copy(payload, frame[headerLen:])
+
+	c.reader.Discard(headerLen + int(length))
+
+	if masked {
+		for pIdx := range payload {
+			payload[pIdx] ^= mask[pIdx%len(mask)]
+		}
+	}
+
+	switch opcode {
+	case opPing:
+		return c.writeFrame(opPong, payload)
+
+	case opClose:
+		c.closeReceived = true
+
+		// Echo the status code back as the reply
+		if len(payload) > 2 {
+			payload = payload[:2]
+		}
+
+		c.writeFrame(opClose, payload)
+
+		return io.EOF
+	}
+
+	return nil
+}
+
+// writeFrame writes a single frame
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	// Nothing can be sent after the close frame
+	if c.closeSent {
+		return io.ErrClosedPipe
+	}
+
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	frameLen := frameMaxHeader + len(payload)
+
+	if cap(c.writeBuf) < frameLen {
+		c.writeBuf = make([]byte, frameLen)
+	}
+
+	buf := c.writeBuf[:frameLen]
+
+	buf[0] = frameFin | opcode
+	headerLen := 2
+
+	switch {
+	case len(payload) < frameLength16:
+		buf[1] = byte(len(payload))
+
+	case len(payload) <= 0xffff:
+		buf[1] = frameLength16
+		binary.BigEndian.PutUint16(buf[2:4], uint16(len(payload)))
+		headerLen += 2
+
+	default:
+		buf[1] = frameLength64
+		binary.BigEndian.PutUint64(buf[2:10], uint64(len(payload)))
+		headerLen += 8
+	}
+
+	if !c.client {
+		copy(buf[headerLen:], payload)
+
+		_, wErr := c.Conn.Write(buf[:headerLen+len(payload)])
+
+		return wErr
+	}
+
+	buf[1] |= frameMask
+
+	mask := buf[headerLen : headerLen+4]
+
+	_, rErr := rand.Read(mask)
+
+	if rErr != nil {
+		return rErr
+	}
+
+	headerLen += 4
+
+	for pIdx := range payload {
+		buf[headerLen+pIdx] = payload[pIdx] ^ mask[pIdx%len(mask)]
+	}
+
+	_, wErr := c.Conn.Write(buf[:headerLen+len(payload)])
+
+	return wErr
+}
+
+// Write writes data as a binary frame
+func (c *Conn) Write(b []byte) (int, error) {
+	hsErr := c.Handshake()
+
+	if hsErr != nil {
+		return 0, hsErr
+	}
+
+	wErr := c.writeFrame(opBinary, b)
+
+	if wErr != nil {
+		return 0, wErr
+	}
+
+	return len(b), nil
+}
+
+// Close sends a close frame (when possible) and closes the connection.
+// It won't wait for a pending handshake, closing the connection will
+// abort it instead
+func (c *Conn) Close() error {
+	if atomic.LoadUint32(&c.established) == 1 {
+		c.Conn.SetWriteDeadline(time.Now().Add(closeWaitTimeout))
+
+		c.writeFrame(opClose, nil)
+	}
+
+	return c.Conn.Close()
+}
@@ -0,0 +1,348 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testPipe(serverPath string, cfg Config) (*Conn, *Conn) {
+	serverConn, clientConn := net.Pipe()
+
+	return Server(serverConn, serverPath), Client(clientConn, cfg)
+}
+
+func TestConnEcho(t *testing.T) {
+	server, client := testPipe("/ws", Config{
+		Path:    "/ws",
+		Host:    "example.com",
+		Headers: nil,
+	})
+
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+
+		io.Copy(server, server)
+	}()
+
+	for _, size := range []int{1, 125, 126, 200, 65535, 65536, 100000} {
+		data := make([]byte, size)
+
+		for dIdx := range data {
+			data[dIdx] = byte(dIdx % 251)
+		}
+
+		go client.Write(data)
+
+		received := make([]byte, size)
+
+		_, rErr := io.ReadFull(client, received)
+
+		if rErr != nil {
+			t.Errorf("Failed to read %d bytes due to error: %s", size, rErr)
+
+			return
+		}
+
+		if !bytes.Equal(received, data) {
+			t.Errorf("Received data of %d bytes is different from sent",
+				size)
+
+			return
+		}
+	}
+}
+
+func TestConnPing(t *testing.T) {
+	server, client := testPipe("", Config{
+		Path:    "/",
+		Host:    "example.com",
+		Headers: nil,
+	})
+
+	defer client.Close()
+
+	go func() {
+		defer server.Close()
+
+		io.Copy(server, server)
+	}()
+
+	hsErr := client.Handshake()
+
+	if hsErr != nil {
+		t.Error("Failed to handshake due to error:", hsErr)
+
+		return
+	}
+
+	pingErr := client.writeFrame(opPing, []byte("Ping"))
+
+	if pingErr != nil {
+		t.Error("Failed to send ping due to error:", pingErr)
+
+		return
+	}
+
+	go client.Write([]byte("Hello World"))
+
+	received := make([]byte, 11)
+
+	_, rErr := io.ReadFull(client, received)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if string(received) != "Hello World" {
+		t.Errorf("Expecting to receive %q, got %q", "Hello World", received)
+	}
+}
+
+func TestConnClose(t *testing.T) {
+	server, client := testPipe("", Config{
+		Path:    "/",
+		Host:    "example.com",
+		Headers: nil,
+	})
+
+	readResult := make(chan error)
+
+	go func() {
+		defer server.Close()
+
+		_, rErr := server.Read(make([]byte, 1))
+
+		readResult <- rErr
+	}()
+
+	hsErr := client.Handshake()
+
+	if hsErr != nil {
+		t.Error("Failed to handshake due to error:", hsErr)
+
+		return
+	}
+
+	client.Close()
+
+	rErr := <-readResult
+
+	if rErr != io.EOF {
+		t.Errorf("Expecting server to read %s, got %s", io.EOF, rErr)
+	}
+}
+
+func TestConnClosePendingHandshake(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+
+	defer clientConn.Close()
+
+	server := Server(serverConn, "")
+
+	hsResult := make(chan error)
+
+	go func() {
+		hsResult <- server.Handshake()
+	}()
+
+	// Give the handshake some time to start waiting for the request
+	time.Sleep(100 * time.Millisecond)
+
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		server.Close()
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(1 * time.Second):
+		t.Error("Expecting Close not to wait for the pending handshake")
+
+		return
+	}
+
+	if <-hsResult == nil {
+		t.Error("Expecting the pending handshake to fail once closed")
+
+		return
+	}
+}
+
+func TestConnHandshakeTooLarge(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+
+	defer clientConn.Close()
+
+	server := Server(serverConn, "")
+
+	defer server.Close()
+
+	hsResult := make(chan error)
+
+	go func() {
+		hsResult <- server.Handshake()
+	}()
+
+	go clientConn.Write([]byte("GET / HTTP/1.1\r\nX-Padding: " +
+		strings.Repeat("A", 2*handshakeMaxRequest) + "\r\n\r\n"))
+
+	resp, respErr := http.ReadResponse(bufio.NewReader(clientConn), nil)
+
+	if respErr != nil {
+		t.Error("Failed to read response due to error:", respErr)
+
+		return
+	}
+
+	if resp.StatusCode != http.StatusRequestHeaderFieldsTooLarge {
+		t.Errorf("Expecting status %d, got %d",
+			http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+
+		return
+	}
+
+	hsErr := <-hsResult
+
+	if hsErr != ErrHandshakeRequestTooLarge {
+		t.Errorf("Expecting server to fail with error %s, got %s",
+			ErrHandshakeRequestTooLarge, hsErr)
+
+		return
+	}
+}
+
+func TestConnPathMismatched(t *testing.T) {
+	server, client := testPipe("/ws", Config{
+		Path:    "/other",
+		Host:    "example.com",
+		Headers: nil,
+	})
+
+	serverResult := make(chan error)
+
+	go func() {
+		defer server.Close()
+
+		serverResult <- server.Handshake()
+	}()
+
+	hsErr := client.Handshake()
+
+	if hsErr != ErrHandshakeInvalidResponse {
+		t.Errorf("Expecting client to fail with error %s, got %s",
+			ErrHandshakeInvalidResponse, hsErr)
+	}
+
+	serverErr := <-serverResult
+
+	if serverErr != ErrHandshakePathMismatched {
+		t.Errorf("Expecting server to fail with error %s, got %s",
+			ErrHandshakePathMismatched, serverErr)
+	}
+
+	client.Close()
+}
+
+func TestConnUnmasked(t *testing.T) {
+	server, client := testPipe("", Config{
+		Path:    "/",
+		Host:    "example.com",
+		Headers: nil,
+	})
+
+	defer client.Close()
+
+	readResult := make(chan error)
+
+	go func() {
+		defer server.Close()
+
+		_, rErr := server.Read(make([]byte, 1))
+
+		readResult <- rErr
+	}()
+
+	hsErr := client.Handshake()
+
+	if hsErr != nil {
+		t.Error("Failed to handshake due to error:", hsErr)
+
+		return
+	}
+
+	// Frame sent without mask
+	client.Conn.Write([]byte{frameFin | opBinary, 1, 'A'})
+
+	rErr := <-readResult
+
+	if rErr != ErrFrameUnmasked {
+		t.Errorf("Expecting server to fail with error %s, got %s",
+			ErrFrameUnmasked, rErr)
+	}
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, parseErr := ParseHeaders([]string{
+		"X-Forwarded-For: 127.0.0.1",
+		"User-Agent:Test",
+		"x-forwarded-for: 127.0.0.2",
+	})
+
+	if parseErr != nil {
+		t.Error("Failed to parse headers due to error:", parseErr)
+
+		return
+	}
+
+	if headers.Get("User-Agent") != "Test" {
+		t.Errorf("Expecting User-Agent to be %q, got %q",
+			"Test", headers.Get("User-Agent"))
+	}
+
+	if len(headers["X-Forwarded-For"]) != 2 {
+		t.Errorf("Expecting 2 X-Forwarded-For headers, got %d",
+			len(headers["X-Forwarded-For"]))
+	}
+
+	for _, invalid := range []string{"Invalid", ": Value", "A B: C"} {
+		_, parseErr = ParseHeaders([]string{invalid})
+
+		if parseErr != ErrHeaderInvalid {
+			t.Errorf("Expecting parse %q to fail with error %s, got %s",
+				invalid, ErrHeaderInvalid, parseErr)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Errors
+var (
+	ErrHandshakeInvalidRequest = errors.New(
+		"Invalid WebSocket handshake request")
+
+	ErrHandshakePathMismatched = errors.New(
+		"WebSocket handshake request was sent to an unknown path")
+
+	ErrHandshakeInvalidResponse = errors.New(
+		"Invalid WebSocket handshake response")
+
+	ErrHeaderInvalid = errors.New(
+		"Header must be in \"<Name>: <Value>\" format")
+
+	ErrHandshakeRequestTooLarge = errors.New(
+		"WebSocket handshake request is too large")
+)
+
+// Consts
+const (
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	// handshakeMaxRequest is the max size of a handshake request, same as
+	// the http.DefaultMaxHeaderBytes of net/http
+	handshakeMaxRequest = 8 * 1024
+
+	// DefaultPath is the path that will be used when no path is specified
+	DefaultPath = "/"
+)
+
+// Config is the client side WebSocket configuration
+type Config struct {
+	Path    string
+	Host    string
+	Headers http.Header
+}
+
+// ParseHeaders parses "<Name>: <Value>" lines into a http.Header
+func ParseHeaders(lines []string) (http.Header, error) {
+	headers := http.Header{}
+
+	for lIdx := range lines {
+		sepIdx := strings.Index(lines[lIdx], ":")
+
+		if sepIdx <= 0 {
+			return nil, ErrHeaderInvalid
+		}
+
+		name := strings.TrimSpace(lines[lIdx][:sepIdx])
+
+		if name == "" || strings.ContainsAny(name, " \t\r\n") {
+			return nil, ErrHeaderInvalid
+		}
+
+		headers.Add(name, strings.TrimSpace(lines[lIdx][sepIdx+1:]))
+	}
+
+	return headers, nil
+}
+
+// handshakeLimiter limits how many bytes can be read from the remote
+// before the handshake is completed
+type handshakeLimiter struct {
+	reader    io.Reader
+	remaining int
+	limited   bool
+}
+
+// Read reads from the underlying reader
+func (h *handshakeLimiter) Read(b []byte) (int, error) {
+	if !h.limited {
+		return h.reader.Read(b)
+	}
+
+	if h.remaining <= 0 {
+		return 0, ErrHandshakeRequestTooLarge
+	}
+
+	if len(b) > h.remaining {
+		b = b[:h.remaining]
+	}
+
+	rLen, rErr := h.reader.Read(b)
+
+	h.remaining -= rLen
+
+	return rLen, rErr
+}
+
+// Server creates a server side WebSocket connection. The handshake
+// request must be sent to the given path, or any path when path is empty
+func Server(conn net.Conn, path string) *Conn {
+	limiter := &handshakeLimiter{
+		reader:    conn,
+		remaining: handshakeMaxRequest,
+		limited:   true,
+	}
+
+	return &Conn{
+		Conn:   conn,
+		reader: bufio.NewReader(limiter),
+		client: false,
+		handshaker: func(c *Conn) error {
+			// Handshake is always performed before any frame is read, and
+			// the limiter is no longer needed once it's done
+			defer func() {
+				limiter.limited = false
+			}()
+
+			return c.serverHandshake(path)
+		},
+	}
+}
+
+// Client creates a client side WebSocket connection
+func Client(conn net.Conn, cfg Config) *Conn {
+	return &Conn{
+		Conn:   conn,
+		reader: bufio.NewReader(conn),
+		client: true,
+		handshaker: func(c *Conn) error {
+			return c.clientHandshake(cfg)
+		},
+	}
+}
+
+// acceptKey calculates the Sec-WebSocket-Accept value of a key
+func acceptKey(key string) string {
+	hashed := sha1.Sum([]byte(key + acceptGUID))
+
+	return base64.StdEncoding.EncodeToString(hashed[:])
+}
+
+// headerContains checks whether or not the comma separated header value
+// contains the given token
+func headerContains(h http.Header, name string, token string) bool {
+	for _, value := range h[http.CanonicalHeaderKey(name)] {
+		for _, v := range strings.Split(value, ",") {
+			if !strings.EqualFold(strings.TrimSpace(v), token) {
+				continue
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeStatus writes a HTTP response with only status
+func (c *Conn) writeStatus(status int) {
+	c.Conn.Write([]byte("HTTP/1.1 " + strconv.Itoa(status) + " " +
+		http.StatusText(status) + "\r\n" +
+		"Connection: close\r\nContent-Length: 0\r\n\r\n"))
+}
+
+func (c *Conn) serverHandshake(path string) error {
+	req, reqErr := http.ReadRequest(c.reader)
+
+	if errors.Is(reqErr, ErrHandshakeRequestTooLarge) {
+		c.writeStatus(http.StatusRequestHeaderFieldsTooLarge)
+
+		return ErrHandshakeRequestTooLarge
+	}
+
+	if reqErr != nil {
+		return reqErr
+	}
+
+	key := req.Header.Get("Sec-WebSocket-Key")
+
+	if req.Method != http.MethodGet ||
+		!headerContains(req.Header, "Upgrade", "websocket") ||
+		!headerContains(req.Header, "Connection", "upgrade") ||
+		req.Header.Get("Sec-WebSocket-Version") != "13" || key == "" {
+		c.writeStatus(http.StatusBadRequest)
+
+		return ErrHandshakeInvalidRequest
+	}
+
+	if path != "" && req.URL.Path != path {
+		c.writeStatus(http.StatusNotFound)
+
+		return ErrHandshakePathMismatched
+	}
+
+	_, wErr := c.Conn.Write([]byte("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"))
+
+	return wErr
+}
+
+func (c *Conn) clientHandshake(cfg Config) error {
+	var rawKey [16]byte
+
+	_, rErr := rand.Read(rawKey[:])
+
+	if rErr != nil {
+		return rErr
+	}
+
+	key := base64.StdEncoding.EncodeToString(rawKey[:])
+
+	path := cfg.Path
+
+	if path == "" {
+		path = DefaultPath
+	}
+
+	req := bytes.NewBuffer(make([]byte, 0, 512))
+
+	req.WriteString("GET " + path + " HTTP/1.1\r\n")
+	req.WriteString("Host: " + cfg.Host + "\r\n")
+	req.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	req.WriteString("Sec-WebSocket-Key: " + key + "\r\n")
+	req.WriteString("Sec-WebSocket-Version: 13\r\n")
+
+	cfg.Headers.Write(req)
+
+	req.WriteString("\r\n")
+
+	_, wErr := c.Conn.Write(req.Bytes())
+
+	if wErr != nil {
+		return wErr
+	}
+
+	resp, respErr := http.ReadResponse(c.reader, nil)
+
+	if respErr != nil {
+		return respErr
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!headerContains(resp.Header, "Upgrade", "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		return ErrHandshakeInvalidResponse
+	}
+
+	return nil
+}
@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/worker"
 )
@@ -90,6 +91,8 @@ type Client interface {
 type relay struct {
 	logger           logger.Logger
 	runner           worker.Runner
+	sent             metrics.Counter
+	received         metrics.Counter
 	mode             Ticker
 	server           rw.ReadWriteDepleteDoner
 	serverBuffer     []byte
@@ -108,15 +111,25 @@ type relay struct {
 // New creates a new Relay
 func New(
 	log logger.Logger,
+	m metrics.Registry,
 	runner worker.Runner,
 	server rw.ReadWriteDepleteDoner,
 	serverBuffer []byte,
 	clientBuilder Client,
 	clientBuffer []byte,
 ) Relay {
+	const bytesHelp = "Total number of bytes relayed"
+
+	sent := m.Counter("coward_relay_bytes_total", bytesHelp,
+		metrics.L("direction", "out"))
+	received := m.Counter("coward_relay_bytes_total", bytesHelp,
+		metrics.L("direction", "in"))
+
 	return &relay{
 		logger:           log.Context("Relay"),
 		runner:           runner,
+		sent:             sent,
+		received:         received,
 		mode:             nil,
 		server:           server,
 		serverBuffer:     serverBuffer,
@@ -335,6 +348,8 @@ func (r *relay) clientReceiver(l logger.Logger) error {
 				return wErr
 			}
 
+			r.sent.Add(uint64(rLen))
+
 			continue
 		}
 
@@ -362,6 +377,8 @@ func (r *relay) serverReceiver(cil io.ReadWriteCloser) error {
 			return rErr
 		}
 
+		r.received.Add(uint64(rLen))
+
 		rw.WriteFull(cil, r.serverBuffer[:rLen])
 	}
 }
@@ -27,6 +27,7 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 )
@@ -191,10 +192,11 @@ func TestRelayRelay(t *testing.T) {
 		w:     bytes.NewBuffer(make([]byte, 0, 4096)),
 		count: make(chan struct{}, 1),
 	}
+	registry := metrics.NewDitch()
 	relay1ClientReading := make(chan io.Reader)
 	relay1ClientSends := bytes.NewBuffer(make([]byte, 0, 4096))
 
-	relay1 := New(logger.NewDitch(), runner, &dummyServerConn{
+	relay1 := New(logger.NewDitch(), registry, runner, &dummyServerConn{
 		r: relay1Reading,
 		w: relay1Sends,
 	}, clientBuffer1[:], &dummyClientBuilder1{
@@ -260,7 +262,7 @@ func TestRelayRelay(t *testing.T) {
 	relay2ClientReading := make(chan io.Reader)
 	relay2ClientSends := bytes.NewBuffer(make([]byte, 0, 4096))
 
-	relay2 := New(logger.NewDitch(), runner, &dummyServerConn{
+	relay2 := New(logger.NewDitch(), registry, runner, &dummyServerConn{
 		r: relay2Reading,
 		w: relay2Sends,
 	}, clientBuffer2[:], &dummyClientBuilder1{
@@ -0,0 +1,329 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package transceiver
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+)
+
+// Errors
+var (
+	ErrCodecNotFound = errors.New(
+		"Specified Codec was not found")
+
+	ErrCodecChainEmpty = errors.New(
+		"Codec chain must contain at least one Codec")
+
+	ErrCodecChainSettingUnsectioned = errors.New(
+		"Setting of a Codec chain must be sectioned by \"[<Codec>]\" lines")
+
+	ErrCodecChainSettingUnknownSection = errors.New(
+		"Setting section doesn't belong to any Codec in the chain")
+)
+
+// Consts
+const (
+	CodecChainSeparator = ","
+)
+
+type chain struct {
+	layers []rw.Codec
+}
+
+type handshakingChain struct {
+	chain
+}
+
+type chainConn struct {
+	chain chain
+	conn  io.ReadWriter
+}
+
+// SelectCodec looks up the Codecs registered in components by the
+// names in the given list, and returns a Codec which chains them in
+// the listed order
+func SelectCodec(names string, components []interface{}) (Codec, error) {
+	codecs := []Codec{}
+
+	for _, name := range strings.Split(names, CodecChainSeparator) {
+		name = strings.TrimSpace(name)
+		found := false
+
+		for cIdx := range components {
+			codecBuilder, isCodecBuilder :=
+				components[cIdx].(func() Codec)
+
+			if !isCodecBuilder {
+				continue
+			}
+
+			codecInfo := codecBuilder()
+
+			if codecInfo.Name != name {
+				continue
+			}
+
+			codecs = append(codecs, codecInfo)
+			found = true
+
+			break
+		}
+
+		if !found {
+			return Codec{}, ErrCodecNotFound
+		}
+	}
+
+	return ChainCodecs(codecs...)
+}
+
+// ChainCodecs returns a Codec which stacks given Codecs. Data will be
+// encoded by the first Codec, then the second and so on before it
+// reaches the connection, and decoded in the reverse order.
+//
+// Each Codec receives its own setting, which is the lines that
+// follows a "[<Codec Name>]" line in the configuration. When a Codec
+// appeared in the chain more than once, the Nth section of the name
+// belongs to the Nth occurrence of the Codec
+func ChainCodecs(codecs ...Codec) (Codec, error) {
+	if len(codecs) <= 0 {
+		return Codec{}, ErrCodecChainEmpty
+	}
+
+	if len(codecs) == 1 {
+		return codecs[0], nil
+	}
+
+	names := make([]string, len(codecs))
+	usages := make([]string, len(codecs))
+
+	for cIdx := range codecs {
+		names[cIdx] = codecs[cIdx].Name
+		usages[cIdx] = "[" + codecs[cIdx].Name + "]\r\n" + codecs[cIdx].Usage
+	}
+
+	return Codec{
+		Name:  strings.Join(names, CodecChainSeparator+" "),
+		Usage: strings.Join(usages, "\r\n\r\n"),
+		Build: func(
+			configuration []string,
+			log logger.Logger,
+			m metrics.Registry,
+		) CodecBuilder {
+			settings, settingErr := chainSettings(codecs, configuration)
+
+			if settingErr != nil {
+				panic("Failed to build Codec chain due to error: " +
+					settingErr.Error())
+			}
+
+			builders := make([]CodecBuilder, len(codecs))
+
+			for cIdx := range codecs {
+				builders[cIdx] = codecs[cIdx].Build(
+					settings[cIdx], log, m)
+			}
+
+			return chainBuilder(builders)
+		},
+		Verify: func(configuration []string) error {
+			settings, settingErr := chainSettings(codecs, configuration)
+
+			if settingErr != nil {
+				return settingErr
+			}
+
+			for cIdx := range codecs {
+				if codecs[cIdx].Verify == nil {
+					continue
+				}
+
+				vErr := codecs[cIdx].Verify(settings[cIdx])
+
+				if vErr == nil {
+					continue
+				}
+
+				return errors.New(codecs[cIdx].Name + ": " + vErr.Error())
+			}
+
+			return nil
+		},
+	}, nil
+}
+
+// chainSettings splits the configuration into settings of each Codec
+func chainSettings(
+	codecs []Codec, configuration []string) ([][]string, error) {
+	settings := make([][]string, len(codecs))
+	sections := map[string]int{}
+	current := -1
+
+	for _, line := range configuration {
+		trimmed := strings.TrimSpace(line)
+		trimmedLen := len(trimmed)
+
+		if trimmedLen < 2 || trimmed[0] != '[' ||
+			trimmed[trimmedLen-1] != ']' {
+			if current >= 0 {
+				settings[current] = append(settings[current], line)
+
+				continue
+			}
+
+			if trimmedLen <= 0 {
+				continue
+			}
+
+			return nil, ErrCodecChainSettingUnsectioned
+		}
+
+		name := strings.TrimSpace(trimmed[1 : trimmedLen-1])
+		occurrence := sections[name]
+		current = -1
+
+		for cIdx := range codecs {
+			if codecs[cIdx].Name != name {
+				continue
+			}
+
+			if occurrence > 0 {
+				occurrence--
+
+				continue
+			}
+
+			current = cIdx
+
+			break
+		}
+
+		if current < 0 {
+			return nil, ErrCodecChainSettingUnknownSection
+		}
+
+		sections[name]++
+	}
+
+	return settings, nil
+}
+
+// chainBuilder builds all layers of a chain
+func chainBuilder(builders []CodecBuilder) CodecBuilder {
+	return func() (rw.Codec, error) {
+		c := chain{
+			layers: make([]rw.Codec, len(builders)),
+		}
+
+		handshaking := false
+
+		for bIdx := range builders {
+			layer, buildErr := builders[bIdx]()
+
+			if buildErr != nil {
+				return nil, buildErr
+			}
+
+			_, isHandshaker := layer.(CodecHandshaker)
+
+			if isHandshaker {
+				handshaking = true
+			}
+
+			c.layers[bIdx] = layer
+		}
+
+		if !handshaking {
+			return c, nil
+		}
+
+		return handshakingChain{chain: c}, nil
+	}
+}
+
+func (c chain) Encode(w io.Writer) rw.WriteWriteAll {
+	last := len(c.layers) - 1
+	encoder := c.layers[last].Encode(w)
+
+	for lIdx := last - 1; lIdx >= 0; lIdx-- {
+		encoder = c.layers[lIdx].Encode(encoder)
+	}
+
+	return encoder
+}
+
+func (c chain) Decode(r io.Reader) io.Reader {
+	last := len(c.layers) - 1
+	decoder := c.layers[last].Decode(r)
+
+	for lIdx := last - 1; lIdx >= 0; lIdx-- {
+		decoder = c.layers[lIdx].Decode(decoder)
+	}
+
+	return decoder
+}
+
+// Handshake completes the handshake of every CodecHandshaker layer,
+// starting from the one closest to the connection. The handshake data
+// of a layer will be carried by the layers which follows it
+func (h handshakingChain) Handshake(
+	conn io.ReadWriter, initiator bool) error {
+	for lIdx := len(h.layers) - 1; lIdx >= 0; lIdx-- {
+		handshaker, isHandshaker := h.layers[lIdx].(CodecHandshaker)
+
+		if !isHandshaker {
+			continue
+		}
+
+		hsErr := handshaker.Handshake(chainConn{
+			chain: chain{layers: h.layers[lIdx+1:]},
+			conn:  conn,
+		}, initiator)
+
+		if hsErr != nil {
+			return hsErr
+		}
+	}
+
+	return nil
+}
+
+func (c chainConn) Read(b []byte) (int, error) {
+	if len(c.chain.layers) <= 0 {
+		return c.conn.Read(b)
+	}
+
+	return c.chain.Decode(c.conn).Read(b)
+}
+
+func (c chainConn) Write(b []byte) (int, error) {
+	if len(c.chain.layers) <= 0 {
+		return c.conn.Write(b)
+	}
+
+	return c.chain.Encode(c.conn).Write(b)
+}
@@ -0,0 +1,366 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package transceiver
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+)
+
+type dummyChainCodec struct {
+	mask byte
+}
+
+type dummyChainEncoder struct {
+	mask byte
+	w    io.Writer
+}
+
+type dummyChainDecoder struct {
+	mask byte
+	r    io.Reader
+}
+
+type dummyChainHandshaker struct {
+	dummyChainCodec
+
+	hello []byte
+}
+
+func (d dummyChainCodec) Encode(w io.Writer) rw.WriteWriteAll {
+	return dummyChainEncoder{mask: d.mask, w: w}
+}
+
+func (d dummyChainCodec) Decode(r io.Reader) io.Reader {
+	return dummyChainDecoder{mask: d.mask, r: r}
+}
+
+func (d dummyChainEncoder) Write(b []byte) (int, error) {
+	return d.WriteAll(b)
+}
+
+func (d dummyChainEncoder) WriteAll(b ...[]byte) (int, error) {
+	masked := []byte{}
+
+	for bIdx := range b {
+		for _, bb := range b[bIdx] {
+			masked = append(masked, bb^d.mask)
+		}
+	}
+
+	return d.w.Write(masked)
+}
+
+func (d dummyChainDecoder) Read(b []byte) (int, error) {
+	rLen, rErr := d.r.Read(b)
+
+	for bIdx := range b[:rLen] {
+		b[bIdx] ^= d.mask
+	}
+
+	return rLen, rErr
+}
+
+func (d *dummyChainHandshaker) Handshake(
+	conn io.ReadWriter, initiator bool) error {
+	if initiator {
+		_, wErr := conn.Write([]byte("HELLO"))
+
+		return wErr
+	}
+
+	d.hello = make([]byte, 5)
+
+	_, rErr := io.ReadFull(conn, d.hello)
+
+	return rErr
+}
+
+func dummyChainCodecInfo(name string, mask byte) Codec {
+	return Codec{
+		Name:  name,
+		Usage: "",
+		Build: func(
+			configuration []string,
+			log logger.Logger,
+			m metrics.Registry,
+		) CodecBuilder {
+			selected := mask
+
+			if len(configuration) > 0 {
+				selected = configuration[0][0]
+			}
+
+			return func() (rw.Codec, error) {
+				return dummyChainCodec{mask: selected}, nil
+			}
+		},
+		Verify: func(configuration []string) error {
+			if len(configuration) > 1 {
+				return errors.New("Too many settings")
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestSelectCodec(t *testing.T) {
+	components := []interface{}{
+		func() Codec { return dummyChainCodecInfo("a", 1) },
+		"Not a Codec",
+		func() Codec { return dummyChainCodecInfo("b", 2) },
+	}
+
+	single, singleErr := SelectCodec("b", components)
+
+	if singleErr != nil {
+		t.Error("Failed to select Codec due to error:", singleErr)
+
+		return
+	}
+
+	if single.Name != "b" {
+		t.Errorf("Expecting Codec \"b\", got %s", single.Name)
+
+		return
+	}
+
+	chained, chainErr := SelectCodec("a, b , a", components)
+
+	if chainErr != nil {
+		t.Error("Failed to select Codec due to error:", chainErr)
+
+		return
+	}
+
+	if chained.Name != "a, b, a" {
+		t.Errorf("Expecting Codec \"a, b, a\", got %s", chained.Name)
+
+		return
+	}
+
+	_, notFoundErr := SelectCodec("a, c", components)
+
+	if notFoundErr != ErrCodecNotFound {
+		t.Errorf("Expecting error %s, got %s",
+			ErrCodecNotFound, notFoundErr)
+
+		return
+	}
+}
+
+func TestChainCodecsSetting(t *testing.T) {
+	chained, chainErr := ChainCodecs(
+		dummyChainCodecInfo("a", 1),
+		dummyChainCodecInfo("b", 2),
+		dummyChainCodecInfo("a", 3))
+
+	if chainErr != nil {
+		t.Error("Failed to chain Codecs due to error:", chainErr)
+
+		return
+	}
+
+	settings, settingErr := chainSettings([]Codec{
+		dummyChainCodecInfo("a", 1),
+		dummyChainCodecInfo("b", 2),
+		dummyChainCodecInfo("a", 3),
+	}, []string{"", "[a]", "1", "[ b ]", "2", "[a]", "3"})
+
+	if settingErr != nil {
+		t.Error("Failed to split setting due to error:", settingErr)
+
+		return
+	}
+
+	for sIdx, expected := range []string{"1", "2", "3"} {
+		if len(settings[sIdx]) != 1 || settings[sIdx][0] != expected {
+			t.Errorf("Expecting setting %d to be %s, got %v",
+				sIdx, expected, settings[sIdx])
+
+			return
+		}
+	}
+
+	tests := []struct {
+		Setting  []string
+		Expected bool
+	}{
+		{Setting: []string{}, Expected: true},
+		{Setting: []string{"[b]", "X"}, Expected: true},
+		{Setting: []string{"X", "[b]"}, Expected: false},
+		{Setting: []string{"[c]", "X"}, Expected: false},
+		{Setting: []string{"[b]", "X", "[b]"}, Expected: false},
+		{Setting: []string{"[b]", "X", "Y"}, Expected: false},
+	}
+
+	for tIdx, test := range tests {
+		vErr := chained.Verify(test.Setting)
+
+		if (vErr == nil) != test.Expected {
+			t.Errorf("Test %d: Unexpected verify result: %v", tIdx, vErr)
+
+			return
+		}
+	}
+}
+
+func TestChainCodecsEncodeDecode(t *testing.T) {
+	chained, chainErr := ChainCodecs(
+		dummyChainCodecInfo("a", 1),
+		dummyChainCodecInfo("b", 2))
+
+	if chainErr != nil {
+		t.Error("Failed to chain Codecs due to error:", chainErr)
+
+		return
+	}
+
+	codec, buildErr := chained.Build(
+		[]string{"[a]", "\x10", "[b]", "\x20"}, nil, nil)()
+
+	if buildErr != nil {
+		t.Error("Failed to build Codec due to error:", buildErr)
+
+		return
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 64))
+
+	_, wErr := codec.Encode(buf).WriteAll([]byte("Hello"), []byte("World"))
+
+	if wErr != nil {
+		t.Error("Failed to write due to error:", wErr)
+
+		return
+	}
+
+	expectedEncoded := []byte("HelloWorld")
+
+	for eIdx := range expectedEncoded {
+		expectedEncoded[eIdx] ^= 0x10 ^ 0x20
+	}
+
+	if !bytes.Equal(buf.Bytes(), expectedEncoded) {
+		t.Errorf("Expecting encoded data to be %d, got %d",
+			expectedEncoded, buf.Bytes())
+
+		return
+	}
+
+	decoded := make([]byte, 10)
+
+	_, rErr := io.ReadFull(codec.Decode(buf), decoded)
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	if !bytes.Equal(decoded, []byte("HelloWorld")) {
+		t.Errorf("Expecting decoded data to be %s, got %s",
+			"HelloWorld", decoded)
+
+		return
+	}
+}
+
+func TestChainCodecsHandshake(t *testing.T) {
+	server := &dummyChainHandshaker{
+		dummyChainCodec: dummyChainCodec{mask: 0x30},
+	}
+
+	c, buildErr := chainBuilder([]CodecBuilder{
+		func() (rw.Codec, error) { return server, nil },
+		func() (rw.Codec, error) {
+			return dummyChainCodec{mask: 0x40}, nil
+		},
+	})()
+
+	if buildErr != nil {
+		t.Error("Failed to build Codec due to error:", buildErr)
+
+		return
+	}
+
+	handshaker, isHandshaker := c.(CodecHandshaker)
+
+	if !isHandshaker {
+		t.Error("Chain with a handshaking layer must be a CodecHandshaker")
+
+		return
+	}
+
+	hello := []byte("HELLO")
+
+	for hIdx := range hello {
+		hello[hIdx] ^= 0x40
+	}
+
+	conn := bytes.NewBuffer(hello)
+
+	hsErr := handshaker.Handshake(conn, false)
+
+	if hsErr != nil {
+		t.Error("Failed to handshake due to error:", hsErr)
+
+		return
+	}
+
+	if !bytes.Equal(server.hello, []byte("HELLO")) {
+		t.Errorf("Expecting handshake data to be %s, got %s",
+			"HELLO", server.hello)
+
+		return
+	}
+
+	plain, plainErr := chainBuilder([]CodecBuilder{
+		func() (rw.Codec, error) {
+			return dummyChainCodec{mask: 0x40}, nil
+		},
+		func() (rw.Codec, error) {
+			return dummyChainCodec{mask: 0x50}, nil
+		},
+	})()
+
+	if plainErr != nil {
+		t.Error("Failed to build Codec due to error:", plainErr)
+
+		return
+	}
+
+	_, isHandshaker = plain.(CodecHandshaker)
+
+	if isHandshaker {
+		t.Error("Chain without handshaking layer must not be a " +
+			"CodecHandshaker")
+
+		return
+	}
+}
@@ -58,6 +58,7 @@ type Requester interface {
 	Full() bool
 	Request(
 		log logger.Logger,
+		destName Destination,
 		req RequestBuilder,
 		cancel <-chan struct{},
 		m Meter) (bool, error)
@@ -28,6 +28,8 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/roles/common/channel"
@@ -122,6 +124,7 @@ type connectRequestResult struct {
 // virtualChannel is the data of a Virtual Channel
 type virtualChannel struct {
 	ConnectionID   transceiver.ConnectionID
+	Session        session.ID
 	ChannelID      channel.ID
 	Channel        connection.Virtual
 	Connection     connCtl
@@ -160,6 +163,8 @@ func (d dialers) TotalConcurrentConnections() uint32 {
 type client struct {
 	id                       transceiver.ClientID
 	log                      logger.Logger
+	metrics                  metrics.Registry
+	sessions                 session.Sessions
 	dialers                  []dialer
 	codec                    transceiver.CodecBuilder
 	cfg                      Config
@@ -187,6 +192,8 @@ type client struct {
 func New(
 	clientID transceiver.ClientID,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	d network.Dialer,
 	codec transceiver.CodecBuilder,
 	requestWaitTicker ticker.Requester,
@@ -207,6 +214,8 @@ func New(
 		id: clientID,
 		log: log.Context("Transceiver (" +
 			strconv.FormatUint(uint64(clientID), 10) + ")"),
+		metrics:  m,
+		sessions: s,
 		dialers:  dls,
 		codec:    codec,
 		cfg:      cfg,
@@ -264,7 +273,18 @@ func (c *client) connect(
 
 	tm.Stop()
 
+	c.metrics.Counter(
+		"coward_transceiver_connections_total",
+		"Established Transceiver connections").Add(1)
+
+	connSession := c.sessions.Open(
+		session.Transceiver, 0, dial.String(), "", conn.Close)
+
+	conn = network.Track(conn, connSession)
+
 	defer func() {
+		connSession.Close()
+
 		log.Debugf("Connection lost")
 
 		if !needClose {
@@ -340,7 +360,7 @@ func (c *client) connect(
 	// Init connection
 	channelCreated := 0
 
-	cc, ccErr := connection.Codec(c.codec)
+	cc, ccErr := connection.Codec(c.codec, conn, true, d.InitialTimeout)
 
 	if ccErr != nil {
 		result <- connectRequestResult{
@@ -363,6 +383,7 @@ func (c *client) connect(
 
 		vChannel := virtualChannel{
 			ConnectionID:   connectionID,
+			Session:        connSession.ID(),
 			ChannelID:      id,
 			Channel:        channelized.For(id),
 			Connection:     connCtl{connection: conn},
@@ -775,6 +796,7 @@ func (c *client) Available() bool {
 }
 
 func (c *client) request(
+	destName transceiver.Destination,
 	requestBuilder transceiver.RequestBuilder,
 	cancel <-chan struct{},
 	meter transceiver.Meter,
@@ -830,6 +852,14 @@ func (c *client) request(
 		<-c.connectionEnabled
 	}
 
+	// A single Channel can't be closed without effecting other Channels
+	// of the same Connection, so the Channel session is not killable. Kill
+	// the parent Transceiver session instead
+	chSession := c.sessions.Open(
+		session.Channel, ch.Session, "", string(destName), nil)
+
+	defer chSession.Close()
+
 	reqTimer := meter.Request()
 
 	reqFSM := fsm.New(requestBuilder(
@@ -883,6 +913,7 @@ func (c *client) request(
 // Request sends request
 func (c *client) Request(
 	log logger.Logger,
+	destName transceiver.Destination,
 	requestBuilder transceiver.RequestBuilder,
 	cancel <-chan struct{},
 	meter transceiver.Meter,
@@ -896,9 +927,11 @@ func (c *client) Request(
 	log = log.Context("Transceiver (" +
 		strconv.FormatUint(uint64(c.id), 10) + ")")
 
+	meter = transceiver.MeasureMeter(meter, c.metrics)
+
 	for retried := uint8(0); retried < c.requestRetries; retried++ {
 		retryWait, retriable, wontCount, connLog, err = c.request(
-			requestBuilder, cancel, meter, log)
+			destName, requestBuilder, cancel, meter, log)
 
 		if retryWait != nil {
 			<-retryWait
@@ -28,7 +28,9 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/roles/common/network"
@@ -186,14 +188,15 @@ func TestClientUpDown(t *testing.T) {
 
 	defer requestWaitTicker.Close()
 
-	c := New(0, log, dialer, testDummyEncodec, requestWaitTicker, Config{
-		MaxConcurrent:        100,
-		RequestRetries:       10,
-		InitialTimeout:       1 * time.Second,
-		IdleTimeout:          3 * time.Second,
-		ConnectionPersistent: false,
-		ConnectionChannels:   16,
-	})
+	c := New(0, log, metrics.NewDitch(), session.NewDitch(), dialer,
+		testDummyEncodec, requestWaitTicker, Config{
+			MaxConcurrent:        100,
+			RequestRetries:       10,
+			InitialTimeout:       1 * time.Second,
+			IdleTimeout:          3 * time.Second,
+			ConnectionPersistent: false,
+			ConnectionChannels:   16,
+		})
 
 	served, servErr := c.Serve()
 
@@ -323,14 +326,15 @@ func TestClientUpRequestThenDown(t *testing.T) {
 
 	defer requestWaitTicker.Close()
 
-	c := New(0, log, dialer, testDummyEncodec, requestWaitTicker, Config{
-		MaxConcurrent:        16,
-		RequestRetries:       10,
-		InitialTimeout:       1 * time.Second,
-		IdleTimeout:          3 * time.Second,
-		ConnectionPersistent: false,
-		ConnectionChannels:   16,
-	})
+	c := New(0, log, metrics.NewDitch(), session.NewDitch(), dialer,
+		testDummyEncodec, requestWaitTicker, Config{
+			MaxConcurrent:        16,
+			RequestRetries:       10,
+			InitialTimeout:       1 * time.Second,
+			IdleTimeout:          3 * time.Second,
+			ConnectionPersistent: false,
+			ConnectionChannels:   16,
+		})
 
 	serving, servErr := c.Serve()
 
@@ -341,11 +345,11 @@ func TestClientUpRequestThenDown(t *testing.T) {
 	}
 
 	for i := 0; i < 30; i++ {
-		go serving.Request(log, dummyRequestBuilder(
+		go serving.Request(log, "Test", dummyRequestBuilder(
 			true,
 		), nil, dummyMeter{})
 
-		go serving.Request(log, dummyRequestBuilder(
+		go serving.Request(log, "Test", dummyRequestBuilder(
 			false,
 		), nil, dummyMeter{})
 	}
@@ -378,14 +382,15 @@ func BenchmarkClientRequest(b *testing.B) {
 
 	defer requestWaitTicker.Close()
 
-	c := New(0, log, dialer, testDummyEncodec, requestWaitTicker, Config{
-		MaxConcurrent:        16,
-		RequestRetries:       10,
-		InitialTimeout:       10 * time.Second,
-		IdleTimeout:          10 * time.Second,
-		ConnectionPersistent: false,
-		ConnectionChannels:   16,
-	})
+	c := New(0, log, metrics.NewDitch(), session.NewDitch(), dialer,
+		testDummyEncodec, requestWaitTicker, Config{
+			MaxConcurrent:        16,
+			RequestRetries:       10,
+			InitialTimeout:       10 * time.Second,
+			IdleTimeout:          10 * time.Second,
+			ConnectionPersistent: false,
+			ConnectionChannels:   16,
+		})
 
 	serving, servErr := c.Serve()
 
@@ -401,7 +406,7 @@ func BenchmarkClientRequest(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, reqErr := serving.Request(log, dummyRequestBuilder(
+		_, reqErr := serving.Request(log, "Test", dummyRequestBuilder(
 			false,
 		), nil, dummyMeter{})
 
@@ -0,0 +1,237 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"sync"
+	"time"
+)
+
+// breakerState is the state of a breaker
+type breakerState uint8
+
+// Breaker states
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker is a Circuit Breaker which prevents requests from being sent
+// to a broken Transceiver Client.
+//
+// A Closed breaker allows all requests. After cfg.FailureThreshold
+// consecutive failures it will be Opened, and all requests will be
+// rejected until cfg.OpenTimeout has passed. After that, the breaker
+// become Half-Open, which only admits cfg.HalfOpenTrials concurrent
+// requests to test the Client. Once cfg.HalfOpenTrials requests has
+// succeeded, the breaker will be Closed again, and any failure will
+// re-Open it.
+//
+// A trial is given back once it's request has ended, so requests which
+// ended without reporting a result will not keep the breaker Half-Opened
+type breaker struct {
+	cfg       BreakerConfig
+	state     breakerState
+	failures  uint32
+	successes uint32
+	trials    uint32
+	epoch     uint64
+	openedAt  time.Time
+	lock      sync.Mutex
+}
+
+// newBreaker creates a new breaker
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.HalfOpenTrials <= 0 {
+		cfg.HalfOpenTrials = 1
+	}
+
+	return &breaker{
+		cfg:       cfg,
+		state:     breakerClosed,
+		failures:  0,
+		successes: 0,
+		trials:    0,
+		epoch:     0,
+		openedAt:  time.Time{},
+		lock:      sync.Mutex{},
+	}
+}
+
+// open opens the breaker. Must be called with lock held
+func (b *breaker) open(now time.Time) {
+	b.state = breakerOpen
+	b.failures = 0
+	b.successes = 0
+	b.trials = 0
+	b.openedAt = now
+}
+
+// halfOpen half-opens the breaker. Must be called with lock held
+func (b *breaker) halfOpen() {
+	b.state = breakerHalfOpen
+	b.successes = 0
+	b.trials = 0
+	b.epoch++
+}
+
+// close closes the breaker. Must be called with lock held
+func (b *breaker) close() {
+	b.state = breakerClosed
+	b.failures = 0
+	b.successes = 0
+	b.trials = 0
+}
+
+// Enabled returns whether or not the breaker is enabled
+func (b *breaker) Enabled() bool {
+	return b.cfg.FailureThreshold > 0
+}
+
+// release gives back a trial which was admitted during the given Half-Open
+// epoch
+func (b *breaker) release(epoch uint64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state != breakerHalfOpen || b.epoch != epoch || b.trials <= 0 {
+		return
+	}
+
+	b.trials--
+}
+
+// Allow returns whether or not a request can be sent through, and a
+// function which must be called once the admitted request has ended
+func (b *breaker) Allow(now time.Time) (bool, func()) {
+	if !b.Enabled() {
+		return true, func() {}
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.cfg.OpenTimeout {
+			return false, func() {}
+		}
+
+		b.halfOpen()
+
+		fallthrough
+
+	case breakerHalfOpen:
+		if b.trials >= b.cfg.HalfOpenTrials {
+			return false, func() {}
+		}
+
+		b.trials++
+
+		epoch := b.epoch
+		releaseOnce := sync.Once{}
+
+		return true, func() {
+			releaseOnce.Do(func() { b.release(epoch) })
+		}
+
+	default:
+		return true, func() {}
+	}
+}
+
+// Success reports a successful request or probe, returns true when the
+// breaker has been Closed by this report
+func (b *breaker) Success(now time.Time) bool {
+	if !b.Enabled() {
+		return false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		// The Client is back, start re-admitting requests
+		b.halfOpen()
+
+		fallthrough
+
+	case breakerHalfOpen:
+		b.successes++
+
+		if b.successes < b.cfg.HalfOpenTrials {
+			return false
+		}
+
+		b.close()
+
+		return true
+
+	default:
+		b.failures = 0
+
+		return false
+	}
+}
+
+// Failure reports a failed request or probe, returns true when the
+// breaker has been Opened by this report
+func (b *breaker) Failure(now time.Time) bool {
+	if !b.Enabled() {
+		return false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		b.openedAt = now
+
+		return false
+
+	case breakerHalfOpen:
+		b.open(now)
+
+		return true
+
+	default:
+		b.failures++
+
+		if b.failures < b.cfg.FailureThreshold {
+			return false
+		}
+
+		b.open(now)
+
+		return true
+	}
+}
+
+// Closed returns whether or not the breaker is Closed
+func (b *breaker) Closed() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.state == breakerClosed
+}
@@ -0,0 +1,172 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+
+	b := newBreaker(BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenTrials:   2,
+	})
+
+	if allowed, _ := b.Allow(now); !allowed {
+		t.Error("Expecting a new breaker to allow requests")
+
+		return
+	}
+
+	if b.Failure(now) {
+		t.Error("Expecting the breaker to stay Closed after first failure")
+
+		return
+	}
+
+	if !b.Failure(now) {
+		t.Error("Expecting the breaker to be Opened after second failure")
+
+		return
+	}
+
+	if allowed, _ := b.Allow(now.Add(5 * time.Second)); allowed {
+		t.Error("Expecting an Opened breaker to reject requests")
+
+		return
+	}
+
+	// Half-Open, only 2 concurrent trials is allowed
+	now = now.Add(10 * time.Second)
+
+	allowed1, release1 := b.Allow(now)
+	allowed2, release2 := b.Allow(now)
+
+	if !allowed1 || !allowed2 {
+		t.Error("Expecting a Half-Opened breaker to allow trial requests")
+
+		return
+	}
+
+	if allowed, _ := b.Allow(now); allowed {
+		t.Error("Expecting a Half-Opened breaker to reject requests " +
+			"when all trials are in use")
+
+		return
+	}
+
+	// Trials which ended without reporting a result must not keep the
+	// breaker from admitting new ones
+	release1()
+	release1()
+
+	allowed3, release3 := b.Allow(now)
+
+	if !allowed3 {
+		t.Error("Expecting a Half-Opened breaker to allow trial requests " +
+			"once a trial has ended")
+
+		return
+	}
+
+	if allowed, _ := b.Allow(now); allowed {
+		t.Error("Expecting a trial to be given back only once")
+
+		return
+	}
+
+	defer release2()
+	defer release3()
+
+	if b.Success(now) {
+		t.Error("Expecting the breaker to stay Half-Opened after " +
+			"first successful trial")
+
+		return
+	}
+
+	if !b.Success(now) {
+		t.Error("Expecting the breaker to be Closed after all trials " +
+			"has succeeded")
+
+		return
+	}
+
+	if !b.Closed() {
+		t.Error("Expecting the breaker to be Closed")
+
+		return
+	}
+}
+
+func TestBreakerHalfOpenFailure(t *testing.T) {
+	now := time.Now()
+
+	b := newBreaker(BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      10 * time.Second,
+		HalfOpenTrials:   1,
+	})
+
+	b.Failure(now)
+
+	now = now.Add(10 * time.Second)
+
+	if allowed, _ := b.Allow(now); !allowed {
+		t.Error("Expecting a Half-Opened breaker to allow trial requests")
+
+		return
+	}
+
+	if !b.Failure(now) {
+		t.Error("Expecting the breaker to be re-Opened after a failed trial")
+
+		return
+	}
+
+	if allowed, _ := b.Allow(now.Add(9 * time.Second)); allowed {
+		t.Error("Expecting a re-Opened breaker to reject requests")
+
+		return
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(BreakerConfig{})
+
+	for i := 0; i < 16; i++ {
+		if b.Failure(time.Now()) {
+			t.Error("Expecting a disabled breaker never to be Opened")
+
+			return
+		}
+	}
+
+	if allowed, _ := b.Allow(time.Now()); !allowed {
+		t.Error("Expecting a disabled breaker to allow all requests")
+
+		return
+	}
+}
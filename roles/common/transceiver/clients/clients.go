@@ -39,33 +39,48 @@ var (
 )
 
 type clients struct {
+	log          logger.Logger
+	cfg          Config
 	clients      []transceiver.Client
 	requesters   requesters
 	destinations destinations
+	orderer      orderer
 	requestLock  sync.Mutex
 	bootLock     sync.Mutex
 	booted       bool
+	probeClosing chan struct{}
+	probeWait    sync.WaitGroup
 }
 
 // New creates a new Transceiver Balancer
-func New(clis []transceiver.Client, maxDestinations int) transceiver.Balancer {
+func New(
+	clis []transceiver.Client,
+	log logger.Logger,
+	cfg Config,
+) transceiver.Balancer {
 	return &clients{
+		log:     log.Context("Balancer"),
+		cfg:     cfg,
 		clients: clis,
 		requesters: requesters{
 			req: make([]*requester, len(clis)),
 		},
 		destinations: destinations{
 			dest: make(
-				map[transceiver.Destination]*destination, maxDestinations),
+				map[transceiver.Destination]*destination, cfg.MaxDestinations),
 			expire: expirer{
-				dests:   make([]transceiver.Destination, maxDestinations),
+				dests: make(
+					[]transceiver.Destination, cfg.MaxDestinations),
 				nextIdx: 0,
-				maxSize: maxDestinations,
+				maxSize: cfg.MaxDestinations,
 			},
 		},
-		requestLock: sync.Mutex{},
-		bootLock:    sync.Mutex{},
-		booted:      false,
+		orderer:      nil,
+		requestLock:  sync.Mutex{},
+		bootLock:     sync.Mutex{},
+		booted:       false,
+		probeClosing: nil,
+		probeWait:    sync.WaitGroup{},
 	}
 }
 
@@ -93,14 +108,34 @@ func (c *clients) Serve() (transceiver.Balanced, error) {
 			return nil, reqServErr
 		}
 
+		weight := uint32(1)
+
+		if int(req.ID()) < len(c.cfg.Weights) && c.cfg.Weights[req.ID()] > 0 {
+			weight = c.cfg.Weights[req.ID()]
+		}
+
 		c.requesters.req[req.ID()] = &requester{
 			id:        req.ID(),
 			requester: req,
 			sink:      false,
 			delay:     timer.Average(),
+			breaker:   newBreaker(c.cfg.Breaker),
+			weight:    weight,
+			running:   0,
 		}
 	}
 
+	c.orderer = newOrderer(c.cfg.Strategy, c.requesters.req)
+
+	// Start probing Clients when we know how to
+	if c.cfg.Probe != nil && c.cfg.ProbeInterval > 0 {
+		c.probeClosing = make(chan struct{})
+
+		c.probeWait.Add(1)
+
+		go c.probing(c.probeClosing)
+	}
+
 	c.booted = true
 
 	return c, nil
@@ -114,6 +149,15 @@ func (c *clients) Close() error {
 		return ErrAlreadyClosed
 	}
 
+	// Stop probing before closing the requesters
+	if c.probeClosing != nil {
+		close(c.probeClosing)
+
+		c.probeWait.Wait()
+
+		c.probeClosing = nil
+	}
+
 	// Close all requesters
 	var closeErr error
 
@@ -168,5 +212,5 @@ func (c *clients) Request(
 	cancel <-chan struct{},
 ) error {
 	return c.destinations.Request(
-		log, dest, req, cancel, &c.requesters, &c.requestLock)
+		log, dest, req, cancel, &c.requesters, c.orderer, &c.requestLock)
 }
@@ -0,0 +1,44 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"time"
+
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Config is the configuration of the Transceiver Client Balancer
+type Config struct {
+	MaxDestinations int
+	Strategy        Strategy
+	Weights         []uint32
+	Probe           transceiver.RequestBuilder
+	ProbeInterval   time.Duration
+	Breaker         BreakerConfig
+}
+
+// BreakerConfig is the configuration of the per Client Circuit Breaker
+type BreakerConfig struct {
+	FailureThreshold uint32
+	OpenTimeout      time.Duration
+	HalfOpenTrials   uint32
+}
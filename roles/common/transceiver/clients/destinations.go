@@ -24,6 +24,7 @@ import (
 	"errors"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
@@ -87,6 +88,7 @@ func (d *destinations) Request(
 	req transceiver.BalancedRequestBuilder,
 	cancel <-chan struct{},
 	requesters *requesters,
+	order orderer,
 	lock *sync.Mutex,
 ) error {
 	var retriable bool
@@ -98,6 +100,7 @@ func (d *destinations) Request(
 	destPriorities := make(priorities, dests.Priorities.Len())
 
 	copy(destPriorities, dests.Priorities)
+	order.Order(dest, destPriorities)
 	lock.Unlock()
 
 	continueLoop := true
@@ -123,7 +126,18 @@ func (d *destinations) Request(
 				continue
 			}
 
+			// Skip the Client immediately if it's known to be broken
+			allowed, release :=
+				destPriorities[dIdx].requester.breaker.Allow(time.Now())
+
+			if !allowed {
+				serverTried[dIdx] = true
+
+				continue
+			}
+
 			m := &meter{
+				log:         log,
 				current:     destPriorities[dIdx],
 				requesters:  requesters,
 				destination: dests,
@@ -132,8 +146,10 @@ func (d *destinations) Request(
 
 			serverTried[dIdx] = true
 
+			atomic.AddUint32(&destPriorities[dIdx].requester.running, 1)
+
 			retriable, reqErr = destPriorities[dIdx].requester.Request(
-				log, func(
+				log, dest, func(
 					connectionID transceiver.ConnectionID,
 					server rw.ReadWriteDepleteDoner,
 					connCtl transceiver.ConnectionControl,
@@ -147,6 +163,11 @@ func (d *destinations) Request(
 						log)
 				}, cancel, m)
 
+			release()
+
+			atomic.AddUint32(
+				&destPriorities[dIdx].requester.running, ^uint32(0))
+
 			if reqErr == nil {
 				return nil
 			}
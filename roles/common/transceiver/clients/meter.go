@@ -25,10 +25,12 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/reinit/coward/common/logger"
 	"github.com/reinit/coward/common/timer"
 )
 
 type meter struct {
+	log         logger.Logger
 	current     *priority
 	requesters  *requesters
 	destination *destination
@@ -71,6 +73,7 @@ func (m meterRequestStopper) Stop() time.Duration {
 	duration := m.stopper.Stop()
 
 	m.current.Sink(false)
+	m.current.requester.breaker.Success(time.Now())
 
 	// Don't resort if somebody is requesting
 	if atomic.LoadUint32(&m.dest.RunningRequests) > 1 {
@@ -97,6 +100,11 @@ func (m *meter) ConnectionFailure(e error) {
 
 	m.current.requester.Sink(true)
 
+	if m.current.requester.breaker.Failure(time.Now()) {
+		m.log.Warningf("Transceiver (%d) is unhealthy, requests will be "+
+			"skipped: %s", m.current.requester.ID(), e)
+	}
+
 	if atomic.LoadUint32(&m.destination.RunningRequests) > 1 {
 		return
 	}
@@ -0,0 +1,161 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/timer"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Consts
+const (
+	// probeDestination is the Destination of the probe requests
+	probeDestination transceiver.Destination = "Probe"
+)
+
+// probeMeter implements transceiver.Meter for health probes
+type probeMeter struct {
+	log        logger.Logger
+	current    *requester
+	requesters *requesters
+	lock       *sync.Mutex
+}
+
+// probeRequestStopper reports the result of a successful probe
+type probeRequestStopper struct {
+	log     logger.Logger
+	current *requester
+	stopper timer.Stopper
+}
+
+func (p probeRequestStopper) Stop() time.Duration {
+	duration := p.stopper.Stop()
+
+	if !p.current.breaker.Success(time.Now()) {
+		return duration
+	}
+
+	p.log.Infof("Transceiver (%d) is healthy again, re-admitting requests",
+		p.current.ID())
+
+	return duration
+}
+
+func (m probeMeter) Connection() timer.Stopper {
+	return meterConnectionStopper{
+		requesters: m.requesters,
+		current:    m.current,
+		stopper:    m.current.Delay().Start(),
+		lock:       m.lock,
+	}
+}
+
+func (m probeMeter) ConnectionFailure(e error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.current.Sink(true)
+	m.requesters.Renew()
+
+	m.RequestFailure(e)
+}
+
+func (m probeMeter) Request() timer.Stopper {
+	return probeRequestStopper{
+		log:     m.log,
+		current: m.current,
+		stopper: timer.New().Start(),
+	}
+}
+
+func (m probeMeter) RequestFailure(e error) {
+	if !m.current.breaker.Failure(time.Now()) {
+		return
+	}
+
+	m.log.Warningf("Transceiver (%d) is unhealthy, requests will be "+
+		"skipped: %s", m.current.ID(), e)
+}
+
+// probing periodically probes all Transceiver Clients until closing
+// is closed
+func (c *clients) probing(closing <-chan struct{}) {
+	defer c.probeWait.Done()
+
+	probeTicker := time.NewTicker(c.cfg.ProbeInterval)
+	defer probeTicker.Stop()
+
+	for {
+		select {
+		case <-probeTicker.C:
+			c.probeAll(closing)
+
+		case <-closing:
+			return
+		}
+	}
+}
+
+// probeAll probes all Transceiver Clients at the same time and wait
+// for them to complete
+func (c *clients) probeAll(closing <-chan struct{}) {
+	c.requestLock.Lock()
+	reqs := make([]*requester, c.requesters.Len())
+	copy(reqs, c.requesters.req)
+	c.requestLock.Unlock()
+
+	probeWait := sync.WaitGroup{}
+
+	for rIdx := range reqs {
+		probeWait.Add(1)
+
+		go func(req *requester) {
+			defer probeWait.Done()
+
+			c.probe(req, closing)
+		}(reqs[rIdx])
+	}
+
+	probeWait.Wait()
+}
+
+// probe sends a probe request to the specified Transceiver Client
+func (c *clients) probe(req *requester, closing <-chan struct{}) {
+	log := c.log.Context("Probe")
+
+	_, reqErr := req.Request(
+		log, probeDestination, c.cfg.Probe, closing, probeMeter{
+			log:        log,
+			current:    req,
+			requesters: &c.requesters,
+			lock:       &c.requestLock,
+		})
+
+	if reqErr == nil {
+		return
+	}
+
+	log.Debugf("Transceiver (%d) has failed the probe: %s", req.ID(), reqErr)
+}
@@ -33,6 +33,9 @@ type requester struct {
 	requester transceiver.Requester
 	sink      bool
 	delay     timer.Timer
+	breaker   *breaker
+	weight    uint32
+	running   uint32
 }
 
 type requesters struct {
@@ -57,11 +60,12 @@ func (r *requester) Delay() timer.Timer {
 
 func (r *requester) Request(
 	log logger.Logger,
+	destName transceiver.Destination,
 	req transceiver.RequestBuilder,
 	cancel <-chan struct{},
 	m transceiver.Meter,
 ) (bool, error) {
-	return r.requester.Request(log, req, cancel, m)
+	return r.requester.Request(log, destName, req, cancel, m)
 }
 
 func (r *requesters) Renew() {
@@ -0,0 +1,302 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"errors"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+// Strategy is the balancing strategy which decides the order of
+// Transceiver Clients that a request will be tried on
+type Strategy uint8
+
+// Errors
+var (
+	ErrStrategyUnknown = errors.New(
+		"Unknown balancing Strategy")
+)
+
+// Strategies
+const (
+	Latency            Strategy = 0x00
+	WeightedRoundRobin Strategy = 0x01
+	LeastRequests      Strategy = 0x02
+	ConsistentHash     Strategy = 0x03
+	Failover           Strategy = 0x04
+)
+
+// Consts
+const (
+	consistentHashReplicas = 64
+)
+
+// Strategies returns names of all available strategies
+func Strategies() []string {
+	return []string{
+		Latency.String(),
+		WeightedRoundRobin.String(),
+		LeastRequests.String(),
+		ConsistentHash.String(),
+		Failover.String(),
+	}
+}
+
+// FromString select Strategy from a string
+func (s *Strategy) FromString(n string) error {
+	switch n {
+	case "latency":
+		*s = Latency
+
+	case "round-robin":
+		*s = WeightedRoundRobin
+
+	case "least-requests":
+		*s = LeastRequests
+
+	case "hash":
+		*s = ConsistentHash
+
+	case "failover":
+		*s = Failover
+
+	default:
+		return ErrStrategyUnknown
+	}
+
+	return nil
+}
+
+// String return the String of current Strategy
+func (s Strategy) String() string {
+	switch s {
+	case Latency:
+		return "latency"
+
+	case WeightedRoundRobin:
+		return "round-robin"
+
+	case LeastRequests:
+		return "least-requests"
+
+	case ConsistentHash:
+		return "hash"
+
+	case Failover:
+		return "failover"
+
+	default:
+		return ""
+	}
+}
+
+// orderer re-orders a copy of destination priorities before a request.
+// The input priorities is already sorted by delay, and Order will be
+// called with the request lock held
+type orderer interface {
+	Order(dest transceiver.Destination, p priorities)
+}
+
+// newOrderer creates an orderer according to the Strategy
+func newOrderer(s Strategy, reqs []*requester) orderer {
+	switch s {
+	case WeightedRoundRobin:
+		return newWeightedRoundRobin(reqs)
+
+	case LeastRequests:
+		return leastRequests{}
+
+	case ConsistentHash:
+		return newConsistentHash(reqs)
+
+	case Failover:
+		return failover{}
+
+	default:
+		return latency{}
+	}
+}
+
+// latency tries Clients with lowest delay first
+type latency struct{}
+
+func (l latency) Order(dest transceiver.Destination, p priorities) {}
+
+// failover always tries Clients in their declaration order
+type failover struct{}
+
+func (f failover) Order(dest transceiver.Destination, p priorities) {
+	sort.SliceStable(p, func(i, j int) bool {
+		return p[i].requester.ID() < p[j].requester.ID()
+	})
+}
+
+// leastRequests tries Clients with less outstanding requests first
+type leastRequests struct{}
+
+func (l leastRequests) Order(dest transceiver.Destination, p priorities) {
+	sort.SliceStable(p, func(i, j int) bool {
+		return atomic.LoadUint32(&p[i].requester.running) <
+			atomic.LoadUint32(&p[j].requester.running)
+	})
+}
+
+// weightedRoundRobin selects Clients by the Smooth Weighted Round-Robin
+// algorithm, so Clients with a greater weight will be selected more often
+// but never consecutively unless necessary
+type weightedRoundRobin struct {
+	current     []int64
+	totalWeight int64
+}
+
+func newWeightedRoundRobin(reqs []*requester) *weightedRoundRobin {
+	w := &weightedRoundRobin{
+		current:     make([]int64, len(reqs)),
+		totalWeight: 0,
+	}
+
+	for rIdx := range reqs {
+		w.totalWeight += int64(reqs[rIdx].weight)
+	}
+
+	return w
+}
+
+func (w *weightedRoundRobin) Order(
+	dest transceiver.Destination, p priorities) {
+	selected := -1
+
+	for pIdx := range p {
+		id := p[pIdx].requester.ID()
+
+		w.current[id] += int64(p[pIdx].requester.weight)
+
+		if selected >= 0 &&
+			w.current[id] <= w.current[p[selected].requester.ID()] {
+			continue
+		}
+
+		selected = pIdx
+	}
+
+	if selected < 0 {
+		return
+	}
+
+	w.current[p[selected].requester.ID()] -= w.totalWeight
+
+	// Move selected one to the front, leave the rest in delay order
+	// so they can be used as fallback
+	selectedPriority := p[selected]
+
+	copy(p[1:selected+1], p[:selected])
+
+	p[0] = selectedPriority
+}
+
+// consistentHashNode is a point on the hash ring
+type consistentHashNode struct {
+	hash uint32
+	id   transceiver.ClientID
+}
+
+// consistentHash selects Clients by hash of the destination, so requests
+// to a same destination will always exit from the same Client as long as
+// it's available
+type consistentHash struct {
+	ring    []consistentHashNode
+	clients int
+}
+
+func newConsistentHash(reqs []*requester) consistentHash {
+	c := consistentHash{
+		ring: make(
+			[]consistentHashNode, 0, len(reqs)*consistentHashReplicas),
+		clients: len(reqs),
+	}
+
+	for rIdx := range reqs {
+		replicas := int(reqs[rIdx].weight) * consistentHashReplicas
+
+		for r := 0; r < replicas; r++ {
+			c.ring = append(c.ring, consistentHashNode{
+				hash: consistentHashSum(strconv.FormatUint(
+					uint64(reqs[rIdx].ID()), 10) + "-" + strconv.Itoa(r)),
+				id: reqs[rIdx].ID(),
+			})
+		}
+	}
+
+	sort.Slice(c.ring, func(i, j int) bool {
+		return c.ring[i].hash < c.ring[j].hash
+	})
+
+	return c
+}
+
+func consistentHashSum(s string) uint32 {
+	h := fnv.New32a()
+
+	h.Write([]byte(s))
+
+	return h.Sum32()
+}
+
+func (c consistentHash) Order(dest transceiver.Destination, p priorities) {
+	ringLen := len(c.ring)
+
+	if ringLen <= 0 {
+		return
+	}
+
+	destHash := consistentHashSum(string(dest))
+	start := sort.Search(ringLen, func(i int) bool {
+		return c.ring[i].hash >= destHash
+	})
+
+	// Walk the ring clockwise to decide the rank of each Client
+	rank := make([]int, c.clients)
+	ranked := 0
+
+	for rIdx := range rank {
+		rank[rIdx] = -1
+	}
+
+	for step := 0; step < ringLen && ranked < c.clients; step++ {
+		node := c.ring[(start+step)%ringLen]
+
+		if rank[node.id] >= 0 {
+			continue
+		}
+
+		rank[node.id] = ranked
+		ranked++
+	}
+
+	sort.SliceStable(p, func(i, j int) bool {
+		return rank[p[i].requester.ID()] < rank[p[j].requester.ID()]
+	})
+}
@@ -0,0 +1,151 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"testing"
+
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+func testStrategyPriorities(weights []uint32) ([]*requester, priorities) {
+	reqs := make([]*requester, len(weights))
+	prios := make(priorities, len(weights))
+
+	for wIdx := range weights {
+		reqs[wIdx] = &requester{
+			id:     transceiver.ClientID(wIdx),
+			weight: weights[wIdx],
+		}
+	}
+
+	// Reverse the order to simulate a sorted-by-delay priorities
+	for rIdx := range reqs {
+		prios[len(reqs)-rIdx-1] = &priority{
+			requester: reqs[rIdx],
+		}
+	}
+
+	return reqs, prios
+}
+
+func TestStrategyFailover(t *testing.T) {
+	reqs, prios := testStrategyPriorities([]uint32{1, 1, 1})
+
+	newOrderer(Failover, reqs).Order("example.com:80", prios)
+
+	for pIdx := range prios {
+		if prios[pIdx].requester.ID() == transceiver.ClientID(pIdx) {
+			continue
+		}
+
+		t.Errorf("Expecting Client %d at position %d, got %d",
+			pIdx, pIdx, prios[pIdx].requester.ID())
+
+		return
+	}
+}
+
+func TestStrategyLeastRequests(t *testing.T) {
+	reqs, prios := testStrategyPriorities([]uint32{1, 1, 1})
+
+	reqs[0].running = 2
+	reqs[1].running = 0
+	reqs[2].running = 1
+
+	newOrderer(LeastRequests, reqs).Order("example.com:80", prios)
+
+	expected := []transceiver.ClientID{1, 2, 0}
+
+	for pIdx := range prios {
+		if prios[pIdx].requester.ID() == expected[pIdx] {
+			continue
+		}
+
+		t.Errorf("Expecting Client %d at position %d, got %d",
+			expected[pIdx], pIdx, prios[pIdx].requester.ID())
+
+		return
+	}
+}
+
+func TestStrategyWeightedRoundRobin(t *testing.T) {
+	reqs, prios := testStrategyPriorities([]uint32{5, 1, 1})
+	selected := make([]int, len(reqs))
+	wrr := newOrderer(WeightedRoundRobin, reqs)
+
+	for i := 0; i < 70; i++ {
+		p := make(priorities, len(prios))
+
+		copy(p, prios)
+
+		wrr.Order("example.com:80", p)
+
+		selected[p[0].requester.ID()]++
+	}
+
+	expected := []int{50, 10, 10}
+
+	for sIdx := range selected {
+		if selected[sIdx] == expected[sIdx] {
+			continue
+		}
+
+		t.Errorf("Expecting Client %d to be selected %d times, got %d",
+			sIdx, expected[sIdx], selected[sIdx])
+
+		return
+	}
+}
+
+func TestStrategyConsistentHash(t *testing.T) {
+	reqs, prios := testStrategyPriorities([]uint32{1, 1, 1, 1})
+	ch := newOrderer(ConsistentHash, reqs)
+	dests := []transceiver.Destination{
+		"example.com:80", "example.org:443", "10.0.0.1:22", "[::1]:8080",
+	}
+
+	for dIdx := range dests {
+		p1 := make(priorities, len(prios))
+		p2 := make(priorities, len(prios))
+
+		copy(p1, prios)
+		copy(p2, prios)
+
+		ch.Order(dests[dIdx], p1)
+
+		// Order of the input should not change the result
+		p2[0], p2[3] = p2[3], p2[0]
+
+		ch.Order(dests[dIdx], p2)
+
+		for pIdx := range p1 {
+			if p1[pIdx].requester.ID() == p2[pIdx].requester.ID() {
+				continue
+			}
+
+			t.Errorf("Expecting destination %s to be always ordered "+
+				"the same way", dests[dIdx])
+
+			return
+		}
+	}
+}
@@ -21,17 +21,31 @@
 package transceiver
 
 import (
+	"io"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 )
 
 // CodecBuilder creates a new Codec
 type CodecBuilder func() (rw.Codec, error)
 
+// CodecHandshaker is a Codec which must exchange data with the remote
+// before it can encode or decode any data
+type CodecHandshaker interface {
+	Handshake(conn io.ReadWriter, initiator bool) error
+}
+
 // Codec is the registeration information of a CodecBuilder
 type Codec struct {
-	Name   string
-	Usage  string
-	Build  func(configuration []string) CodecBuilder
+	Name  string
+	Usage string
+	Build func(
+		configuration []string,
+		log logger.Logger,
+		m metrics.Registry,
+	) CodecBuilder
 	Verify func(configuration []string) error
 }
 
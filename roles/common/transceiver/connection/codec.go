@@ -21,14 +21,21 @@
 package connection
 
 import (
+	"time"
+
 	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/network"
 	"github.com/reinit/coward/roles/common/transceiver"
 )
 
 // Codec creates a io.ReadWriter for encode and decode data from
-// given network.Connection
+// given network.Connection. If the Codec is a CodecHandshaker, the
+// handshake will be completed on the connection before return
 func Codec(
 	cc transceiver.CodecBuilder,
+	conn network.Connection,
+	initiator bool,
+	timeout time.Duration,
 ) (rw.Codec, error) {
 	ccc, ccErr := cc()
 
@@ -36,5 +43,19 @@ func Codec(
 		return nil, ccErr
 	}
 
+	handshaker, isHandshaker := ccc.(transceiver.CodecHandshaker)
+
+	if !isHandshaker {
+		return ccc, nil
+	}
+
+	conn.SetTimeout(timeout)
+
+	hsErr := handshaker.Handshake(conn, initiator)
+
+	if hsErr != nil {
+		return nil, hsErr
+	}
+
 	return ccc, nil
 }
@@ -24,6 +24,7 @@ import (
 	"bytes"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/reinit/coward/common/rw"
 )
@@ -88,13 +89,28 @@ func (d dummyCodecEncoder) WriteAll(b ...[]byte) (int, error) {
 	return totalWrite, nil
 }
 
+type dummyHandshakeCoder struct {
+	dummyCoder
+
+	initiator *bool
+}
+
+func (d dummyHandshakeCoder) Handshake(
+	conn io.ReadWriter, initiator bool) error {
+	*d.initiator = initiator
+
+	_, wErr := conn.Write([]byte("Hello"))
+
+	return wErr
+}
+
 func TestCodec(t *testing.T) {
 	d := &dummyConnection{
 		buf: bytes.NewBuffer(make([]byte, 0, 4096)),
 	}
 	c, _ := Codec(func() (rw.Codec, error) {
 		return dummyCoder{}, nil
-	})
+	}, d, true, 1*time.Second)
 
 	wLen, wErr := c.Encode(d).Write([]byte("Hello World"))
 
@@ -133,3 +149,32 @@ func TestCodec(t *testing.T) {
 		return
 	}
 }
+
+func TestCodecHandshake(t *testing.T) {
+	d := &dummyConnection{
+		buf: bytes.NewBuffer(make([]byte, 0, 4096)),
+	}
+	initiator := false
+
+	_, cErr := Codec(func() (rw.Codec, error) {
+		return dummyHandshakeCoder{initiator: &initiator}, nil
+	}, d, true, 1*time.Second)
+
+	if cErr != nil {
+		t.Error("Failed to build codec due to error:", cErr)
+
+		return
+	}
+
+	if !initiator {
+		t.Error("Expecting the handshake to be completed as initiator")
+
+		return
+	}
+
+	if !bytes.Equal(d.buf.Bytes(), []byte("Hello")) {
+		t.Errorf("Expecting handshake data \"Hello\", got %q", d.buf.Bytes())
+
+		return
+	}
+}
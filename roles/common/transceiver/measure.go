@@ -0,0 +1,136 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package transceiver
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/timer"
+)
+
+// measuredMeter is a Meter that also collects metrics
+type measuredMeter struct {
+	meter    Meter
+	registry metrics.Registry
+}
+
+// measuredStopper is a timer.Stopper that also records the duration
+// into a Histogram
+type measuredStopper struct {
+	stopper   timer.Stopper
+	histogram metrics.Histogram
+}
+
+// ErrorClass returns a fixed class name of the given error, so it can be
+// used as a metric label without the addresses and ports carried in the
+// error message
+func ErrorClass(e error) string {
+	// Unwrap Transceiver Connection Errors
+	if wrapped, isWrapped := e.(interface{ Get() error }); isWrapped &&
+		wrapped.Get() != nil {
+		e = wrapped.Get()
+	}
+
+	if _, isCodecErr := e.(CodecError); isCodecErr {
+		return "codec"
+	}
+
+	if netErr, isNetErr := e.(net.Error); isNetErr && netErr.Timeout() {
+		return "timeout"
+	}
+
+	switch {
+	case errors.Is(e, syscall.ECONNREFUSED):
+		return "refused"
+
+	case errors.Is(e, syscall.ECONNRESET):
+		return "reset"
+
+	case errors.Is(e, io.EOF) || errors.Is(e, io.ErrUnexpectedEOF) ||
+		errors.Is(e, net.ErrClosed) || errors.Is(e, syscall.EPIPE):
+		return "closed"
+
+	default:
+		return "other"
+	}
+}
+
+// MeasureMeter wraps a Meter so the measurements will also be recorded
+// into the metrics Registry
+func MeasureMeter(m Meter, reg metrics.Registry) Meter {
+	return measuredMeter{
+		meter:    m,
+		registry: reg,
+	}
+}
+
+func (m measuredStopper) Stop() time.Duration {
+	d := m.stopper.Stop()
+
+	m.histogram.Observe(d)
+
+	return d
+}
+
+func (m measuredMeter) Connection() timer.Stopper {
+	return measuredStopper{
+		stopper: m.meter.Connection(),
+		histogram: m.registry.Histogram(
+			"coward_transceiver_connect_seconds",
+			"Delay of Transceiver connection establishment"),
+	}
+}
+
+func (m measuredMeter) ConnectionFailure(e error) {
+	m.registry.Counter(
+		"coward_transceiver_connection_failures_total",
+		"Failed Transceiver connection establishments",
+		metrics.L("error", ErrorClass(e))).Add(1)
+
+	m.meter.ConnectionFailure(e)
+}
+
+func (m measuredMeter) Request() timer.Stopper {
+	m.registry.Counter(
+		"coward_transceiver_requests_total",
+		"Transceiver requests").Add(1)
+
+	return measuredStopper{
+		stopper: m.meter.Request(),
+		histogram: m.registry.Histogram(
+			"coward_transceiver_request_seconds",
+			"Delay of Transceiver request initialization"),
+	}
+}
+
+func (m measuredMeter) RequestFailure(e error) {
+	m.registry.Counter(
+		"coward_transceiver_request_failures_total",
+		"Failed Transceiver requests",
+		metrics.L("error", ErrorClass(e))).Add(1)
+
+	m.meter.RequestFailure(e)
+}
@@ -0,0 +1,62 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package transceiver
+
+import (
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestErrorClass(t *testing.T) {
+	refused := &net.OpError{
+		Op:     "dial",
+		Net:    "tcp",
+		Source: nil,
+		Addr:   nil,
+		Err:    syscall.ECONNREFUSED,
+	}
+
+	tests := []struct {
+		err   error
+		class string
+	}{
+		{WrapCodecError(errors.New("10.0.0.1:443")), "codec"},
+		{refused, "refused"},
+		{io.EOF, "closed"},
+		{errors.New("dial tcp 10.0.0.1:443: something"), "other"},
+	}
+
+	for tIdx := range tests {
+		class := ErrorClass(tests[tIdx].err)
+
+		if class == tests[tIdx].class {
+			continue
+		}
+
+		t.Errorf("Expecting error %d to be classified as \"%s\", got \"%s\"",
+			tIdx, tests[tIdx].class, class)
+
+		return
+	}
+}
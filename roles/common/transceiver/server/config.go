@@ -28,4 +28,5 @@ type Config struct {
 	IdleTimeout          time.Duration
 	ConnectionChannels   uint8
 	ChannelDispatchDelay time.Duration
+	Fallback             Fallback
 }
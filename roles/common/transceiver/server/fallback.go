@@ -0,0 +1,192 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/roles/common/network"
+)
+
+// Consts
+const (
+	fallbackMaxRecord = 64 * 1024
+)
+
+// Fallback takes over connections which have failed the Codec before
+// their first request is dispatched. The data that has already been read
+// from the connection is given as read
+type Fallback func(l logger.Logger, conn network.Connection, read []byte) error
+
+// recorder records the data read from a Connection until it's stopped.
+// Closing of the Connection is held back during the recording, so the
+// Connection can still be handed over to the Fallback after the Transceiver
+// has given up on it
+type recorder struct {
+	network.Connection
+
+	lock       sync.Mutex
+	recorded   []byte
+	recording  bool
+	overflowed bool
+	closing    bool
+}
+
+// record starts recording the data read from the connection
+func record(conn network.Connection) *recorder {
+	return &recorder{
+		Connection: conn,
+		lock:       sync.Mutex{},
+		recorded:   make([]byte, 0, 1024),
+		recording:  true,
+		overflowed: false,
+		closing:    false,
+	}
+}
+
+// Read reads data from the Connection, and record it when needed
+func (r *recorder) Read(b []byte) (int, error) {
+	rLen, rErr := r.Connection.Read(b)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.recording || rLen <= 0 {
+		return rLen, rErr
+	}
+
+	if len(r.recorded)+rLen > fallbackMaxRecord {
+		r.recording = false
+		r.overflowed = true
+		r.recorded = nil
+
+		return rLen, rErr
+	}
+
+	r.recorded = append(r.recorded, b[:rLen]...)
+
+	return rLen, rErr
+}
+
+// Close closes the Connection, or holds the closing back until the
+// recording is released
+func (r *recorder) Close() error {
+	r.lock.Lock()
+
+	if r.recording {
+		r.closing = true
+
+		r.lock.Unlock()
+
+		return nil
+	}
+
+	r.lock.Unlock()
+
+	return r.Connection.Close()
+}
+
+// release stops the recording and carries out the closing that has been
+// held back
+func (r *recorder) release() error {
+	r.lock.Lock()
+
+	r.recording = false
+	r.recorded = nil
+
+	closing := r.closing
+
+	r.closing = false
+
+	r.lock.Unlock()
+
+	if !closing {
+		return nil
+	}
+
+	return r.Connection.Close()
+}
+
+// stop stops the recording and returns the recorded data. The result
+// will be false if the recorded data is incomplete
+func (r *recorder) stop() ([]byte, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	recorded := r.recorded
+
+	r.recording = false
+	r.recorded = nil
+
+	return recorded, !r.overflowed
+}
+
+// Splice creates a Fallback that relays the connection to the destination
+// of the dialer, with the already read data replayed
+func Splice(dialer network.Dialer, idleTimeout time.Duration) Fallback {
+	return func(
+		l logger.Logger,
+		conn network.Connection,
+		read []byte,
+	) error {
+		fallbackConn, dialErr := dialer.Dialer().Dial()
+
+		if dialErr != nil {
+			return dialErr
+		}
+
+		defer fallbackConn.Close()
+
+		conn.SetTimeout(idleTimeout)
+		fallbackConn.SetTimeout(idleTimeout)
+
+		_, wErr := fallbackConn.Write(read)
+
+		if wErr != nil {
+			return wErr
+		}
+
+		responded := make(chan struct{})
+
+		go func() {
+			defer close(responded)
+
+			io.Copy(conn, fallbackConn)
+
+			// Fallback destination has done responding, unblock the
+			// reading of the connection
+			conn.Close()
+		}()
+
+		io.Copy(fallbackConn, conn)
+
+		fallbackConn.Close()
+
+		<-responded
+
+		l.Debugf("Fallback completed")
+
+		return nil
+	}
+}
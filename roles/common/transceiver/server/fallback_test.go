@@ -0,0 +1,358 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/command"
+	tcpconn "github.com/reinit/coward/roles/common/network/connection/tcp"
+	"github.com/reinit/coward/roles/common/network/dialer/tcp"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/transceiver"
+)
+
+var (
+	errTestCodecRejected = transceiver.NewCodecError("Rejected")
+)
+
+type rejectCodec struct{}
+
+type rejectCodecDecoder struct {
+	r io.Reader
+}
+
+type rejectCodecEncoder struct {
+	w io.Writer
+}
+
+type waitCodec struct {
+	rejectCodec
+}
+
+type waitCodecDecoder struct {
+	r io.Reader
+}
+
+func (r rejectCodec) Decode(reader io.Reader) io.Reader {
+	return rejectCodecDecoder{r: reader}
+}
+
+func (r rejectCodec) Encode(w io.Writer) rw.WriteWriteAll {
+	return rejectCodecEncoder{w: w}
+}
+
+func (r rejectCodecDecoder) Read(b []byte) (int, error) {
+	_, rErr := r.r.Read(b)
+
+	if rErr != nil {
+		return 0, rErr
+	}
+
+	return 0, errTestCodecRejected
+}
+
+func (w waitCodec) Decode(reader io.Reader) io.Reader {
+	return waitCodecDecoder{r: reader}
+}
+
+func (w waitCodecDecoder) Read(b []byte) (int, error) {
+	_, rErr := io.ReadFull(w.r, make([]byte, 64))
+
+	if rErr != nil {
+		return 0, rErr
+	}
+
+	return 0, errTestCodecRejected
+}
+
+func (r rejectCodecEncoder) Write(b []byte) (int, error) {
+	return r.w.Write(b)
+}
+
+func (r rejectCodecEncoder) WriteAll(b ...[]byte) (int, error) {
+	return r.w.Write(bytes.Join(b, nil))
+}
+
+func TestServerFallback(t *testing.T) {
+	const request = "GET / HTTP/1.0\r\nHost: example.com\r\n\r\n"
+	const response = "HTTP/1.0 200 OK\r\nContent-Length: 5\r\n\r\nDecoy"
+
+	decoy, listenErr := net.Listen("tcp", "127.0.0.1:0")
+
+	if listenErr != nil {
+		t.Error("Failed to listen due to error:", listenErr)
+
+		return
+	}
+
+	defer decoy.Close()
+
+	decoyReceived := make(chan []byte, 1)
+
+	go func() {
+		conn, accErr := decoy.Accept()
+
+		if accErr != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		buf := make([]byte, len(request))
+
+		io.ReadFull(conn, buf)
+
+		decoyReceived <- buf
+
+		conn.Write([]byte(response))
+	}()
+
+	_, decoyPort, _ := net.SplitHostPort(decoy.Addr().String())
+	decoyPortN, _ := strconv.ParseUint(decoyPort, 10, 16)
+
+	registry := metrics.New()
+
+	s := New(func() (rw.Codec, error) {
+		return rejectCodec{}, nil
+	}, nil, registry, Config{
+		InitialTimeout:       3 * time.Second,
+		IdleTimeout:          3 * time.Second,
+		ConnectionChannels:   1,
+		ChannelDispatchDelay: 0,
+		Fallback: Splice(tcp.New(
+			"127.0.0.1", uint16(decoyPortN), 3*time.Second, sockopt.Options{},
+			tcpconn.Wrap,
+		), 3*time.Second),
+	})
+
+	serverConn, clientConn := net.Pipe()
+
+	handleResult := make(chan error, 1)
+
+	go func() {
+		handleResult <- s.Handle(
+			logger.NewDitch(), tcpconn.Wrap(serverConn), command.New())
+	}()
+
+	go clientConn.Write([]byte(request))
+
+	clientConn.SetDeadline(time.Now().Add(3 * time.Second))
+
+	received, rErr := ioutil.ReadAll(clientConn)
+
+	if rErr != nil {
+		t.Error("Failed to read response due to error:", rErr)
+
+		return
+	}
+
+	if string(received) != response {
+		t.Errorf("Expecting to receive %q, got %q", response, received)
+
+		return
+	}
+
+	replayed := <-decoyReceived
+
+	if string(replayed) != request {
+		t.Errorf("Expecting decoy to receive %q, got %q", request, replayed)
+
+		return
+	}
+
+	handleErr := <-handleResult
+
+	if handleErr != nil {
+		t.Error("Expecting Handle to succeed, got error:", handleErr)
+
+		return
+	}
+
+	fallbacks := uint64(0)
+
+	registry.Gather(func(f metrics.Family) {
+		if f.Name != "coward_transceiver_fallbacks_total" {
+			return
+		}
+
+		for mIdx := range f.Metrics {
+			fallbacks += f.Metrics[mIdx].Value
+		}
+	})
+
+	if fallbacks != 1 {
+		t.Errorf("Expecting %d fallback, got %d", 1, fallbacks)
+	}
+}
+
+func TestServerFallbackOnTimeout(t *testing.T) {
+	const probe = "PROBE"
+	const response = "Decoy"
+
+	decoy, listenErr := net.Listen("tcp", "127.0.0.1:0")
+
+	if listenErr != nil {
+		t.Error("Failed to listen due to error:", listenErr)
+
+		return
+	}
+
+	defer decoy.Close()
+
+	decoyReceived := make(chan []byte, 1)
+
+	go func() {
+		conn, accErr := decoy.Accept()
+
+		if accErr != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		buf := make([]byte, len(probe))
+
+		io.ReadFull(conn, buf)
+
+		decoyReceived <- buf
+
+		conn.Write([]byte(response))
+	}()
+
+	_, decoyPort, _ := net.SplitHostPort(decoy.Addr().String())
+	decoyPortN, _ := strconv.ParseUint(decoyPort, 10, 16)
+
+	// The Codec wants more data than the probe carries, so the initial read
+	// will time out rather than fail the Codec
+	s := New(func() (rw.Codec, error) {
+		return waitCodec{}, nil
+	}, nil, metrics.NewDitch(), Config{
+		InitialTimeout:       200 * time.Millisecond,
+		IdleTimeout:          3 * time.Second,
+		ConnectionChannels:   1,
+		ChannelDispatchDelay: 0,
+		Fallback: Splice(tcp.New(
+			"127.0.0.1", uint16(decoyPortN), 3*time.Second, sockopt.Options{},
+			tcpconn.Wrap,
+		), 3*time.Second),
+	})
+
+	serverConn, clientConn := net.Pipe()
+
+	handleResult := make(chan error, 1)
+
+	go func() {
+		handleResult <- s.Handle(
+			logger.NewDitch(), tcpconn.Wrap(serverConn), command.New())
+	}()
+
+	go clientConn.Write([]byte(probe))
+
+	clientConn.SetDeadline(time.Now().Add(3 * time.Second))
+
+	received, rErr := ioutil.ReadAll(clientConn)
+
+	if rErr != nil {
+		t.Error("Failed to read response due to error:", rErr)
+
+		return
+	}
+
+	if string(received) != response {
+		t.Errorf("Expecting to receive %q, got %q", response, received)
+
+		return
+	}
+
+	replayed := <-decoyReceived
+
+	if string(replayed) != probe {
+		t.Errorf("Expecting decoy to receive %q, got %q", probe, replayed)
+
+		return
+	}
+
+	handleErr := <-handleResult
+
+	if handleErr != nil {
+		t.Error("Expecting Handle to succeed, got error:", handleErr)
+	}
+}
+
+func TestServerWithoutFallback(t *testing.T) {
+	s := New(func() (rw.Codec, error) {
+		return rejectCodec{}, nil
+	}, nil, metrics.NewDitch(), Config{
+		InitialTimeout:       3 * time.Second,
+		IdleTimeout:          3 * time.Second,
+		ConnectionChannels:   1,
+		ChannelDispatchDelay: 0,
+		Fallback:             nil,
+	})
+
+	serverConn, clientConn := net.Pipe()
+
+	defer clientConn.Close()
+
+	go clientConn.Write([]byte("GET / HTTP/1.0\r\n\r\n"))
+
+	handleErr := s.Handle(
+		logger.NewDitch(), tcpconn.Wrap(serverConn), command.New())
+
+	if handleErr != errTestCodecRejected {
+		t.Errorf("Expecting Handle to fail with error %s, got %s",
+			errTestCodecRejected, handleErr)
+	}
+}
+
+func TestRecorderOverflow(t *testing.T) {
+	serverConn, clientConn := net.Pipe()
+
+	defer clientConn.Close()
+
+	go clientConn.Write(make([]byte, fallbackMaxRecord+1))
+
+	r := record(tcpconn.Wrap(serverConn))
+
+	_, rErr := io.ReadFull(r, make([]byte, fallbackMaxRecord+1))
+
+	if rErr != nil {
+		t.Error("Failed to read due to error:", rErr)
+
+		return
+	}
+
+	_, completed := r.stop()
+
+	if completed {
+		t.Error("Expecting the record to be incomplete")
+	}
+}
@@ -23,9 +23,11 @@ package server
 import (
 	"errors"
 	"io"
+	"strconv"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/roles/common/command"
 	"github.com/reinit/coward/roles/common/transceiver/connection"
 )
@@ -38,6 +40,7 @@ var (
 
 type handler struct {
 	logger     logger.Logger
+	metrics    metrics.Registry
 	conn       connection.Virtual
 	commands   command.Commands
 	runningCmd fsm.FSM
@@ -71,6 +74,12 @@ func (h *handler) Bootup() (fsm.State, error) {
 		return nil, cmdSelectErr
 	}
 
+	h.metrics.Counter(
+		"coward_transceiver_commands_total",
+		"Handled Transceiver commands",
+		metrics.L("command", strconv.FormatUint(uint64(cmd.ID()), 10)),
+	).Add(1)
+
 	cmdRunner := fsm.New(cmd.New(h.conn, h.logger))
 
 	// Notice the Bootup will continue reading the segment rather than
@@ -28,6 +28,7 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/roles/common/channel"
 	"github.com/reinit/coward/roles/common/command"
@@ -47,18 +48,21 @@ type server struct {
 	cfg        Config
 	codec      transceiver.CodecBuilder
 	timeTicker ticker.Requester
+	metrics    metrics.Registry
 }
 
 // New creates a new transceiver server
 func New(
 	codec transceiver.CodecBuilder,
 	timeTicker ticker.Requester,
+	m metrics.Registry,
 	cfg Config,
 ) transceiver.Server {
 	return &server{
 		cfg:        cfg,
 		codec:      codec,
 		timeTicker: timeTicker,
+		metrics:    m,
 	}
 }
 
@@ -70,9 +74,22 @@ func (s *server) Handle(
 ) error {
 	log := l.Context("Transceiver")
 
-	cc, ccErr := connection.Codec(s.codec)
+	var recorded *recorder
+
+	if s.cfg.Fallback != nil {
+		recorded = record(conn)
+		conn = recorded
+	}
+
+	cc, ccErr := connection.Codec(s.codec, conn, false, s.cfg.InitialTimeout)
 
 	if ccErr != nil {
+		fellback, fbErr := s.fallback(log, recorded, ccErr)
+
+		if fellback {
+			return fbErr
+		}
+
 		return ccErr
 	}
 
@@ -89,6 +106,7 @@ func (s *server) Handle(
 
 		return &handler{
 			logger:     sLog,
+			metrics:    s.metrics,
 			conn:       channelConn,
 			commands:   commands,
 			runningCmd: nil,
@@ -120,6 +138,14 @@ func (s *server) Handle(
 
 		channelID, machine, chGetErr := channelized.Dispatch(channels)
 
+		if chGetErr != nil && !connectionTimeoutUpdated {
+			fellback, fbErr := s.fallback(log, recorded, chGetErr)
+
+			if fellback {
+				return fbErr
+			}
+		}
+
 		if chGetErr != nil {
 			connErr, isConnErr := chGetErr.(connection.Error)
 
@@ -136,6 +162,10 @@ func (s *server) Handle(
 		}
 
 		if !connectionTimeoutUpdated {
+			if recorded != nil {
+				recorded.release()
+			}
+
 			channelized.Timeout(s.cfg.IdleTimeout)
 
 			connectionTimeoutUpdated = true
@@ -151,6 +181,11 @@ func (s *server) Handle(
 			continue
 		}
 
+		s.metrics.Counter(
+			"coward_transceiver_command_failures_total",
+			"Failed Transceiver commands",
+			metrics.L("error", transceiver.ErrorClass(tickErr))).Add(1)
+
 		continueHandling := true
 
 		switch tickErr.(type) {
@@ -184,3 +219,43 @@ func (s *server) Handle(
 		return tickErr
 	}
 }
+
+// fallback hands the connection over to the Fallback if the error is a
+// CodecError or a timeout of the initial read. The result will be false
+// if the connection is not taken
+func (s *server) fallback(
+	log logger.Logger,
+	recorded *recorder,
+	err error,
+) (bool, error) {
+	if recorded == nil {
+		return false, nil
+	}
+
+	// A probe which is shorter than what the Codec wants to read will time
+	// out instead of failing the Codec, and it must be handed over as well,
+	// otherwise the server can still be told apart by it
+	errClass := transceiver.ErrorClass(err)
+
+	if errClass != "codec" && errClass != "timeout" {
+		recorded.release()
+
+		return false, nil
+	}
+
+	read, completed := recorded.stop()
+
+	if !completed {
+		recorded.release()
+
+		return false, nil
+	}
+
+	log.Debugf("Falling back due to error: %s", err)
+
+	s.metrics.Counter(
+		"coward_transceiver_fallbacks_total",
+		"Connections that have been handed over to the Fallback").Add(1)
+
+	return true, s.cfg.Fallback(log, recorded.Connection, read)
+}
@@ -25,16 +25,23 @@ import (
 	"time"
 
 	"github.com/reinit/coward/roles/common/network"
+	unixlistener "github.com/reinit/coward/roles/common/network/listener/unix"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 	proxycomm "github.com/reinit/coward/roles/proxy/common"
 )
 
 // Mapped Item
 type Mapped struct {
-	ID        proxycomm.MapID
-	Protocol  network.Protocol
-	Interface net.IP
-	Port      uint16
-	Capacity  uint32
+	ID         proxycomm.MapID
+	Protocol   network.Protocol
+	Interfaces []net.IP
+	Port       uint16
+	Capacity   uint32
+
+	// Socket is the path of the unix domain socket to serve the mapping
+	// on instead of the Interfaces and Port when it's not empty
+	Socket       string
+	SocketConfig unixlistener.Config
 }
 
 // Mappeds a group of Mapped
@@ -60,4 +67,5 @@ type Config struct {
 	TransceiverInitialTimeout       time.Duration
 	TransceiverChannels             uint8
 	Mapping                         Mappeds
+	SocketOptions                   sockopt.Options
 }
@@ -21,9 +21,11 @@
 package mapper
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -35,6 +37,7 @@ import (
 
 type udpHandler struct {
 	mapper      proxycommon.MapID
+	metrics     metrics.Registry
 	cfg         Config
 	runner      worker.Runner
 	shb         *common.SharedBuffer
@@ -45,6 +48,7 @@ type udpHandler struct {
 
 type udpClient struct {
 	mapper      proxycommon.MapID
+	metrics     metrics.Registry
 	conn        network.Connection
 	logger      logger.Logger
 	cfg         Config
@@ -61,6 +65,7 @@ func (d udpHandler) New(
 ) (network.Client, error) {
 	return udpClient{
 		mapper:      d.mapper,
+		metrics:     d.metrics,
 		conn:        c,
 		logger:      l,
 		cfg:         d.cfg,
@@ -85,7 +90,10 @@ func (d udpClient) Serve() error {
 
 	_, reqErr := d.transceiver.Request(
 		d.logger,
-		request.UDP(d.mapper, d.conn, d.runner, d.timeout, d.shb),
+		transceiver.Destination("Mapping:"+strconv.FormatUint(
+			uint64(d.mapper), 10)),
+		request.UDP(
+			d.mapper, d.conn, d.metrics, d.runner, d.timeout, d.shb),
 		d.conn.Closed(), metering)
 
 	if reqErr != nil {
@@ -24,10 +24,14 @@ import (
 	"errors"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -35,6 +39,7 @@ import (
 	udpconn "github.com/reinit/coward/roles/common/network/connection/udp"
 	tcplistener "github.com/reinit/coward/roles/common/network/listener/tcp"
 	udplistener "github.com/reinit/coward/roles/common/network/listener/udp"
+	unixlistener "github.com/reinit/coward/roles/common/network/listener/unix"
 	"github.com/reinit/coward/roles/common/network/server"
 	"github.com/reinit/coward/roles/common/transceiver"
 	tclient "github.com/reinit/coward/roles/common/transceiver/client"
@@ -60,6 +65,8 @@ type mapper struct {
 	codec           transceiver.CodecBuilder
 	dialer          network.Dialer
 	log             logger.Logger
+	metrics         metrics.Registry
+	sessions        session.Sessions
 	cfg             Config
 	transceiver     transceiver.Requester
 	ticker          ticker.RequestCloser
@@ -73,12 +80,16 @@ func New(
 	codec transceiver.CodecBuilder,
 	dialer network.Dialer,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) role.Role {
 	return &mapper{
 		codec:           codec,
 		dialer:          dialer,
 		log:             log.Context("Mapper"),
+		metrics:         m.With(metrics.L("role", "mapper")),
+		sessions:        s.With("mapper"),
 		cfg:             cfg,
 		transceiver:     nil,
 		ticker:          nil,
@@ -105,7 +116,8 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 
 	// Open transceiver client first
 	trServe, trServeErr := tclient.New(
-		0, s.log, s.dialer, s.codec, s.ticker, tclient.Config{
+		0, s.log, s.metrics, s.sessions, s.dialer, s.codec, s.ticker,
+		tclient.Config{
 			MaxConcurrent:        s.cfg.TransceiverMaxConnections,
 			RequestRetries:       s.cfg.TransceiverRequestRetries,
 			IdleTimeout:          s.cfg.TransceiverIdleTimeout,
@@ -149,14 +161,51 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 		var serving network.Serving
 		var serveErr error
 
+		mappingMetrics := s.metrics.With(metrics.L("mapping",
+			strconv.FormatUint(uint64(s.cfg.Mapping[mIdx].ID), 10)))
+
+		addresses := make([]string, len(s.cfg.Mapping[mIdx].Interfaces))
+
+		for iIdx := range s.cfg.Mapping[mIdx].Interfaces {
+			addresses[iIdx] = net.JoinHostPort(
+				s.cfg.Mapping[mIdx].Interfaces[iIdx].String(),
+				strconv.FormatUint(uint64(s.cfg.Mapping[mIdx].Port), 10))
+		}
+
+		address := strings.Join(addresses, ", ")
+
+		if s.cfg.Mapping[mIdx].Socket != "" {
+			address = s.cfg.Mapping[mIdx].Socket
+		}
+
+		inherit.Separate(
+			s.cfg.Mapping[mIdx].Interfaces, s.cfg.Mapping[mIdx].Port)
+
+		listeners := make(
+			[]network.Listener, len(s.cfg.Mapping[mIdx].Interfaces))
+
 		switch s.cfg.Mapping[mIdx].Protocol {
 		case network.TCP:
-			serving, serveErr = server.New(tcplistener.New(
-				s.cfg.Mapping[mIdx].Interface,
-				s.cfg.Mapping[mIdx].Port,
-				tcpconn.Wrap,
-			), tcpHandler{
+			if s.cfg.Mapping[mIdx].Socket != "" {
+				listeners = []network.Listener{unixlistener.New(
+					s.cfg.Mapping[mIdx].Socket,
+					s.cfg.Mapping[mIdx].SocketConfig,
+					tcpconn.Wrap,
+				)}
+			} else {
+				for iIdx := range listeners {
+					listeners[iIdx] = tcplistener.New(
+						s.cfg.Mapping[mIdx].Interfaces[iIdx],
+						s.cfg.Mapping[mIdx].Port,
+						s.cfg.SocketOptions,
+						tcpconn.Wrap,
+					)
+				}
+			}
+
+			serving, serveErr = server.New(listeners, tcpHandler{
 				mapper:      s.cfg.Mapping[mIdx].ID,
+				metrics:     mappingMetrics,
 				runner:      s.runner,
 				shb:         shb,
 				transceiver: s.transceiver,
@@ -164,26 +213,28 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 				reqTimeout:  s.cfg.TransceiverInitialTimeout,
 			}, s.log.Context(strconv.FormatUint(
 				uint64(s.cfg.Mapping[mIdx].ID), 10)+" ("+
-				s.cfg.Mapping[mIdx].Protocol.String()+" "+
-				net.JoinHostPort(s.cfg.Mapping[mIdx].Interface.String(),
-					strconv.FormatUint(
-						uint64(s.cfg.Mapping[mIdx].Port), 10))+")",
-			), s.runner, server.Config{
+				s.cfg.Mapping[mIdx].Protocol.String()+" "+address+")",
+			), mappingMetrics, s.sessions, s.runner, server.Config{
 				AcceptErrorWait: 300 * time.Millisecond,
 				MaxConnections:  s.cfg.Mapping[mIdx].Capacity,
 			}).Serve()
 
 		case network.UDP:
-			serving, serveErr = server.New(udplistener.New(
-				s.cfg.Mapping[mIdx].Interface,
-				s.cfg.Mapping[mIdx].Port,
-				s.cfg.TransceiverIdleTimeout,
-				s.cfg.Mapping[mIdx].Capacity,
-				make([]byte, 4096),
-				s.ticker,
-				udpconn.Wrap,
-			), udpHandler{
+			for iIdx := range listeners {
+				listeners[iIdx] = udplistener.New(
+					s.cfg.Mapping[mIdx].Interfaces[iIdx],
+					s.cfg.Mapping[mIdx].Port,
+					s.cfg.TransceiverIdleTimeout,
+					s.cfg.Mapping[mIdx].Capacity,
+					make([]byte, 4096),
+					s.ticker,
+					udpconn.Wrap,
+				)
+			}
+
+			serving, serveErr = server.New(listeners, udpHandler{
 				mapper:      s.cfg.Mapping[mIdx].ID,
+				metrics:     mappingMetrics,
 				runner:      s.runner,
 				shb:         shb,
 				transceiver: s.transceiver,
@@ -191,11 +242,8 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 				reqTimeout:  s.cfg.TransceiverInitialTimeout,
 			}, s.log.Context(strconv.FormatUint(
 				uint64(s.cfg.Mapping[mIdx].ID), 10)+" ("+
-				s.cfg.Mapping[mIdx].Protocol.String()+" "+
-				net.JoinHostPort(s.cfg.Mapping[mIdx].Interface.String(),
-					strconv.FormatUint(
-						uint64(s.cfg.Mapping[mIdx].Port), 10))+")",
-			), s.runner, server.Config{
+				s.cfg.Mapping[mIdx].Protocol.String()+" "+address+")",
+			), mappingMetrics, s.sessions, s.runner, server.Config{
 				AcceptErrorWait: 300 * time.Millisecond,
 				MaxConnections:  s.cfg.Mapping[mIdx].Capacity,
 			}).Serve()
@@ -206,10 +254,7 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 
 		if serveErr != nil {
 			s.log.Errorf("Failed to boot up server for mapper %d on \"%s\"",
-				s.cfg.Mapping[mIdx].ID, net.JoinHostPort(
-					s.cfg.Mapping[mIdx].Interface.String(),
-					strconv.FormatUint(uint64(
-						s.cfg.Mapping[mIdx].Port), 10)))
+				s.cfg.Mapping[mIdx].ID, address)
 
 			return serveErr
 		}
@@ -219,7 +264,7 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 		s.log.Infof("Serving %s mapper %d on \"%s\"",
 			s.cfg.Mapping[mIdx].Protocol.String(),
 			s.cfg.Mapping[mIdx].ID,
-			serving.Listening())
+			address)
 
 		startedServer++
 	}
@@ -229,6 +274,27 @@ func (s *mapper) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	return nil
 }
 
+// Health returns whether or not all mapping servers are serving
+func (s *mapper) Health() error {
+	if len(s.servers) <= 0 {
+		return role.ErrNotListening
+	}
+
+	for sIdx := range s.servers {
+		if s.servers[sIdx] == nil {
+			return role.ErrNotListening
+		}
+
+		hErr := s.servers[sIdx].Health()
+
+		if hErr != nil {
+			return hErr
+		}
+	}
+
+	return nil
+}
+
 func (s *mapper) Unspawn() error {
 	s.log.Infof("Closing")
 
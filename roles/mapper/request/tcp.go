@@ -25,6 +25,7 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -45,6 +46,7 @@ type tcp struct {
 func TCP(
 	mapper proxycommon.MapID,
 	client network.Connection,
+	m metrics.Registry,
 	runner worker.Runner,
 	timeout time.Duration,
 	shb *common.SharedBuffer,
@@ -57,7 +59,7 @@ func TCP(
 	) fsm.Machine {
 		return tcp{
 			log: log,
-			relay: relay.New(log, runner, conn, shb.Select(id), tcpRelay{
+			relay: relay.New(log, m, runner, conn, shb.Select(id), tcpRelay{
 				mapper:  mapper,
 				client:  client,
 				timeout: timeout,
@@ -21,31 +21,95 @@
 package mapper
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/print"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/arq"
+	"github.com/reinit/coward/roles/common/network/bond"
 	tcpconn "github.com/reinit/coward/roles/common/network/connection/tcp"
+	arqdial "github.com/reinit/coward/roles/common/network/dialer/arq"
+	bonddial "github.com/reinit/coward/roles/common/network/dialer/bond"
+	hopdial "github.com/reinit/coward/roles/common/network/dialer/hop"
 	"github.com/reinit/coward/roles/common/network/dialer/tcp"
+	tlsdial "github.com/reinit/coward/roles/common/network/dialer/tls"
+	wsdial "github.com/reinit/coward/roles/common/network/dialer/websocket"
+	unixlisten "github.com/reinit/coward/roles/common/network/listener/unix"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/network/websocket"
 	"github.com/reinit/coward/roles/common/transceiver"
 	"github.com/reinit/coward/roles/proxy/common"
 )
 
+// ConfigUnix Unix domain socket configurations
+type ConfigUnix struct {
+	built   unixlisten.Config
+	enabled bool
+	Path    string `json:"path" cfg:"p,-path:Path of the socket file.\r\n\r\nA stale socket file that left on the path by a previous run will be replaced."`
+	Mode    string `json:"mode" cfg:"m,-mode:Permission of the socket file in octal, for example \"0660\".\r\n\r\nThe permission that given by the umask will be kept when this is not specified."`
+	Owner   string `json:"owner" cfg:"o,-owner:Owner of the socket file in \"<User>[:<Group>]\" format, both name and numeric ID are accepted.\r\n\r\nThe owner will not be changed when this is not specified."`
+}
+
+// Verify Verifies
+func (c *ConfigUnix) Verify() error {
+	if c.Path == "" {
+		return errors.New("Socket Path must be specified")
+	}
+
+	c.built = unixlisten.Config{
+		Mode: 0,
+		UID:  -1,
+		GID:  -1,
+	}
+
+	if c.Mode != "" {
+		mode, modeErr := unixlisten.ParseMode(c.Mode)
+
+		if modeErr != nil {
+			return modeErr
+		}
+
+		c.built.Mode = mode
+	}
+
+	if c.Owner != "" {
+		uid, gid, ownerErr := unixlisten.ParseOwner(c.Owner)
+
+		if ownerErr != nil {
+			return errors.New("Invalid socket Owner: " + ownerErr.Error())
+		}
+
+		c.built.UID = uid
+		c.built.GID = gid
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
 // ConfigMapping Mapping Configuration
 type ConfigMapping struct {
-	selectProto       network.Protocol
-	selectedInterface net.IP
-	ID                uint8  `json:"id" cfg:"i,-id:Mapping Item ID.\r\n\r\nMust matchs the setting defined on the COWARD Proxy."`
-	Protocol          string `json:"protocol" cfg:"o,-protocol:Protocol type of the remote destination.\r\n\r\nMust matchs the setting defined on the COWARD Proxy."`
-	Interface         string `json:"interface" cfg:"a,-interface:Specify a local network interface to serve for the mapped destination."`
-	Port              uint16 `json:"port" cfg:"p,-port:Specify a local port to serve for the mapped destination."`
-	Capacity          uint32 `json:"capacity" cfg:"c,-capacity:The maximum connections this Mapping server can accept.\r\n\r\nWhen amount of connections reached this limitation, new incoming connection will be dropped."`
+	selectProto        network.Protocol
+	selectedInterface  net.IP
+	selectedInterfaces []net.IP
+	ID                 uint8      `json:"id" cfg:"i,-id:Mapping Item ID.\r\n\r\nMust matchs the setting defined on the COWARD Proxy."`
+	Protocol           string     `json:"protocol" cfg:"o,-protocol:Protocol type of the remote destination.\r\n\r\nMust matchs the setting defined on the COWARD Proxy."`
+	Interface          string     `json:"interface" cfg:"a,-interface:Specify a local network interface to serve for the mapped destination."`
+	Interfaces         []string   `json:"interfaces" cfg:"as,-interfaces:Additional local network interfaces to serve for the mapped destination, by specify their IP addresses.\r\n\r\nThe mapped destination will be served on the same Port of all of these interfaces as well as the one specified by Interface, and connections accepted from all of them share the same Capacity.\r\n\r\nAn unspecified address such as \"0.0.0.0\" or \"::\" accepts both IPv4 and IPv6 connections, unless both \"0.0.0.0\" and \"::\" are selected, in which case each of them only accepts connections of it's own IP family. Can not be used together with Unix."`
+	Port               uint16     `json:"port" cfg:"p,-port:Specify a local port to serve for the mapped destination."`
+	Unix               ConfigUnix `json:"unix" cfg:"u,-unix:Serve the mapped destination on a unix domain socket instead of the Interface and Port.\r\n\r\nLocal tools and containers that share the socket file can then access the mapped destination under the control of the file permission. Only available for the \"tcp\" Protocol."`
+	Capacity           uint32     `json:"capacity" cfg:"c,-capacity:The maximum connections this Mapping server can accept.\r\n\r\nWhen amount of connections reached this limitation, new incoming connection will be dropped."`
 }
 
 // VerifyProtocol Verify Protocol
@@ -56,6 +120,10 @@ func (c *ConfigMapping) VerifyProtocol() error {
 		return protocolErr
 	}
 
+	if c.selectProto == network.Unix {
+		return errors.New("Unix protocol is not supported here")
+	}
+
 	return nil
 }
 
@@ -91,7 +159,11 @@ func (c *ConfigMapping) Verify() error {
 		return fmt.Errorf("Protocol must be defined")
 	}
 
-	if c.Interface == "" {
+	if c.Unix.enabled && c.selectProto != network.TCP {
+		return errors.New("Unix domain socket can only serve the TCP Protocol")
+	}
+
+	if c.Interface == "" && !c.Unix.enabled {
 		return errors.New("Interface must be specified")
 	}
 
@@ -103,24 +175,210 @@ func (c *ConfigMapping) Verify() error {
 		return errors.New("Capacity must be specified")
 	}
 
+	if c.Unix.enabled && len(c.Interfaces) > 0 {
+		return errors.New("Interfaces can not be used together with Unix")
+	}
+
+	c.selectedInterfaces = make([]net.IP, 1, len(c.Interfaces)+1)
+	c.selectedInterfaces[0] = c.selectedInterface
+
+	for iIdx := range c.Interfaces {
+		selectedIP := net.ParseIP(c.Interfaces[iIdx])
+
+		if selectedIP == nil {
+			return errors.New(
+				"Invalid Interfaces address \"" + c.Interfaces[iIdx] + "\"")
+		}
+
+		c.selectedInterfaces = append(c.selectedInterfaces, selectedIP)
+	}
+
+	return nil
+}
+
+// ConfigTLS TLS configurations
+type ConfigTLS struct {
+	built       *tls.Config
+	ServerName  string   `json:"server_name" cfg:"s,-server-name:Server name that will be sent through SNI and be used to verify the server certificate.\r\n\r\nThe Host name of the COWARD Proxy server will be used when this is not specified."`
+	ALPN        []string `json:"alpn" cfg:"a,-alpn:Application protocol names that will be offered through ALPN during the TLS handshake."`
+	CA          string   `json:"ca" cfg:"ca,-ca:Path to a PEM encoded CA certificate file.\r\n\r\nOnce specified, the server certificate will be verified against the CAs in the file instead of the CAs of the system."`
+	Pins        []string `json:"pins" cfg:"pn,-pins:Pinned public keys of the COWARD Proxy server, each of them is a Base64 encoded SHA-256 hash of the SubjectPublicKeyInfo of a certificate.\r\n\r\nOnce specified, the connection will be rejected unless the server certificate matchs the pins. The server certificate will only be verified against the CA when \"--ca\" is also specified, so a self-signed certificate can be used on the server. When \"--ca\" is specified, the pins can also match the intermediate or root certificates of the verified chain."`
+	Certificate string   `json:"certificate" cfg:"c,-certificate:Path to a PEM encoded client certificate file that will be presented to the COWARD Proxy server."`
+	Key         string   `json:"key" cfg:"k,-key:Path to the PEM encoded private key file of the client certificate."`
+}
+
+// Verify Verify all configrations
+func (c *ConfigTLS) Verify() error {
+	built, buildErr := tlsdial.Config{
+		ServerName:  c.ServerName,
+		ALPN:        c.ALPN,
+		CA:          c.CA,
+		Pins:        c.Pins,
+		Certificate: c.Certificate,
+		Key:         c.Key,
+	}.Build()
+
+	if buildErr != nil {
+		return buildErr
+	}
+
+	c.built = built
+
+	return nil
+}
+
+// ConfigWebSocket WebSocket configurations
+type ConfigWebSocket struct {
+	built   websocket.Config
+	enabled bool
+	Path    string   `json:"path" cfg:"p,-path:The path which the WebSocket handshake request will be sent to. Default: \"/\""`
+	Host    string   `json:"host" cfg:"h,-host:Value of the Host header of the WebSocket handshake request.\r\n\r\nThe Host name and Port of the COWARD Proxy server will be used when this is not specified."`
+	Headers []string `json:"headers" cfg:"hd,-headers:Extra headers of the WebSocket handshake request, each of them must be in \"<Name>: <Value>\" format."`
+}
+
+// VerifyPath Verify Path
+func (c *ConfigWebSocket) VerifyPath() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return errors.New("Path must be started with \"/\"")
+	}
+
 	return nil
 }
 
+// Verify Verify all configrations
+func (c *ConfigWebSocket) Verify() error {
+	headers, headersErr := websocket.ParseHeaders(c.Headers)
+
+	if headersErr != nil {
+		return headersErr
+	}
+
+	if c.Path == "" {
+		c.Path = websocket.DefaultPath
+	}
+
+	c.built = websocket.Config{
+		Path:    c.Path,
+		Host:    c.Host,
+		Headers: headers,
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigReliableUDP Reliable UDP configurations
+type ConfigReliableUDP struct {
+	built      arq.Config
+	enabled    bool
+	Window     uint16 `json:"window" cfg:"w,-window:The amount of segments can be sent without being acknowledged, as well as the amount of segments can be buffered for receiving.\r\n\r\nLarger window allows higher throughput on long-haul links at the cost of memory. Default: 128"`
+	RTO        uint16 `json:"rto" cfg:"r,-rto:The minimal retransmission timeout in millisecond.\r\n\r\nSegments that are not acknowledged within the timeout will be retransmitted. Default: 100"`
+	FastResend uint8  `json:"fast_resend" cfg:"f,-fast-resend:Retransmit a segment right away once this amount of segments sent after it has been acknowledged, rather than waiting for it to be timed out. Default: 2"`
+	FEC        uint8  `json:"fec" cfg:"x,-fec:Send a XOR parity datagram after every this amount of datagrams, so any single lost datagram among them can be recovered without retransmission.\r\n\r\nSet to 0 to disable. Must matchs the setting on the other side. Default: 0"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigReliableUDP) Verify() error {
+	if c.Window <= 0 {
+		c.Window = arq.DefaultWindow
+	}
+
+	if c.RTO <= 0 {
+		c.RTO = uint16(arq.DefaultMinRTO / time.Millisecond)
+	}
+
+	if c.FastResend <= 0 {
+		c.FastResend = arq.DefaultFastResend
+	}
+
+	c.built = arq.Config{
+		Window:     c.Window,
+		MinRTO:     time.Duration(c.RTO) * time.Millisecond,
+		FastResend: c.FastResend,
+		FEC:        c.FEC,
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigHop Port hopping configurations
+type ConfigHop struct {
+	enabled bool
+	Ports   uint16 `json:"ports" cfg:"p,-ports:How many consecutive ports starting from Port the COWARD Proxy server is listening on.\r\n\r\nMust matchs the \"Hop Ports\" setting on server."`
+	Key     string `json:"key" cfg:"k,-key:A shared key which decides the port to pick for each connection in each time period.\r\n\r\nMust be at least 16 characters long. It is recommended to use a key that is different from the one of the Codec."`
+	Window  uint16 `json:"window" cfg:"w,-window:The length of the time period in second.\r\n\r\nThe picks of the ports will be changed in every time period. Default: 60"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigHop) Verify() error {
+	if c.Ports < 2 {
+		return errors.New("Hop Ports must be greater than 1")
+	}
+
+	if len(c.Key) < 16 {
+		return errors.New(
+			"Hop Key was too short. Make it at least 16 characters long")
+	}
+
+	if c.Window <= 0 {
+		c.Window = uint16(hopdial.DefaultWindow / time.Second)
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigSocket Socket option configurations
+type ConfigSocket struct {
+	built         sockopt.Options
+	KeepAlive     uint16 `json:"keepalive" cfg:"k,-keepalive:Period in second of the TCP keepalive, which is how long a connection must stay idle before the keepalive probes are sent.\r\n\r\nSet to 0 to use the system default."`
+	SendBuffer    uint32 `json:"send_buffer" cfg:"sb,-send-buffer:Size of the send buffer (SO_SNDBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	ReceiveBuffer uint32 `json:"receive_buffer" cfg:"rb,-receive-buffer:Size of the receive buffer (SO_RCVBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	NoDelay       bool   `json:"no_delay" cfg:"n,-no-delay:Send data right away rather than waiting for more data to be combined into one packet (TCP_NODELAY).\r\n\r\nEnable it for interactive sessions such as SSH, keep it disabled for bulk transfers to save bandwidth. Default: no"`
+	Mark          uint32 `json:"mark" cfg:"m,-mark:Mark the sockets with this firewall mark (SO_MARK), so they can be handled by the policy routing rules.\r\n\r\nOnly supported on Linux, and requires the CAP_NET_ADMIN capability. Set to 0 to disable."`
+	FastOpen      bool   `json:"fast_open" cfg:"f,-fast-open:Enable TCP Fast Open, so data can be carried by the handshake packets to save a round trip.\r\n\r\nOnly supported on Linux, and must also be enabled through the \"net.ipv4.tcp_fastopen\" system setting. Default: no"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigSocket) Verify() error {
+	c.built = sockopt.Options{
+		KeepAlive:     time.Duration(c.KeepAlive) * time.Second,
+		SendBuffer:    int(c.SendBuffer),
+		ReceiveBuffer: int(c.ReceiveBuffer),
+		NoDelay:       c.NoDelay,
+		Mark:          c.Mark,
+		FastOpen:      c.FastOpen,
+	}
+
+	return c.built.Verify()
+}
+
 // ConfigInput Configuration
 type ConfigInput struct {
 	components     []interface{}
 	selectedCodec  transceiver.Codec
-	Host           string          `json:"host" cfg:"h,-host:Host name of the remote COWARD Proxy server.\r\n\r\nMust matchs the configuration on the Proxy server."`
-	Port           uint16          `json:"port" cfg:"p,-port:Port number of the remote COWARD Proxy server.\r\n\r\nMust matchs the configuration on the Proxy server."`
-	Connections    uint32          `json:"connections" cfg:"c,-connections:The maximum concurrent connections that can be established with a COWARD Proxy Server."`
-	Persistent     bool            `json:"persist" cfg:"k,-persist:Whether or not to keep the connection to the COWARD Proxy active even after all requests on the connection is completed."`
-	RequestRetries uint8           `json:"retries" cfg:"r,-retries:How many times a failed Initial request can be retried."`
-	Channels       uint8           `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection can be use to transport multiple requests (Multiplexing).\r\n\r\nWARNING:\r\nThis value must matchs or smaller than the related setting on the COWARD Proxy server, otherwise the request will be come malformed and thus dropped."`
-	Timeout        uint16          `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of a established proxy connection.\r\n\r\nIf the proxy connection consecutively idle during this period of time, then that connection will be considered as inactive and thus be disconnected.\r\n\r\nIt is recommended to set this value no greater than the related one on the COWARD Proxy server setting."`
-	RequestTimeout uint16          `json:"request_timeout" cfg:"rt,-request-timeout:The maximum wait time in second for the server to respond the Initial request of a client.\r\n\r\nIf the COWARD Proxy server has failed to respond the Initial request within this period of time, the connection will be considered broken and thus be closed.\r\n\r\nIt is recommended to set this value slightly greater than the \"--initial-timeout\" setting on the COWARD Proxy server."`
-	Mapping        []ConfigMapping `json:"mapping" cfg:"m,-mapping:Enable and configure mapped remote destinations.\r\n\r\nThis will allow you to map the pre-defined destinations on the Proxy as local servers.\r\n\r\nAll access to these servers will be relayed to their corresponding remote destinations transparently through the COWARD Proxy server."`
-	Codec          string          `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection."`
-	CodecSetting   []string        `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing."`
+	selectedBond   []net.IP
+	Host           string            `json:"host" cfg:"h,-host:Host name of the remote COWARD Proxy server.\r\n\r\nMust matchs the configuration on the Proxy server."`
+	Port           uint16            `json:"port" cfg:"p,-port:Port number of the remote COWARD Proxy server.\r\n\r\nMust matchs the configuration on the Proxy server."`
+	Connections    uint32            `json:"connections" cfg:"c,-connections:The maximum concurrent connections that can be established with a COWARD Proxy Server."`
+	Persistent     bool              `json:"persist" cfg:"k,-persist:Whether or not to keep the connection to the COWARD Proxy active even after all requests on the connection is completed."`
+	RequestRetries uint8             `json:"retries" cfg:"r,-retries:How many times a failed Initial request can be retried."`
+	Channels       uint8             `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection can be use to transport multiple requests (Multiplexing).\r\n\r\nWARNING:\r\nThis value must matchs or smaller than the related setting on the COWARD Proxy server, otherwise the request will be come malformed and thus dropped."`
+	Timeout        uint16            `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of a established proxy connection.\r\n\r\nIf the proxy connection consecutively idle during this period of time, then that connection will be considered as inactive and thus be disconnected.\r\n\r\nIt is recommended to set this value no greater than the related one on the COWARD Proxy server setting."`
+	RequestTimeout uint16            `json:"request_timeout" cfg:"rt,-request-timeout:The maximum wait time in second for the server to respond the Initial request of a client.\r\n\r\nIf the COWARD Proxy server has failed to respond the Initial request within this period of time, the connection will be considered broken and thus be closed.\r\n\r\nIt is recommended to set this value slightly greater than the \"--initial-timeout\" setting on the COWARD Proxy server."`
+	Mapping        []ConfigMapping   `json:"mapping" cfg:"m,-mapping:Enable and configure mapped remote destinations.\r\n\r\nThis will allow you to map the pre-defined destinations on the Proxy as local servers.\r\n\r\nAll access to these servers will be relayed to their corresponding remote destinations transparently through the COWARD Proxy server."`
+	Codec          string            `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection.\r\n\r\nMultiple Codecs can be chained by separating their names with \",\". Data will be encoded by the Codecs in the listed order before being sent, and decoded in the reverse order."`
+	CodecSetting   []string          `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing.\r\n\r\nWhen Codecs are chained, the setting of each Codec must be placed after a \"[<Codec Name>]\" line."`
+	TLS            ConfigTLS         `json:"tls" cfg:"tl,-tls:Enable and configure the TLS transport.\r\n\r\nOnce enabled, connections to the COWARD Proxy server will be established through TLS.\r\n\r\nMust matchs the setting on server."`
+	WebSocket      ConfigWebSocket   `json:"websocket" cfg:"ws,-websocket:Enable and configure the WebSocket transport.\r\n\r\nOnce enabled, connections to the COWARD Proxy server will be established through WebSocket, so they can be forwarded by HTTP reverse proxies. When the TLS transport is enabled as well, the WebSocket will be carried by TLS.\r\n\r\nMust matchs the setting on server."`
+	ReliableUDP    ConfigReliableUDP `json:"reliable_udp" cfg:"ru,-reliable-udp:Enable and configure the Reliable UDP transport.\r\n\r\nOnce enabled, connections to the COWARD Proxy server will be carried by an ARQ (Automatic Repeat reQuest) protocol over UDP which retransmits lost data more aggressively than TCP does, so the throughput on lossy long-haul links can be much higher.\r\n\r\nCan not be used together with the TLS or WebSocket transport. Must matchs the setting on server."`
+	Bond           []string          `json:"bond" cfg:"bd,-bond:Local IP addresses of the network interfaces to bond.\r\n\r\nOnce specified, every connection to the COWARD Proxy server will be established as one or more links, one through each of the listed interfaces. Data of the connection will be striped across all of it's links so their bandwidth is combined, and the connection will survive as long as one of the links is still alive.\r\n\r\nAn interface can be listed multiple times to establish multiple links through it. Set an item to \"0.0.0.0\" (or \"::\" for IPv6) to let the system select the interface.\r\n\r\nAt most 16 links can be bonded. Links are encoded with the Codec, so the Codec setting must be the same as the server.\r\n\r\nCan only be used with the plain TCP transport. Requires Bond to be enabled on server."`
+	Hop            ConfigHop         `json:"hop" cfg:"hp,-hop:Enable and configure port hopping.\r\n\r\nOnce enabled, connections to the COWARD Proxy server will be spread across a range of ports starting from Port, and the picks will be changed periodically, so an observer will not see a single fixed destination.\r\n\r\nRequires \"Hop Ports\" to be set on server."`
+	Socket         ConfigSocket      `json:"socket" cfg:"so,-socket:Tune the options of the TCP sockets.\r\n\r\nConnections of the plain TCP (including the bonded ones), TLS and WebSocket transport are affected, connections of the Reliable UDP transport will keep using the default options."`
 }
 
 // GetDescription gets description
@@ -128,7 +386,7 @@ func (c ConfigInput) GetDescription(fieldPath string) string {
 	result := ""
 
 	switch fieldPath {
-	case "/Mapping/Interface":
+	case "/Mapping/Interface", "/Mapping/Interfaces":
 		ifAddrs, ifAddrsErr := net.InterfaceAddrs()
 
 		if ifAddrsErr != nil {
@@ -233,24 +491,15 @@ func (c *ConfigInput) VerifyRequestTimeout() error {
 
 // VerifyCodec Verify Codec
 func (c *ConfigInput) VerifyCodec() error {
-	for cIdx := range c.components {
-		codecBuilder, isCodecBuilder :=
-			c.components[cIdx].(func() transceiver.Codec)
-
-		if !isCodecBuilder {
-			continue
-		}
-
-		codecInfo := codecBuilder()
+	selected, selectErr := transceiver.SelectCodec(c.Codec, c.components)
 
-		if codecInfo.Name == c.Codec {
-			c.selectedCodec = codecInfo
-
-			return nil
-		}
+	if selectErr != nil {
+		return selectErr
 	}
 
-	return errors.New("Specified Codec was not found")
+	c.selectedCodec = selected
+
+	return nil
 }
 
 // VerifyCodecSetting Verify CodecSetting
@@ -312,6 +561,37 @@ func (c *ConfigInput) Verify() error {
 		}
 	}
 
+	if c.ReliableUDP.enabled && (c.TLS.built != nil || c.WebSocket.enabled) {
+		return errors.New("Reliable UDP transport can not be used " +
+			"together with TLS or WebSocket transport")
+	}
+
+	c.selectedBond = make([]net.IP, len(c.Bond))
+
+	for bIdx := range c.Bond {
+		c.selectedBond[bIdx] = net.ParseIP(c.Bond[bIdx])
+
+		if c.selectedBond[bIdx] != nil {
+			continue
+		}
+
+		return errors.New("Invalid Bond interface \"" + c.Bond[bIdx] + "\"")
+	}
+
+	if len(c.selectedBond) > 0 &&
+		(c.ReliableUDP.enabled || c.TLS.built != nil || c.WebSocket.enabled) {
+		return errors.New("Bond can only be used with TCP transport")
+	}
+
+	if len(c.selectedBond) > bond.MaxLinks {
+		return errors.New("Bond can not have more than " +
+			strconv.Itoa(bond.MaxLinks) + " interfaces")
+	}
+
+	if c.Hop.enabled && uint32(c.Port)+uint32(c.Hop.Ports) > 65536 {
+		return errors.New("Hop Ports exceeds the maximum port number")
+	}
+
 	return nil
 }
 
@@ -335,38 +615,161 @@ func Role() role.Registration {
 				Mapping:        []ConfigMapping{},
 				Codec:          "",
 				CodecSetting:   nil,
+				TLS: ConfigTLS{
+					built:       nil,
+					ServerName:  "",
+					ALPN:        nil,
+					CA:          "",
+					Pins:        nil,
+					Certificate: "",
+					Key:         "",
+				},
+				WebSocket: ConfigWebSocket{
+					built:   websocket.Config{},
+					enabled: false,
+					Path:    "",
+					Host:    "",
+					Headers: nil,
+				},
+				ReliableUDP: ConfigReliableUDP{
+					built:      arq.Config{},
+					enabled:    false,
+					Window:     0,
+					RTO:        0,
+					FastResend: 0,
+					FEC:        0,
+				},
+				Bond: nil,
+				Hop: ConfigHop{
+					enabled: false,
+					Ports:   0,
+					Key:     "",
+					Window:  0,
+				},
+				Socket: ConfigSocket{
+					built:         sockopt.Options{},
+					KeepAlive:     0,
+					SendBuffer:    0,
+					ReceiveBuffer: 0,
+					NoDelay:       false,
+					Mark:          0,
+					FastOpen:      false,
+				},
 			}
 		},
 		Generater: func(
 			w print.Common,
 			config interface{},
 			log logger.Logger,
+			m metrics.Registry,
+			s session.Sessions,
 		) (role.Role, error) {
 			cfg := config.(*ConfigInput)
 
-			dialer := tcp.New(
-				cfg.Host,
-				cfg.Port,
-				time.Duration(cfg.RequestTimeout)*time.Second,
-				tcpconn.Wrap,
-			)
+			codecBuilder := cfg.selectedCodec.Build(cfg.CodecSetting, log,
+				m.With(metrics.L("role", "mapper")))
+
+			dialTo := func(port uint16) network.Dialer {
+				switch {
+				case len(cfg.selectedBond) > 0:
+					links := make([]network.Dialer, len(cfg.selectedBond))
+
+					for lIdx := range cfg.selectedBond {
+						links[lIdx] = tcp.NewFrom(
+							cfg.selectedBond[lIdx],
+							cfg.Host,
+							port,
+							time.Duration(cfg.RequestTimeout)*time.Second,
+							cfg.Socket.built,
+							tcpconn.Wrap,
+						)
+					}
+
+					return bonddial.New(
+						links,
+						codecBuilder,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						tcpconn.Wrap)
+
+				case cfg.ReliableUDP.enabled:
+					return arqdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.ReliableUDP.built,
+						tcpconn.Wrap,
+					)
+
+				case cfg.WebSocket.enabled:
+					return wsdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.WebSocket.built,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+
+				case cfg.TLS.built != nil:
+					return tlsdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+
+				default:
+					return tcp.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+				}
+			}
+
+			var dialer network.Dialer
+
+			if cfg.Hop.enabled {
+				ports := make([]network.Dialer, cfg.Hop.Ports)
+
+				for pIdx := range ports {
+					ports[pIdx] = dialTo(cfg.Port + uint16(pIdx))
+				}
+
+				dialer = hopdial.New(ports, []byte(cfg.Hop.Key),
+					time.Duration(cfg.Hop.Window)*time.Second)
+			} else {
+				dialer = dialTo(cfg.Port)
+			}
 
 			mapps := make([]Mapped, len(cfg.Mapping))
 
 			for mIdx := range cfg.Mapping {
 				mapps[mIdx] = Mapped{
-					ID:        common.MapID(cfg.Mapping[mIdx].ID),
-					Interface: cfg.Mapping[mIdx].selectedInterface,
-					Port:      cfg.Mapping[mIdx].Port,
-					Protocol:  cfg.Mapping[mIdx].selectProto,
-					Capacity:  cfg.Mapping[mIdx].Capacity,
+					ID:         common.MapID(cfg.Mapping[mIdx].ID),
+					Interfaces: cfg.Mapping[mIdx].selectedInterfaces,
+					Port:       cfg.Mapping[mIdx].Port,
+					Protocol:   cfg.Mapping[mIdx].selectProto,
+					Capacity:   cfg.Mapping[mIdx].Capacity,
+				}
+
+				if cfg.Mapping[mIdx].Unix.enabled {
+					mapps[mIdx].Socket = cfg.Mapping[mIdx].Unix.Path
+					mapps[mIdx].SocketConfig = cfg.Mapping[mIdx].Unix.built
 				}
 			}
 
 			return New(
-				cfg.selectedCodec.Build(cfg.CodecSetting),
+				codecBuilder,
 				dialer,
 				log,
+				m,
+				s,
 				Config{
 					TransceiverMaxConnections: cfg.Connections,
 					TransceiverRequestRetries: cfg.RequestRetries,
@@ -377,6 +780,7 @@ func Role() role.Registration {
 					TransceiverConnectionPersistent: cfg.Persistent,
 					TransceiverChannels:             cfg.Channels,
 					Mapping:                         mapps,
+					SocketOptions:                   cfg.Socket.built,
 				}), nil
 		},
 	}
@@ -26,7 +26,9 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -47,6 +49,9 @@ var (
 
 	ErrUnknownEndpintNetworkProtocol = errors.New(
 		"Unknown Endpoint protocol")
+
+	ErrNotProjecting = errors.New(
+		"Not projecting")
 )
 
 const (
@@ -57,6 +62,8 @@ type projectile struct {
 	codec           transceiver.CodecBuilder
 	dialer          network.Dialer
 	logger          logger.Logger
+	metrics         metrics.Registry
+	sessions        session.Sessions
 	cfg             Config
 	transceiver     transceiver.Requester
 	runner          worker.Runner
@@ -70,12 +77,16 @@ func New(
 	codec transceiver.CodecBuilder,
 	dialer network.Dialer,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) role.Role {
 	return &projectile{
 		codec:           codec,
 		dialer:          dialer,
 		logger:          log.Context("Project"),
+		metrics:         m.With(metrics.L("role", "project")),
+		sessions:        s.With("project"),
 		cfg:             cfg,
 		transceiver:     nil,
 		runner:          nil,
@@ -125,7 +136,8 @@ func (s *projectile) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	// so we only effected by the network failure rather than the internal
 	// read timeout failure
 	trServe, trServeErr := tclient.New(
-		0, s.logger, s.dialer, s.codec, nil, tclient.Config{
+		0, s.logger, s.metrics, s.sessions, s.dialer, s.codec, nil,
+		tclient.Config{
 			MaxConcurrent:        trConnections,
 			RequestRetries:       1, // We'll do retry manually
 			IdleTimeout:          s.cfg.TransceiverIdleTimeout,
@@ -149,7 +161,8 @@ func (s *projectile) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 				Endpoint: s.cfg.Endpoints[epIdx],
 				Dialer: tcp.New(
 					s.cfg.Endpoints[epIdx].Host, s.cfg.Endpoints[epIdx].Port,
-					s.cfg.Endpoints[epIdx].RequestTimeout, tcpconn.Wrap),
+					s.cfg.Endpoints[epIdx].RequestTimeout,
+					s.cfg.SocketOptions, tcpconn.Wrap),
 				MinWorkers: pcommon.AutomaticalMinWorkerCount(
 					s.cfg.Endpoints[epIdx].MaxConnections, 64),
 			}
@@ -178,6 +191,7 @@ func (s *projectile) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 
 	pProjects, pProjectErr := project.New(
 		s.logger,
+		s.metrics,
 		s.transceiver,
 		s.runner,
 		s.ticker,
@@ -205,6 +219,16 @@ func (s *projectile) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	return nil
 }
 
+// Health returns whether or not the Projectile is projecting. Projectile
+// has no listener, so it's healthy as long as the projects are up
+func (s *projectile) Health() error {
+	if s.projects == nil {
+		return ErrNotProjecting
+	}
+
+	return nil
+}
+
 // Unspawn shuts down the Projectile
 func (s *projectile) Unspawn() error {
 	s.logger.Infof("Closing")
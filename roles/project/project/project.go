@@ -28,6 +28,7 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/timer"
@@ -48,6 +49,7 @@ type project struct {
 	idleWorkers        uint32
 	connections        *connections
 	logger             logger.Logger
+	metrics            metrics.Registry
 	transceiver        transceiver.Requester
 	ticker             ticker.Requester
 	closeSignal        chan struct{}
@@ -71,6 +73,7 @@ func (p *project) persistRequest(
 		projection:              p.endpoint,
 		dialer:                  p.dialer,
 		runner:                  p.runner,
+		metrics:                 p.metrics,
 		buf:                     connData.Buffer,
 		project:                 p,
 		pingTickTimeout:         p.pingTickTimeout,
@@ -107,6 +110,7 @@ func (p *project) request(
 		projection:              p.endpoint,
 		dialer:                  p.dialer,
 		runner:                  p.runner,
+		metrics:                 p.metrics,
 		buf:                     connData.Buffer,
 		project:                 p,
 		pingTickTimeout:         p.pingTickTimeout,
@@ -148,6 +152,8 @@ func (p *project) handle(
 		connection: timer.New(),
 		request:    timer.New(),
 	}
+	dest := transceiver.Destination("Projection:" + strconv.FormatUint(
+		uint64(p.endpoint.ID), 10))
 
 	skipRetrySleep := false
 
@@ -159,7 +165,8 @@ func (p *project) handle(
 		default:
 			ll.Debugf("Serving")
 
-			_, tReqErr := p.transceiver.Request(ll, reqBuilder, nil, m)
+			_, tReqErr := p.transceiver.Request(
+				ll, dest, reqBuilder, nil, m)
 
 			if !retry {
 				if tReqErr == nil {
@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -87,6 +88,7 @@ type projects struct {
 // New creates a new Projects
 func New(
 	log logger.Logger,
+	m metrics.Registry,
 	tClient transceiver.Requester,
 	runner worker.Runner,
 	ticker ticker.Requester,
@@ -131,6 +133,8 @@ func New(
 					registerations[rrIdx].Endpoint.Host,
 					strconv.FormatUint(uint64(
 						registerations[rrIdx].Endpoint.Port), 10))),
+				metrics: m.With(metrics.L("projection", strconv.FormatUint(
+					uint64(registerations[rrIdx].Endpoint.ID), 10))),
 				transceiver: tClient,
 				ticker:      ticker,
 				closeSignal: prjs.closeSignal,
@@ -27,6 +27,7 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
@@ -102,6 +103,7 @@ type requester struct {
 	projection              Endpoint
 	dialer                  network.Dialer
 	runner                  worker.Runner
+	metrics                 metrics.Registry
 	buf                     []byte
 	project                 *project
 	pingTickTimeout         time.Duration
@@ -574,7 +576,7 @@ func (h *requester) wait(f fsm.FSM) error {
 func (h *requester) relayInit(f fsm.FSM) error {
 	h.pendingRelay = false
 
-	relay := relay.New(h.log, h.runner, h.rw, h.buf, &relaying{
+	relay := relay.New(h.log, h.metrics, h.runner, h.rw, h.buf, &relaying{
 		dial:       h.dialer.Dialer(),
 		projection: h.projection,
 		client:     nil,
@@ -21,17 +21,31 @@
 package project
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/print"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/arq"
+	"github.com/reinit/coward/roles/common/network/bond"
 	tcpconn "github.com/reinit/coward/roles/common/network/connection/tcp"
+	arqdial "github.com/reinit/coward/roles/common/network/dialer/arq"
+	bonddial "github.com/reinit/coward/roles/common/network/dialer/bond"
+	hopdial "github.com/reinit/coward/roles/common/network/dialer/hop"
 	"github.com/reinit/coward/roles/common/network/dialer/tcp"
+	tlsdial "github.com/reinit/coward/roles/common/network/dialer/tls"
+	wsdial "github.com/reinit/coward/roles/common/network/dialer/websocket"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/network/websocket"
 	"github.com/reinit/coward/roles/common/transceiver"
 	"github.com/reinit/coward/roles/project/project"
 	"github.com/reinit/coward/roles/projector/projection"
@@ -76,6 +90,10 @@ func (c *ConfigProject) VerifyProtocol() error {
 		return protocolErr
 	}
 
+	if c.selectedProto == network.Unix {
+		return errors.New("Unix protocol is not supported here")
+	}
+
 	return nil
 }
 
@@ -156,20 +174,188 @@ func (c *ConfigProject) Verify() error {
 	return nil
 }
 
+// ConfigTLS TLS configurations
+type ConfigTLS struct {
+	built       *tls.Config
+	ServerName  string   `json:"server_name" cfg:"s,-server-name:Server name that will be sent through SNI and be used to verify the server certificate.\r\n\r\nThe Host name of the COWARD Projector server will be used when this is not specified."`
+	ALPN        []string `json:"alpn" cfg:"a,-alpn:Application protocol names that will be offered through ALPN during the TLS handshake."`
+	CA          string   `json:"ca" cfg:"ca,-ca:Path to a PEM encoded CA certificate file.\r\n\r\nOnce specified, the server certificate will be verified against the CAs in the file instead of the CAs of the system."`
+	Pins        []string `json:"pins" cfg:"pn,-pins:Pinned public keys of the COWARD Projector server, each of them is a Base64 encoded SHA-256 hash of the SubjectPublicKeyInfo of a certificate.\r\n\r\nOnce specified, the connection will be rejected unless the server certificate matchs the pins. The server certificate will only be verified against the CA when \"--ca\" is also specified, so a self-signed certificate can be used on the server. When \"--ca\" is specified, the pins can also match the intermediate or root certificates of the verified chain."`
+	Certificate string   `json:"certificate" cfg:"c,-certificate:Path to a PEM encoded client certificate file that will be presented to the COWARD Projector server."`
+	Key         string   `json:"key" cfg:"k,-key:Path to the PEM encoded private key file of the client certificate."`
+}
+
+// Verify Verify all configrations
+func (c *ConfigTLS) Verify() error {
+	built, buildErr := tlsdial.Config{
+		ServerName:  c.ServerName,
+		ALPN:        c.ALPN,
+		CA:          c.CA,
+		Pins:        c.Pins,
+		Certificate: c.Certificate,
+		Key:         c.Key,
+	}.Build()
+
+	if buildErr != nil {
+		return buildErr
+	}
+
+	c.built = built
+
+	return nil
+}
+
+// ConfigWebSocket WebSocket configurations
+type ConfigWebSocket struct {
+	built   websocket.Config
+	enabled bool
+	Path    string   `json:"path" cfg:"p,-path:The path which the WebSocket handshake request will be sent to. Default: \"/\""`
+	Host    string   `json:"host" cfg:"h,-host:Value of the Host header of the WebSocket handshake request.\r\n\r\nThe Host name and Port of the COWARD Projector server will be used when this is not specified."`
+	Headers []string `json:"headers" cfg:"hd,-headers:Extra headers of the WebSocket handshake request, each of them must be in \"<Name>: <Value>\" format."`
+}
+
+// VerifyPath Verify Path
+func (c *ConfigWebSocket) VerifyPath() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return errors.New("Path must be started with \"/\"")
+	}
+
+	return nil
+}
+
+// Verify Verify all configrations
+func (c *ConfigWebSocket) Verify() error {
+	headers, headersErr := websocket.ParseHeaders(c.Headers)
+
+	if headersErr != nil {
+		return headersErr
+	}
+
+	if c.Path == "" {
+		c.Path = websocket.DefaultPath
+	}
+
+	c.built = websocket.Config{
+		Path:    c.Path,
+		Host:    c.Host,
+		Headers: headers,
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigReliableUDP Reliable UDP configurations
+type ConfigReliableUDP struct {
+	built      arq.Config
+	enabled    bool
+	Window     uint16 `json:"window" cfg:"w,-window:The amount of segments can be sent without being acknowledged, as well as the amount of segments can be buffered for receiving.\r\n\r\nLarger window allows higher throughput on long-haul links at the cost of memory. Default: 128"`
+	RTO        uint16 `json:"rto" cfg:"r,-rto:The minimal retransmission timeout in millisecond.\r\n\r\nSegments that are not acknowledged within the timeout will be retransmitted. Default: 100"`
+	FastResend uint8  `json:"fast_resend" cfg:"f,-fast-resend:Retransmit a segment right away once this amount of segments sent after it has been acknowledged, rather than waiting for it to be timed out. Default: 2"`
+	FEC        uint8  `json:"fec" cfg:"x,-fec:Send a XOR parity datagram after every this amount of datagrams, so any single lost datagram among them can be recovered without retransmission.\r\n\r\nSet to 0 to disable. Must matchs the setting on the other side. Default: 0"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigReliableUDP) Verify() error {
+	if c.Window <= 0 {
+		c.Window = arq.DefaultWindow
+	}
+
+	if c.RTO <= 0 {
+		c.RTO = uint16(arq.DefaultMinRTO / time.Millisecond)
+	}
+
+	if c.FastResend <= 0 {
+		c.FastResend = arq.DefaultFastResend
+	}
+
+	c.built = arq.Config{
+		Window:     c.Window,
+		MinRTO:     time.Duration(c.RTO) * time.Millisecond,
+		FastResend: c.FastResend,
+		FEC:        c.FEC,
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigHop Port hopping configurations
+type ConfigHop struct {
+	enabled bool
+	Ports   uint16 `json:"ports" cfg:"p,-ports:How many consecutive ports starting from Port the COWARD Projector server is listening on.\r\n\r\nMust matchs the \"Hop Ports\" setting on server."`
+	Key     string `json:"key" cfg:"k,-key:A shared key which decides the port to pick for each connection in each time period.\r\n\r\nMust be at least 16 characters long. It is recommended to use a key that is different from the one of the Codec."`
+	Window  uint16 `json:"window" cfg:"w,-window:The length of the time period in second.\r\n\r\nThe picks of the ports will be changed in every time period. Default: 60"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigHop) Verify() error {
+	if c.Ports < 2 {
+		return errors.New("Hop Ports must be greater than 1")
+	}
+
+	if len(c.Key) < 16 {
+		return errors.New(
+			"Hop Key was too short. Make it at least 16 characters long")
+	}
+
+	if c.Window <= 0 {
+		c.Window = uint16(hopdial.DefaultWindow / time.Second)
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigSocket Socket option configurations
+type ConfigSocket struct {
+	built         sockopt.Options
+	KeepAlive     uint16 `json:"keepalive" cfg:"k,-keepalive:Period in second of the TCP keepalive, which is how long a connection must stay idle before the keepalive probes are sent.\r\n\r\nSet to 0 to use the system default."`
+	SendBuffer    uint32 `json:"send_buffer" cfg:"sb,-send-buffer:Size of the send buffer (SO_SNDBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	ReceiveBuffer uint32 `json:"receive_buffer" cfg:"rb,-receive-buffer:Size of the receive buffer (SO_RCVBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	NoDelay       bool   `json:"no_delay" cfg:"n,-no-delay:Send data right away rather than waiting for more data to be combined into one packet (TCP_NODELAY).\r\n\r\nEnable it for interactive sessions such as SSH, keep it disabled for bulk transfers to save bandwidth. Default: no"`
+	Mark          uint32 `json:"mark" cfg:"m,-mark:Mark the sockets with this firewall mark (SO_MARK), so they can be handled by the policy routing rules.\r\n\r\nOnly supported on Linux, and requires the CAP_NET_ADMIN capability. Set to 0 to disable."`
+	FastOpen      bool   `json:"fast_open" cfg:"f,-fast-open:Enable TCP Fast Open, so data can be carried by the handshake packets to save a round trip.\r\n\r\nOnly supported on Linux, and must also be enabled through the \"net.ipv4.tcp_fastopen\" system setting. Default: no"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigSocket) Verify() error {
+	c.built = sockopt.Options{
+		KeepAlive:     time.Duration(c.KeepAlive) * time.Second,
+		SendBuffer:    int(c.SendBuffer),
+		ReceiveBuffer: int(c.ReceiveBuffer),
+		NoDelay:       c.NoDelay,
+		Mark:          c.Mark,
+		FastOpen:      c.FastOpen,
+	}
+
+	return c.built.Verify()
+}
+
 // ConfigInput configurations
 type ConfigInput struct {
 	components     []interface{}
 	selectedCodec  transceiver.Codec
-	Host           string           `json:"host" cfg:"h,-host:Host name of the remote COWARD Projector server.\r\n\r\nMust matchs the setting on server."`
-	Port           uint16           `json:"port" cfg:"p,-port:Registeration port of the remote COWARD Projector server.\r\n\r\nMust matchs the setting on server."`
-	Timeout        uint16           `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of the established connection.\r\n\r\nIf a connection is consecutively idle during this period of time, then that connection will be considered as inactive and thus be disconnected.\r\n\r\nIt is recommended to set this value no greater than the related one on the COWARD Projector server setting."`
-	RequestTimeout uint16           `json:"request_timeout" cfg:"rt,-request-timeout:The maximum wait time in second for the server to respond the Initial request of a client.\r\n\r\nIf the COWARD Projector server has failed to respond the Initial request within this period of time, the connection will be considered broken and thus be closed.\r\n\r\nIt is recommended to set this value slightly greater than the \"--initial-timeout\" setting on the COWARD Projector server."`
-	PingTimeout    uint16           `json:"ping_timeout" cfg:"pt,-ping-timeout:The maximum delay between pings in second.\r\n\r\nWe normally will automatically negotiate the ping delay during registeration, but sometime that negoitated delay maybe too long for actal use.\r\n\r\nWhen that happens, you can overwrite that negoitated delay by set a smaller value use this option."`
-	Channels       uint8            `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection can be use to transport multiple requests (Multiplexing).\r\n\r\nWARNING:\r\nThis value must matchs or smaller than the related setting on the COWARD Projector server, otherwise the request will be come malformed and thus dropped."`
-	Persistent     bool             `json:"persist" cfg:"k,-persist:Whether or not to keep the connection to the COWARD Projector active after all requests on the connection is completed."`
-	Projects       []*ConfigProject `json:"projects" cfg:"s,-projects:Pre-defined project destnations.\r\n\r\nMust be exist on the COWARD Projector server."`
-	Codec          string           `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection."`
-	CodecSetting   []string         `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing."`
+	selectedBond   []net.IP
+	Host           string            `json:"host" cfg:"h,-host:Host name of the remote COWARD Projector server.\r\n\r\nMust matchs the setting on server."`
+	Port           uint16            `json:"port" cfg:"p,-port:Registeration port of the remote COWARD Projector server.\r\n\r\nMust matchs the setting on server."`
+	Timeout        uint16            `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of the established connection.\r\n\r\nIf a connection is consecutively idle during this period of time, then that connection will be considered as inactive and thus be disconnected.\r\n\r\nIt is recommended to set this value no greater than the related one on the COWARD Projector server setting."`
+	RequestTimeout uint16            `json:"request_timeout" cfg:"rt,-request-timeout:The maximum wait time in second for the server to respond the Initial request of a client.\r\n\r\nIf the COWARD Projector server has failed to respond the Initial request within this period of time, the connection will be considered broken and thus be closed.\r\n\r\nIt is recommended to set this value slightly greater than the \"--initial-timeout\" setting on the COWARD Projector server."`
+	PingTimeout    uint16            `json:"ping_timeout" cfg:"pt,-ping-timeout:The maximum delay between pings in second.\r\n\r\nWe normally will automatically negotiate the ping delay during registeration, but sometime that negoitated delay maybe too long for actal use.\r\n\r\nWhen that happens, you can overwrite that negoitated delay by set a smaller value use this option."`
+	Channels       uint8             `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection can be use to transport multiple requests (Multiplexing).\r\n\r\nWARNING:\r\nThis value must matchs or smaller than the related setting on the COWARD Projector server, otherwise the request will be come malformed and thus dropped."`
+	Persistent     bool              `json:"persist" cfg:"k,-persist:Whether or not to keep the connection to the COWARD Projector active after all requests on the connection is completed."`
+	Projects       []*ConfigProject  `json:"projects" cfg:"s,-projects:Pre-defined project destnations.\r\n\r\nMust be exist on the COWARD Projector server."`
+	Codec          string            `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection.\r\n\r\nMultiple Codecs can be chained by separating their names with \",\". Data will be encoded by the Codecs in the listed order before being sent, and decoded in the reverse order."`
+	CodecSetting   []string          `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing.\r\n\r\nWhen Codecs are chained, the setting of each Codec must be placed after a \"[<Codec Name>]\" line."`
+	TLS            ConfigTLS         `json:"tls" cfg:"tl,-tls:Enable and configure the TLS transport.\r\n\r\nOnce enabled, connections to the COWARD Projector server will be established through TLS.\r\n\r\nMust matchs the setting on server."`
+	WebSocket      ConfigWebSocket   `json:"websocket" cfg:"ws,-websocket:Enable and configure the WebSocket transport.\r\n\r\nOnce enabled, connections to the COWARD Projector server will be established through WebSocket, so they can be forwarded by HTTP reverse proxies. When the TLS transport is enabled as well, the WebSocket will be carried by TLS.\r\n\r\nMust matchs the setting on server."`
+	ReliableUDP    ConfigReliableUDP `json:"reliable_udp" cfg:"ru,-reliable-udp:Enable and configure the Reliable UDP transport.\r\n\r\nOnce enabled, connections to the COWARD Projector server will be carried by an ARQ (Automatic Repeat reQuest) protocol over UDP which retransmits lost data more aggressively than TCP does, so the throughput on lossy long-haul links can be much higher.\r\n\r\nCan not be used together with the TLS or WebSocket transport. Must matchs the setting on server."`
+	Bond           []string          `json:"bond" cfg:"bd,-bond:Local IP addresses of the network interfaces to bond.\r\n\r\nOnce specified, every connection to the COWARD Projector server will be established as one or more links, one through each of the listed interfaces. Data of the connection will be striped across all of it's links so their bandwidth is combined, and the connection will survive as long as one of the links is still alive.\r\n\r\nAn interface can be listed multiple times to establish multiple links through it. Set an item to \"0.0.0.0\" (or \"::\" for IPv6) to let the system select the interface.\r\n\r\nAt most 16 links can be bonded. Links are encoded with the Codec, so the Codec setting must be the same as the server.\r\n\r\nCan only be used with the plain TCP transport. Requires Bond to be enabled on server."`
+	Hop            ConfigHop         `json:"hop" cfg:"hp,-hop:Enable and configure port hopping.\r\n\r\nOnce enabled, connections to the COWARD Projector server will be spread across a range of ports starting from Port, and the picks will be changed periodically, so an observer will not see a single fixed destination.\r\n\r\nRequires \"Hop Ports\" to be set on server."`
+	Socket         ConfigSocket      `json:"socket" cfg:"so,-socket:Tune the options of the TCP sockets.\r\n\r\nConnections of the plain TCP (including the bonded ones), TLS and WebSocket transport are affected, connections of the Reliable UDP transport will keep using the default options."`
 }
 
 // GetDescription get descriptions
@@ -241,24 +427,15 @@ func (c *ConfigInput) VerifyChannels() error {
 
 // VerifyCodec Verify Codec
 func (c *ConfigInput) VerifyCodec() error {
-	for cIdx := range c.components {
-		codecBuilder, isCodecBuilder :=
-			c.components[cIdx].(func() transceiver.Codec)
-
-		if !isCodecBuilder {
-			continue
-		}
+	selected, selectErr := transceiver.SelectCodec(c.Codec, c.components)
 
-		codecInfo := codecBuilder()
-
-		if codecInfo.Name == c.Codec {
-			c.selectedCodec = codecInfo
-
-			return nil
-		}
+	if selectErr != nil {
+		return selectErr
 	}
 
-	return errors.New("Specified Codec was not found")
+	c.selectedCodec = selected
+
+	return nil
 }
 
 // VerifyCodecSetting Verify CodecSetting
@@ -316,6 +493,37 @@ func (c *ConfigInput) Verify() error {
 		}
 	}
 
+	if c.ReliableUDP.enabled && (c.TLS.built != nil || c.WebSocket.enabled) {
+		return errors.New("Reliable UDP transport can not be used " +
+			"together with TLS or WebSocket transport")
+	}
+
+	c.selectedBond = make([]net.IP, len(c.Bond))
+
+	for bIdx := range c.Bond {
+		c.selectedBond[bIdx] = net.ParseIP(c.Bond[bIdx])
+
+		if c.selectedBond[bIdx] != nil {
+			continue
+		}
+
+		return errors.New("Invalid Bond interface \"" + c.Bond[bIdx] + "\"")
+	}
+
+	if len(c.selectedBond) > 0 &&
+		(c.ReliableUDP.enabled || c.TLS.built != nil || c.WebSocket.enabled) {
+		return errors.New("Bond can only be used with TCP transport")
+	}
+
+	if len(c.selectedBond) > bond.MaxLinks {
+		return errors.New("Bond can not have more than " +
+			strconv.Itoa(bond.MaxLinks) + " interfaces")
+	}
+
+	if c.Hop.enabled && uint32(c.Port)+uint32(c.Hop.Ports) > 65536 {
+		return errors.New("Hop Ports exceeds the maximum port number")
+	}
+
 	return nil
 }
 
@@ -339,21 +547,137 @@ func Role() role.Registration {
 				Projects:       []*ConfigProject{},
 				Codec:          "",
 				CodecSetting:   nil,
+				TLS: ConfigTLS{
+					built:       nil,
+					ServerName:  "",
+					ALPN:        nil,
+					CA:          "",
+					Pins:        nil,
+					Certificate: "",
+					Key:         "",
+				},
+				WebSocket: ConfigWebSocket{
+					built:   websocket.Config{},
+					enabled: false,
+					Path:    "",
+					Host:    "",
+					Headers: nil,
+				},
+				ReliableUDP: ConfigReliableUDP{
+					built:      arq.Config{},
+					enabled:    false,
+					Window:     0,
+					RTO:        0,
+					FastResend: 0,
+					FEC:        0,
+				},
+				Bond: nil,
+				Hop: ConfigHop{
+					enabled: false,
+					Ports:   0,
+					Key:     "",
+					Window:  0,
+				},
+				Socket: ConfigSocket{
+					built:         sockopt.Options{},
+					KeepAlive:     0,
+					SendBuffer:    0,
+					ReceiveBuffer: 0,
+					NoDelay:       false,
+					Mark:          0,
+					FastOpen:      false,
+				},
 			}
 		},
 		Generater: func(
 			w print.Common,
 			config interface{},
 			log logger.Logger,
+			m metrics.Registry,
+			s session.Sessions,
 		) (role.Role, error) {
 			cfg := config.(*ConfigInput)
 
-			dialer := tcp.New(
-				cfg.Host,
-				cfg.Port,
-				time.Duration(cfg.RequestTimeout)*time.Second,
-				tcpconn.Wrap,
-			)
+			codecBuilder := cfg.selectedCodec.Build(cfg.CodecSetting, log,
+				m.With(metrics.L("role", "project")))
+
+			dialTo := func(port uint16) network.Dialer {
+				switch {
+				case len(cfg.selectedBond) > 0:
+					links := make([]network.Dialer, len(cfg.selectedBond))
+
+					for lIdx := range cfg.selectedBond {
+						links[lIdx] = tcp.NewFrom(
+							cfg.selectedBond[lIdx],
+							cfg.Host,
+							port,
+							time.Duration(cfg.RequestTimeout)*time.Second,
+							cfg.Socket.built,
+							tcpconn.Wrap,
+						)
+					}
+
+					return bonddial.New(
+						links,
+						codecBuilder,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						tcpconn.Wrap)
+
+				case cfg.ReliableUDP.enabled:
+					return arqdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.ReliableUDP.built,
+						tcpconn.Wrap,
+					)
+
+				case cfg.WebSocket.enabled:
+					return wsdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.WebSocket.built,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+
+				case cfg.TLS.built != nil:
+					return tlsdial.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+
+				default:
+					return tcp.New(
+						cfg.Host,
+						port,
+						time.Duration(cfg.RequestTimeout)*time.Second,
+						cfg.Socket.built,
+						tcpconn.Wrap,
+					)
+				}
+			}
+
+			var dialer network.Dialer
+
+			if cfg.Hop.enabled {
+				ports := make([]network.Dialer, cfg.Hop.Ports)
+
+				for pIdx := range ports {
+					ports[pIdx] = dialTo(cfg.Port + uint16(pIdx))
+				}
+
+				dialer = hopdial.New(ports, []byte(cfg.Hop.Key),
+					time.Duration(cfg.Hop.Window)*time.Second)
+			} else {
+				dialer = dialTo(cfg.Port)
+			}
 
 			endpoints := make(Endpoints, len(cfg.Projects))
 
@@ -373,9 +697,11 @@ func Role() role.Registration {
 			}
 
 			return New(
-				cfg.selectedCodec.Build(cfg.CodecSetting),
+				codecBuilder,
 				dialer,
 				log,
+				m,
+				s,
 				Config{
 					TransceiverIdleTimeout: time.Duration(
 						cfg.Timeout) * time.Second,
@@ -386,6 +712,7 @@ func Role() role.Registration {
 					TransceiverChannels:             cfg.Channels,
 					TransceiverConnectionPersistent: cfg.Persistent,
 					Endpoints:                       endpoints,
+					SocketOptions:                   cfg.Socket.built,
 				}), nil
 		},
 	}
@@ -22,16 +22,19 @@ package projector
 
 import (
 	"net"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 	"github.com/reinit/coward/roles/projector/projection"
 )
 
 // Server contains server data
 type Server struct {
 	ID             projection.ID
-	Interface      net.IP
+	Interfaces     []net.IP
 	Port           uint16
 	Timeout        time.Duration
 	RequestTimeout time.Duration
@@ -40,6 +43,18 @@ type Server struct {
 	Retries        uint8
 }
 
+// Addresses returns all the addresses the server will be listening on
+func (s Server) Addresses() string {
+	addrs := make([]string, len(s.Interfaces))
+
+	for iIdx := range s.Interfaces {
+		addrs[iIdx] = net.JoinHostPort(s.Interfaces[iIdx].String(),
+			strconv.FormatUint(uint64(s.Port), 10))
+	}
+
+	return strings.Join(addrs, ", ")
+}
+
 // Config Configuration
 type Config struct {
 	Servers              []Server
@@ -49,6 +64,8 @@ type Config struct {
 	RequestRetries       uint8
 	ConnectionChannels   uint8
 	ChannelDispatchDelay time.Duration
+	Fallback             network.Dialer
+	SocketOptions        sockopt.Options
 }
 
 // GetAllServerRegisterations return projection registeration for all
@@ -22,6 +22,8 @@ package projector
 
 import (
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
@@ -32,6 +34,8 @@ import (
 )
 
 type handler struct {
+	metrics     metrics.Registry
+	sessions    session.Sessions
 	transceiver transceiver.Server
 	runner      worker.Runner
 	projections projection.Projections
@@ -42,6 +46,8 @@ type handler struct {
 type client struct {
 	conn        network.Connection
 	logger      logger.Logger
+	metrics     metrics.Registry
+	sessions    session.Sessions
 	transceiver transceiver.Server
 	runner      worker.Runner
 	projections projection.Projections
@@ -56,6 +62,8 @@ func (d handler) New(
 	return client{
 		conn:        c,
 		logger:      l,
+		metrics:     d.metrics,
+		sessions:    d.sessions,
 		transceiver: d.transceiver,
 		runner:      d.runner,
 		projections: d.projections,
@@ -79,6 +87,8 @@ func (d client) Serve() error {
 			closeNotify,
 			d.runner,
 			d.logger,
+			d.metrics,
+			d.sessions,
 			join.Config{
 				ConnectionID:     d.conn.ID(),
 				ConnectionDelay:  timer.Average(),
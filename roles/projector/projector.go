@@ -23,12 +23,14 @@ package projector
 import (
 	"errors"
 	"math"
-	"net"
 	"strconv"
 	"time"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -60,9 +62,11 @@ const (
 // projector Projector
 type projector struct {
 	logger          logger.Logger
+	metrics         metrics.Registry
+	sessions        session.Sessions
 	codec           transceiver.CodecBuilder
 	cfg             Config
-	listener        network.Listener
+	listeners       []network.Listener
 	unspawnNotifier role.UnspawnNotifier
 	runner          worker.Runner
 	ticker          ticker.RequestCloser
@@ -73,16 +77,20 @@ type projector struct {
 
 // New creates a new Projector
 func New(
-	listener network.Listener,
+	listeners []network.Listener,
 	codec transceiver.CodecBuilder,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) role.Role {
 	return &projector{
 		logger:          log.Context("Projector"),
+		metrics:         m.With(metrics.L("role", "projector")),
+		sessions:        s.With("projector"),
 		codec:           codec,
 		cfg:             cfg,
-		listener:        listener,
+		listeners:       listeners,
 		unspawnNotifier: nil,
 		runner:          nil,
 		ticker:          nil,
@@ -142,48 +150,66 @@ func (s *projector) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 		var serving network.Serving
 		var serveErr error
 
+		projectionMetrics := s.metrics.With(metrics.L("projection",
+			strconv.FormatUint(uint64(s.cfg.Servers[sIdx].ID), 10)))
+
 		pHandler, pErr := s.projections.Handler(s.cfg.Servers[sIdx].ID)
 
 		if pErr != nil {
 			return pErr
 		}
 
+		listenAddr := s.cfg.Servers[sIdx].Addresses()
+
+		inherit.Separate(
+			s.cfg.Servers[sIdx].Interfaces, s.cfg.Servers[sIdx].Port)
+
+		listeners := make(
+			[]network.Listener, len(s.cfg.Servers[sIdx].Interfaces))
+
 		switch s.cfg.Servers[sIdx].Protocol {
 		case network.TCP:
-			serving, serveErr = server.New(tcplistener.New(
-				s.cfg.Servers[sIdx].Interface,
-				s.cfg.Servers[sIdx].Port,
-				tcpconn.Wrap,
-			), pHandler, s.logger.Context("Projection (#"+
-				strconv.FormatUint(uint64(s.cfg.Servers[sIdx].ID), 10)+" "+
-				strconv.FormatInt(int64(sIdx), 10)+
-				") TCP "+net.JoinHostPort(
-				s.cfg.Servers[sIdx].Interface.String(),
-				strconv.FormatUint(uint64(
-					s.cfg.Servers[sIdx].Port), 10))), s.runner, server.Config{
-				MaxConnections:  s.cfg.Servers[sIdx].Capacity,
-				AcceptErrorWait: 300 * time.Millisecond,
-			}).Serve()
+			for iIdx := range listeners {
+				listeners[iIdx] = tcplistener.New(
+					s.cfg.Servers[sIdx].Interfaces[iIdx],
+					s.cfg.Servers[sIdx].Port,
+					s.cfg.SocketOptions,
+					tcpconn.Wrap,
+				)
+			}
+
+			serving, serveErr = server.New(listeners, pHandler,
+				s.logger.Context("Projection (#"+
+					strconv.FormatUint(uint64(s.cfg.Servers[sIdx].ID), 10)+
+					" "+strconv.FormatInt(int64(sIdx), 10)+") TCP "+
+					listenAddr),
+				projectionMetrics, s.sessions, s.runner, server.Config{
+					MaxConnections:  s.cfg.Servers[sIdx].Capacity,
+					AcceptErrorWait: 300 * time.Millisecond,
+				}).Serve()
 
 		case network.UDP:
-			serving, serveErr = server.New(udplistener.New(
-				s.cfg.Servers[sIdx].Interface,
-				s.cfg.Servers[sIdx].Port,
-				s.cfg.Servers[sIdx].RequestTimeout,
-				s.cfg.Servers[sIdx].Capacity,
-				make([]byte, 4096),
-				s.ticker,
-				udpconn.Wrap,
-			), pHandler, s.logger.Context("Projection (#"+
-				strconv.FormatUint(uint64(s.cfg.Servers[sIdx].ID), 10)+" "+
-				strconv.FormatInt(int64(sIdx), 10)+
-				") UDP "+net.JoinHostPort(
-				s.cfg.Servers[sIdx].Interface.String(),
-				strconv.FormatUint(uint64(
-					s.cfg.Servers[sIdx].Port), 10))), s.runner, server.Config{
-				MaxConnections:  s.cfg.Servers[sIdx].Capacity,
-				AcceptErrorWait: 300 * time.Millisecond,
-			}).Serve()
+			for iIdx := range listeners {
+				listeners[iIdx] = udplistener.New(
+					s.cfg.Servers[sIdx].Interfaces[iIdx],
+					s.cfg.Servers[sIdx].Port,
+					s.cfg.Servers[sIdx].RequestTimeout,
+					s.cfg.Servers[sIdx].Capacity,
+					make([]byte, 4096),
+					s.ticker,
+					udpconn.Wrap,
+				)
+			}
+
+			serving, serveErr = server.New(listeners, pHandler,
+				s.logger.Context("Projection (#"+
+					strconv.FormatUint(uint64(s.cfg.Servers[sIdx].ID), 10)+
+					" "+strconv.FormatInt(int64(sIdx), 10)+") UDP "+
+					listenAddr),
+				projectionMetrics, s.sessions, s.runner, server.Config{
+					MaxConnections:  s.cfg.Servers[sIdx].Capacity,
+					AcceptErrorWait: 300 * time.Millisecond,
+				}).Serve()
 
 		default:
 			return ErrUnsupportedNetworkProtocolType
@@ -193,17 +219,14 @@ func (s *projector) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 			s.logger.Errorf("Failed to boot up server for %s Projection %d "+
 				"on \"%s\" due to error: %s",
 				s.cfg.Servers[sIdx].Protocol,
-				s.cfg.Servers[sIdx].ID, net.JoinHostPort(
-					s.cfg.Servers[sIdx].Interface.String(),
-					strconv.FormatUint(uint64(
-						s.cfg.Servers[sIdx].Port), 10)), serveErr)
+				s.cfg.Servers[sIdx].ID, listenAddr, serveErr)
 
 			return serveErr
 		}
 
 		s.logger.Infof("Serving %s Projection %d on \"%s\"",
 			s.cfg.Servers[sIdx].Protocol,
-			s.cfg.Servers[sIdx].ID, serving.Listening())
+			s.cfg.Servers[sIdx].ID, listenAddr)
 
 		s.servers[serverIdx] = serving
 
@@ -216,18 +239,27 @@ func (s *projector) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 		minTimeout = math.MaxUint16
 	}
 
-	server, serveErr := server.New(s.listener, handler{
-		transceiver: tserver.New(s.codec, nil, tserver.Config{
+	var fallback tserver.Fallback
+
+	if s.cfg.Fallback != nil {
+		fallback = tserver.Splice(s.cfg.Fallback, s.cfg.IdleTimeout)
+	}
+
+	server, serveErr := server.New(s.listeners, handler{
+		metrics:  s.metrics,
+		sessions: s.sessions,
+		transceiver: tserver.New(s.codec, nil, s.metrics, tserver.Config{
 			InitialTimeout:       s.cfg.InitialTimeout,
 			IdleTimeout:          s.cfg.IdleTimeout,
 			ConnectionChannels:   s.cfg.ConnectionChannels,
 			ChannelDispatchDelay: s.cfg.ChannelDispatchDelay,
+			Fallback:             fallback,
 		}),
 		runner:      s.runner,
 		projections: s.projections,
 		minTimeout:  uint16(minTimeout),
 		cfg:         s.cfg,
-	}, s.logger, s.runner, server.Config{
+	}, s.logger, s.metrics, s.sessions, s.runner, server.Config{
 		MaxConnections:  s.cfg.Capacity,
 		AcceptErrorWait: 300 * time.Millisecond,
 	}).Serve()
@@ -245,6 +277,34 @@ func (s *projector) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	return nil
 }
 
+// Health returns whether or not the register server and all projection
+// servers are serving
+func (s *projector) Health() error {
+	if s.tserver == nil || len(s.servers) <= 0 {
+		return role.ErrNotListening
+	}
+
+	tErr := s.tserver.Health()
+
+	if tErr != nil {
+		return tErr
+	}
+
+	for sIdx := range s.servers {
+		if s.servers[sIdx] == nil {
+			return role.ErrNotListening
+		}
+
+		hErr := s.servers[sIdx].Health()
+
+		if hErr != nil {
+			return hErr
+		}
+	}
+
+	return nil
+}
+
 // // Unspawn closes current Projector
 func (s *projector) Unspawn() error {
 	s.logger.Infof("Closing")
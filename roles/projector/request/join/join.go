@@ -23,7 +23,9 @@ package join
 import (
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
 	"github.com/reinit/coward/roles/common/network"
@@ -34,6 +36,8 @@ import (
 // join Join request handler
 type join struct {
 	cfg                   Config
+	metrics               metrics.Registry
+	sessions              session.Sessions
 	runner                worker.Runner
 	parentConn            network.Connection
 	parentConnCloseNotify chan struct{}
@@ -47,10 +51,14 @@ func New(
 	parentConnCloseNotify chan struct{},
 	runner worker.Runner,
 	logger logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) command.Command {
 	return join{
 		cfg:                   cfg,
+		metrics:               m,
+		sessions:              s,
 		runner:                runner,
 		parentConn:            parentConn,
 		parentConnCloseNotify: parentConnCloseNotify,
@@ -72,6 +80,8 @@ func (j join) New(rw rw.ReadWriteDepleteDoner, log logger.Logger) fsm.Machine {
 	return &processor{
 		logger:                      log,
 		cfg:                         j.cfg,
+		metrics:                     j.metrics,
+		sessions:                    j.sessions,
 		runner:                      j.runner,
 		parentConn:                  j.parentConn,
 		parentConnCloseNotify:       j.parentConnCloseNotify,
@@ -80,6 +90,7 @@ func (j join) New(rw rw.ReadWriteDepleteDoner, log logger.Logger) fsm.Machine {
 		currentProjectionID:         0,
 		currentReceiveResult:        nil,
 		currentReceiver:             nil,
+		currentSession:              nil,
 		currentReceivedAccessorChan: make(chan projection.Accessor, 1),
 		currentReceivedAccessor:     nil,
 		currentRemotePingTimer:      nil,
@@ -23,10 +23,13 @@ package join
 import (
 	"errors"
 	"io"
+	"strconv"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -96,6 +99,8 @@ const (
 type processor struct {
 	logger                      logger.Logger
 	cfg                         Config
+	metrics                     metrics.Registry
+	sessions                    session.Sessions
 	runner                      worker.Runner
 	parentConn                  network.Connection
 	parentConnCloseNotify       chan struct{}
@@ -104,6 +109,7 @@ type processor struct {
 	currentProjectionID         projection.ID
 	currentReceiveResult        chan error
 	currentReceiver             projection.Receiver
+	currentSession              session.Session
 	currentReceivedAccessorChan chan projection.Accessor
 	currentReceivedAccessor     projection.Accessor
 	currentRemotePingTimer      timer.Stopper
@@ -163,6 +169,15 @@ func (p *processor) Bootup() (fsm.State, error) {
 		return nil, wErr
 	}
 
+	// Killing the Receiver will close the entire parent Connection, as
+	// the Receiver can't be unregistered without notifying the remote
+	p.currentSession = p.sessions.Open(
+		session.Receiver,
+		0,
+		p.parentConn.RemoteAddr().String(),
+		"Projection:"+strconv.FormatUint(uint64(p.currentProjectionID), 10),
+		p.parentConn.Close)
+
 	return p.wait, nil
 }
 
@@ -403,6 +418,8 @@ func (p *processor) wait(f fsm.FSM) error {
 func (p *processor) relayInit(f fsm.FSM) error {
 	p.currentRelay = relay.New(
 		p.logger,
+		p.metrics.With(metrics.L("projection",
+			strconv.FormatUint(uint64(p.currentProjectionID), 10))),
 		p.currentReceivedAccessor.Runner(),
 		p.rw,
 		p.cfg.Buffer,
@@ -490,5 +507,10 @@ func (p *processor) Shutdown() error {
 
 	p.registered.Remove(p.currentProjectionID)
 
+	if p.currentSession != nil {
+		p.currentSession.Close()
+		p.currentSession = nil
+	}
+
 	return nil
 }
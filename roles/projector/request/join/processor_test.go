@@ -25,12 +25,15 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/timer"
 	"github.com/reinit/coward/common/worker"
@@ -176,8 +179,11 @@ func testGetJoin() (*join, *dummyProjection, *dummyProjection, worker.Runner) {
 	}
 
 	return &join{
-		cfg:    cfg,
-		runner: rr,
+		cfg:        cfg,
+		metrics:    metrics.NewDitch(),
+		sessions:   session.NewDitch(),
+		runner:     rr,
+		parentConn: &dummyNetworkConnection{},
 		registered: registerations{
 			projections: proj,
 			receivers: make(
@@ -273,6 +279,10 @@ func (d *dummyNetworkConnection) Close() error {
 	return nil
 }
 
+func (d *dummyNetworkConnection) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}
+}
+
 func TestProccessor(t *testing.T) {
 	j, dp1, dp2, rr := testGetJoin()
 	clientConn := &dummyReadWriteDoner{
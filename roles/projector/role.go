@@ -21,32 +21,48 @@
 package projector
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/reinit/coward/common/inherit"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/print"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/arq"
 	tcpconn "github.com/reinit/coward/roles/common/network/connection/tcp"
+	tcpdial "github.com/reinit/coward/roles/common/network/dialer/tcp"
+	arqlisten "github.com/reinit/coward/roles/common/network/listener/arq"
+	bondlisten "github.com/reinit/coward/roles/common/network/listener/bond"
+	hoplisten "github.com/reinit/coward/roles/common/network/listener/hop"
 	"github.com/reinit/coward/roles/common/network/listener/tcp"
+	tlslisten "github.com/reinit/coward/roles/common/network/listener/tls"
+	wslisten "github.com/reinit/coward/roles/common/network/listener/websocket"
+	"github.com/reinit/coward/roles/common/network/sockopt"
+	"github.com/reinit/coward/roles/common/network/websocket"
 	"github.com/reinit/coward/roles/common/transceiver"
 	"github.com/reinit/coward/roles/projector/projection"
 )
 
 // ConfigProject Configuration of Project
 type ConfigProject struct {
-	selectedProto     network.Protocol
-	selectedInterface net.IP
-	ID                uint8  `json:"id" cfg:"i,-id:Projection ID."`
-	Interface         string `json:"interface" cfg:"a,-interface:Specify the interface which current Projection server will listening to.\r\n\r\nSet this to \"0.0.0.0\" (or \"::\" for IPv6) to make it publicly accessable, or \"127.0.0.1\" to make it local-only."`
-	Port              uint16 `json:"port" cfg:"p,-port:Specify a port for server to listen on.\r\n\r\nNotice you may need special permission in order to listen on a port that higher (smaller in number) than 1024."`
-	Protocol          string `json:"protocol" cfg:"o,-protocol:Specify which network protocol this server using."`
-	Capacity          uint32 `json:"capacity" cfg:"c,-capacity:The maximum connections this server will handle.\r\n\r\nIf amount of connections has reached this limitation, new incoming connections will be dropped."`
-	Retries           uint8  `json:"retries" cfg:"r,-retries:When a request to current Projection has failed, how many times we will going to retry that request before given up."`
+	selectedProto      network.Protocol
+	selectedInterface  net.IP
+	selectedInterfaces []net.IP
+	ID                 uint8    `json:"id" cfg:"i,-id:Projection ID."`
+	Interface          string   `json:"interface" cfg:"a,-interface:Specify the interface which current Projection server will listening to.\r\n\r\nSet this to \"0.0.0.0\" (or \"::\" for IPv6) to make it publicly accessable, or \"127.0.0.1\" to make it local-only."`
+	Interfaces         []string `json:"interfaces" cfg:"as,-interfaces:Additional interfaces which current Projection server will listening to, by specify their IP addresses.\r\n\r\nThe Projection server will listen on the same Port of all of these interfaces as well as the one specified by Interface, and connections accepted from all of them share the same Capacity.\r\n\r\nAn unspecified address such as \"0.0.0.0\" or \"::\" accepts both IPv4 and IPv6 connections, unless both \"0.0.0.0\" and \"::\" are selected, in which case each of them only accepts connections of it's own IP family."`
+	Port               uint16   `json:"port" cfg:"p,-port:Specify a port for server to listen on.\r\n\r\nNotice you may need special permission in order to listen on a port that higher (smaller in number) than 1024."`
+	Protocol           string   `json:"protocol" cfg:"o,-protocol:Specify which network protocol this server using."`
+	Capacity           uint32   `json:"capacity" cfg:"c,-capacity:The maximum connections this server will handle.\r\n\r\nIf amount of connections has reached this limitation, new incoming connections will be dropped."`
+	Retries            uint8    `json:"retries" cfg:"r,-retries:When a request to current Projection has failed, how many times we will going to retry that request before given up."`
 }
 
 // VerifyInterface Verify Interface
@@ -70,6 +86,10 @@ func (c *ConfigProject) VerifyProtocol() error {
 		return protocolErr
 	}
 
+	if c.selectedProto == network.Unix {
+		return errors.New("Unix protocol is not supported here")
+	}
+
 	return nil
 }
 
@@ -113,24 +133,163 @@ func (c *ConfigProject) Verify() error {
 		c.Retries = 1
 	}
 
+	c.selectedInterfaces = make([]net.IP, 1, len(c.Interfaces)+1)
+	c.selectedInterfaces[0] = c.selectedInterface
+
+	for iIdx := range c.Interfaces {
+		selectedIP := net.ParseIP(c.Interfaces[iIdx])
+
+		if selectedIP == nil {
+			return errors.New("Invalid Projection server Interfaces " +
+				"address \"" + c.Interfaces[iIdx] + "\"")
+		}
+
+		c.selectedInterfaces = append(c.selectedInterfaces, selectedIP)
+	}
+
+	return nil
+}
+
+// ConfigTLS TLS configurations
+type ConfigTLS struct {
+	built       *tls.Config
+	Certificate string   `json:"certificate" cfg:"c,-certificate:Path to the PEM encoded certificate file of the Projector Register server."`
+	Key         string   `json:"key" cfg:"k,-key:Path to the PEM encoded private key file of the server certificate."`
+	ClientCA    string   `json:"client_ca" cfg:"ca,-client-ca:Path to a PEM encoded CA certificate file.\r\n\r\nOnce specified, COWARD Project clients must present a certificate which is signed by one of the CAs in the file, otherwise the connection will be rejected."`
+	ALPN        []string `json:"alpn" cfg:"a,-alpn:Application protocol names that will be advertised through ALPN during the TLS handshake."`
+}
+
+// Verify Verify all configrations
+func (c *ConfigTLS) Verify() error {
+	built, buildErr := tlslisten.Config{
+		Certificate: c.Certificate,
+		Key:         c.Key,
+		ClientCA:    c.ClientCA,
+		ALPN:        c.ALPN,
+	}.Build()
+
+	if buildErr != nil {
+		return buildErr
+	}
+
+	c.built = built
+
+	return nil
+}
+
+// ConfigWebSocket WebSocket configurations
+type ConfigWebSocket struct {
+	enabled bool
+	Path    string `json:"path" cfg:"p,-path:The path which the WebSocket handshake request must be sent to.\r\n\r\nHandshake requests that sent to other paths will be rejected. Default: \"/\""`
+}
+
+// VerifyPath Verify Path
+func (c *ConfigWebSocket) VerifyPath() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return errors.New("Path must be started with \"/\"")
+	}
+
 	return nil
 }
 
+// Verify Verify all configrations
+func (c *ConfigWebSocket) Verify() error {
+	if c.Path == "" {
+		c.Path = websocket.DefaultPath
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigReliableUDP Reliable UDP configurations
+type ConfigReliableUDP struct {
+	built      arq.Config
+	enabled    bool
+	Window     uint16 `json:"window" cfg:"w,-window:The amount of segments can be sent without being acknowledged, as well as the amount of segments can be buffered for receiving.\r\n\r\nLarger window allows higher throughput on long-haul links at the cost of memory. Default: 128"`
+	RTO        uint16 `json:"rto" cfg:"r,-rto:The minimal retransmission timeout in millisecond.\r\n\r\nSegments that are not acknowledged within the timeout will be retransmitted. Default: 100"`
+	FastResend uint8  `json:"fast_resend" cfg:"f,-fast-resend:Retransmit a segment right away once this amount of segments sent after it has been acknowledged, rather than waiting for it to be timed out. Default: 2"`
+	FEC        uint8  `json:"fec" cfg:"x,-fec:Send a XOR parity datagram after every this amount of datagrams, so any single lost datagram among them can be recovered without retransmission.\r\n\r\nSet to 0 to disable. Must matchs the setting on the other side. Default: 0"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigReliableUDP) Verify() error {
+	if c.Window <= 0 {
+		c.Window = arq.DefaultWindow
+	}
+
+	if c.RTO <= 0 {
+		c.RTO = uint16(arq.DefaultMinRTO / time.Millisecond)
+	}
+
+	if c.FastResend <= 0 {
+		c.FastResend = arq.DefaultFastResend
+	}
+
+	c.built = arq.Config{
+		Window:     c.Window,
+		MinRTO:     time.Duration(c.RTO) * time.Millisecond,
+		FastResend: c.FastResend,
+		FEC:        c.FEC,
+	}
+
+	c.enabled = true
+
+	return nil
+}
+
+// ConfigSocket Socket option configurations
+type ConfigSocket struct {
+	built         sockopt.Options
+	KeepAlive     uint16 `json:"keepalive" cfg:"k,-keepalive:Period in second of the TCP keepalive, which is how long a connection must stay idle before the keepalive probes are sent.\r\n\r\nSet to 0 to use the system default."`
+	SendBuffer    uint32 `json:"send_buffer" cfg:"sb,-send-buffer:Size of the send buffer (SO_SNDBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	ReceiveBuffer uint32 `json:"receive_buffer" cfg:"rb,-receive-buffer:Size of the receive buffer (SO_RCVBUF) of the socket in bytes.\r\n\r\nSet to 0 to use the system default."`
+	NoDelay       bool   `json:"no_delay" cfg:"n,-no-delay:Send data right away rather than waiting for more data to be combined into one packet (TCP_NODELAY).\r\n\r\nEnable it for interactive sessions such as SSH, keep it disabled for bulk transfers to save bandwidth. Default: no"`
+	Mark          uint32 `json:"mark" cfg:"m,-mark:Mark the sockets with this firewall mark (SO_MARK), so they can be handled by the policy routing rules.\r\n\r\nOnly supported on Linux, and requires the CAP_NET_ADMIN capability. Set to 0 to disable."`
+	FastOpen      bool   `json:"fast_open" cfg:"f,-fast-open:Enable TCP Fast Open, so data can be carried by the handshake packets to save a round trip.\r\n\r\nOnly supported on Linux, and must also be enabled through the \"net.ipv4.tcp_fastopen\" system setting. Default: no"`
+}
+
+// Verify Verify all configrations
+func (c *ConfigSocket) Verify() error {
+	c.built = sockopt.Options{
+		KeepAlive:     time.Duration(c.KeepAlive) * time.Second,
+		SendBuffer:    int(c.SendBuffer),
+		ReceiveBuffer: int(c.ReceiveBuffer),
+		NoDelay:       c.NoDelay,
+		Mark:          c.Mark,
+		FastOpen:      c.FastOpen,
+	}
+
+	return c.built.Verify()
+}
+
 // ConfigInput Config
 type ConfigInput struct {
 	components           []interface{}
 	selectedInterface    net.IP
+	selectedInterfaces   []net.IP
 	selectedCodec        transceiver.Codec
-	Interface            string           `json:"interface" cfg:"i,-interface:Select a network interface for the Projector Register server to listen on by specify the IP address of that interface.\r\n\r\nSet this to \"0.0.0.0\" (or \"::\" for IPv6) to make it publicly accessable, or \"127.0.0.1\" to make it local-only."`
-	Port                 uint16           `json:"port" cfg:"p,-port:Specify a port for the Projector Register server to listen on.\r\n\r\nNotice that on some operating systems, you may not able listen on a \"High Port\" (Usually, that's a port number which smaller than 1025) without root privilege.\r\n\r\nIt's not recommended to run this server with such privilege. So instead, you should get around of this limitation by listen on a lower port (Port number that greater than 1024)."`
-	Timeout              uint16           `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of a COWARD Project client connection.\r\n\r\nIf server consecutively receives no data from a connection during this period of time, then that connection will be considered as inactive and thus be disconnected."`
-	InitialTimeout       uint16           `json:"initial_timeout" cfg:"it,-initial-timeout:The maximum wait time in second for COWARD Project client to finish Initial request (Or first request)\r\n\r\nA well balanced value is required: You need to give clients plenty of time to finish the Initial request (Otherwise they may never be able to connect), and also be able defending against malicious accesses (By time them out) at same time."`
-	Capacity             uint32           `json:"capacity" cfg:"c,-capacity:The maximum connections the Projector register server will handle.\r\n\r\nIf amount of connections has reached this limitation, new incoming connections will be dropped."`
-	Channels             uint8            `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection will be allowed to transport multiple requests (Multiplexing). This is very useful to increase the utility of a stable connection.\r\n\r\nWhen the connection is not stable enough however, too many Connection Channels can reduce overall stabililty."`
-	ChannelDispatchDelay uint16           `json:"channel_dispatch_delay" cfg:"cd,-channel-delay:A delay of time in millisecond in between Connection Channel data dispatch operations.\r\n\r\nThe main propose of this setting is to limit the CPU usage of the Connection Channel data dispatch. However, it can also in part be use to control the server's connection bandwidth (Higher the delay, lower the bandwidth and CPU usage)."`
-	Projects             []*ConfigProject `json:"projects" cfg:"s,-projects:Pre-defined Projection servers"`
-	Codec                string           `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection."`
-	CodecSetting         []string         `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing."`
+	selectedFallbackHost string
+	selectedFallbackPort uint16
+	Interface            string            `json:"interface" cfg:"i,-interface:Select a network interface for the Projector Register server to listen on by specify the IP address of that interface.\r\n\r\nSet this to \"0.0.0.0\" (or \"::\" for IPv6) to make it publicly accessable, or \"127.0.0.1\" to make it local-only."`
+	Interfaces           []string          `json:"interfaces" cfg:"is,-interfaces:Additional network interfaces for the Projector Register server to listen on, by specify their IP addresses.\r\n\r\nThe server will listen on the same Port of all of these interfaces as well as the one selected by Interface, and connections accepted from all of them share the same Capacity.\r\n\r\nAn unspecified address such as \"0.0.0.0\" or \"::\" accepts both IPv4 and IPv6 connections, unless both \"0.0.0.0\" and \"::\" are selected, in which case each of them only accepts connections of it's own IP family."`
+	Port                 uint16            `json:"port" cfg:"p,-port:Specify a port for the Projector Register server to listen on.\r\n\r\nNotice that on some operating systems, you may not able listen on a \"High Port\" (Usually, that's a port number which smaller than 1025) without root privilege.\r\n\r\nIt's not recommended to run this server with such privilege. So instead, you should get around of this limitation by listen on a lower port (Port number that greater than 1024)."`
+	Timeout              uint16            `json:"timeout" cfg:"t,-timeout:The maximum idle time in second of a COWARD Project client connection.\r\n\r\nIf server consecutively receives no data from a connection during this period of time, then that connection will be considered as inactive and thus be disconnected."`
+	InitialTimeout       uint16            `json:"initial_timeout" cfg:"it,-initial-timeout:The maximum wait time in second for COWARD Project client to finish Initial request (Or first request)\r\n\r\nA well balanced value is required: You need to give clients plenty of time to finish the Initial request (Otherwise they may never be able to connect), and also be able defending against malicious accesses (By time them out) at same time."`
+	Capacity             uint32            `json:"capacity" cfg:"c,-capacity:The maximum connections the Projector register server will handle.\r\n\r\nIf amount of connections has reached this limitation, new incoming connections will be dropped."`
+	Channels             uint8             `json:"channels" cfg:"n,-channels:How many requests can be simultaneously opened on a single established connection.\r\n\r\nSet the value greater than 1 so a single connection will be allowed to transport multiple requests (Multiplexing). This is very useful to increase the utility of a stable connection.\r\n\r\nWhen the connection is not stable enough however, too many Connection Channels can reduce overall stabililty."`
+	ChannelDispatchDelay uint16            `json:"channel_dispatch_delay" cfg:"cd,-channel-delay:A delay of time in millisecond in between Connection Channel data dispatch operations.\r\n\r\nThe main propose of this setting is to limit the CPU usage of the Connection Channel data dispatch. However, it can also in part be use to control the server's connection bandwidth (Higher the delay, lower the bandwidth and CPU usage)."`
+	Projects             []*ConfigProject  `json:"projects" cfg:"s,-projects:Pre-defined Projection servers"`
+	Codec                string            `json:"codec" cfg:"e,-codec:Specify which Codec will be used to encode and decode data payload to and from a connection.\r\n\r\nMultiple Codecs can be chained by separating their names with \",\". Data will be encoded by the Codecs in the listed order before being sent, and decoded in the reverse order."`
+	CodecSetting         []string          `json:"codec_setting" cfg:"es,-codec-cfg:Configuration of the Codec as an array of string.\r\n\r\nThe actual configuration format of this setting is depend on the Codec of your choosing.\r\n\r\nWhen Codecs are chained, the setting of each Codec must be placed after a \"[<Codec Name>]\" line."`
+	Fallback             string            `json:"fallback" cfg:"fb,-fallback:Address of a fallback server in \"<Host>:<Port>\" format, for example, a local web server.\r\n\r\nOnce specified, COWARD Project client connections which failed the Codec before sending their first request will be relayed to the fallback server, with the data that has already been received replayed, rather than just being closed. This makes the server looks like an ordinary one to active probers."`
+	TLS                  ConfigTLS         `json:"tls" cfg:"tl,-tls:Enable and configure the TLS transport.\r\n\r\nOnce enabled, all COWARD Project client connections must be established through TLS before the data payload is handled by the Codec."`
+	WebSocket            ConfigWebSocket   `json:"websocket" cfg:"ws,-websocket:Enable and configure the WebSocket transport.\r\n\r\nOnce enabled, all COWARD Project client connections must be established through WebSocket, so they can be forwarded by HTTP reverse proxies. When the TLS transport is enabled as well, the WebSocket will be carried by TLS."`
+	ReliableUDP          ConfigReliableUDP `json:"reliable_udp" cfg:"ru,-reliable-udp:Enable and configure the Reliable UDP transport.\r\n\r\nOnce enabled, server will listen on a UDP port instead of TCP, and COWARD Project client connections will be carried by an ARQ (Automatic Repeat reQuest) protocol which retransmits lost data more aggressively than TCP does, so the throughput on lossy long-haul links can be much higher.\r\n\r\nCan not be used together with the TLS or WebSocket transport."`
+	Bond                 bool              `json:"bond" cfg:"bd,-bond:Accept bonded connections.\r\n\r\nOnce enabled, COWARD Project clients must establish each of their connections through one or more links (for example, one through each of their network interfaces). Data of the connection will be striped across all of it's links so their bandwidth is combined, and the connection will survive as long as one of the links is still alive.\r\n\r\nEvery link is encoded with the Codec, links that failed the Codec will be closed.\r\n\r\nCan not be used together with Fallback."`
+	HopPorts             uint16            `json:"hop_ports" cfg:"hp,-hop-ports:Listen on this amount of consecutive ports starting from Port, so COWARD Project clients can hop between them.\r\n\r\nSome ISPs throttle long-lived connections to a single port over time. Once enabled, COWARD Project clients will spread their connections across the ports and change the picks periodically, so an observer will not see a single fixed destination.\r\n\r\nSet to 0 or 1 to listen on Port only. Must matchs the setting on COWARD Project clients."`
+	Socket               ConfigSocket      `json:"socket" cfg:"so,-socket:Tune the options of the TCP sockets.\r\n\r\nConnections of the plain TCP (including the bonded ones), TLS and WebSocket transport are affected, connections of the Reliable UDP transport will keep using the default options."`
 }
 
 // GetDescription get descriptions
@@ -138,9 +297,8 @@ func (c ConfigInput) GetDescription(fieldPath string) string {
 	result := ""
 
 	switch fieldPath {
-	case "/Interface":
-		fallthrough
-	case "/Projects/Interface":
+	case "/Interface", "/Interfaces", "/Projects/Interface",
+		"/Projects/Interfaces":
 		ifAddrs, ifAddrsErr := net.InterfaceAddrs()
 
 		if ifAddrsErr != nil {
@@ -249,24 +407,36 @@ func (c *ConfigInput) VerifyChannelDispatchDelay() error {
 
 // VerifyCodec Verify Codec
 func (c *ConfigInput) VerifyCodec() error {
-	for cIdx := range c.components {
-		codecBuilder, isCodecBuilder :=
-			c.components[cIdx].(func() transceiver.Codec)
+	selected, selectErr := transceiver.SelectCodec(c.Codec, c.components)
 
-		if !isCodecBuilder {
-			continue
-		}
+	if selectErr != nil {
+		return selectErr
+	}
 
-		codecInfo := codecBuilder()
+	c.selectedCodec = selected
 
-		if codecInfo.Name == c.Codec {
-			c.selectedCodec = codecInfo
+	return nil
+}
 
-			return nil
-		}
+// VerifyFallback Verify Fallback
+func (c *ConfigInput) VerifyFallback() error {
+	host, port, splitErr := net.SplitHostPort(c.Fallback)
+
+	if splitErr != nil {
+		return errors.New(
+			"Fallback must be in \"<Host>:<Port>\" format")
 	}
 
-	return errors.New("Specified Codec was not found")
+	portNum, portErr := strconv.ParseUint(port, 10, 16)
+
+	if portErr != nil || portNum <= 0 {
+		return errors.New("Invalid Fallback port")
+	}
+
+	c.selectedFallbackHost = host
+	c.selectedFallbackPort = uint16(portNum)
+
+	return nil
 }
 
 // VerifyCodecSetting Verify CodecSetting
@@ -284,6 +454,20 @@ func (c *ConfigInput) Verify() error {
 		c.selectedInterface = net.ParseIP("127.0.0.1")
 	}
 
+	c.selectedInterfaces = make([]net.IP, 1, len(c.Interfaces)+1)
+	c.selectedInterfaces[0] = c.selectedInterface
+
+	for iIdx := range c.Interfaces {
+		selectedIP := net.ParseIP(c.Interfaces[iIdx])
+
+		if selectedIP == nil {
+			return errors.New(
+				"Invalid Interfaces address \"" + c.Interfaces[iIdx] + "\"")
+		}
+
+		c.selectedInterfaces = append(c.selectedInterfaces, selectedIP)
+	}
+
 	if c.Timeout <= 0 {
 		return errors.New("Idle Timeout must be specified")
 	}
@@ -320,6 +504,23 @@ func (c *ConfigInput) Verify() error {
 		}
 	}
 
+	if c.ReliableUDP.enabled && (c.TLS.built != nil || c.WebSocket.enabled) {
+		return errors.New("Reliable UDP transport can not be used " +
+			"together with TLS or WebSocket transport")
+	}
+
+	if c.Bond && c.Fallback != "" {
+		return errors.New("Bond can not be used together with Fallback")
+	}
+
+	if c.HopPorts > 1 && c.Port <= 0 {
+		return errors.New("Port must be specified to use Hop Ports")
+	}
+
+	if uint32(c.Port)+uint32(c.HopPorts) > 65536 {
+		return errors.New("Hop Ports exceeds the maximum port number")
+	}
+
 	return nil
 }
 
@@ -333,6 +534,7 @@ func Role() role.Registration {
 			return &ConfigInput{
 				components:           components,
 				Interface:            "",
+				Interfaces:           nil,
 				Port:                 0,
 				Timeout:              0,
 				InitialTimeout:       0,
@@ -342,37 +544,146 @@ func Role() role.Registration {
 				Projects:             []*ConfigProject{},
 				Codec:                "",
 				CodecSetting:         nil,
+				Fallback:             "",
+				TLS: ConfigTLS{
+					built:       nil,
+					Certificate: "",
+					Key:         "",
+					ClientCA:    "",
+					ALPN:        nil,
+				},
+				WebSocket: ConfigWebSocket{
+					enabled: false,
+					Path:    "",
+				},
+				ReliableUDP: ConfigReliableUDP{
+					built:      arq.Config{},
+					enabled:    false,
+					Window:     0,
+					RTO:        0,
+					FastResend: 0,
+					FEC:        0,
+				},
+				Bond:     false,
+				HopPorts: 0,
+				Socket: ConfigSocket{
+					built:         sockopt.Options{},
+					KeepAlive:     0,
+					SendBuffer:    0,
+					ReceiveBuffer: 0,
+					NoDelay:       false,
+					Mark:          0,
+					FastOpen:      false,
+				},
 			}
 		},
 		Generater: func(
 			w print.Common,
 			config interface{},
 			log logger.Logger,
+			m metrics.Registry,
+			s session.Sessions,
 		) (role.Role, error) {
 			cfg := config.(*ConfigInput)
 
-			listen := tcp.New(
-				cfg.selectedInterface,
-				cfg.Port,
-				tcpconn.Wrap)
+			var fallback network.Dialer
+
+			if cfg.Fallback != "" {
+				fallback = tcpdial.New(
+					cfg.selectedFallbackHost,
+					cfg.selectedFallbackPort,
+					time.Duration(cfg.InitialTimeout)*time.Second,
+					cfg.Socket.built,
+					tcpconn.Wrap,
+				)
+			}
+
+			listenOn := func(ip net.IP, port uint16) network.Listener {
+				inherit.Separate(cfg.selectedInterfaces, port)
+
+				switch {
+				case cfg.ReliableUDP.enabled:
+					return arqlisten.New(
+						ip,
+						port,
+						cfg.Capacity,
+						cfg.ReliableUDP.built,
+						tcpconn.Wrap)
+
+				case cfg.WebSocket.enabled:
+					return wslisten.New(
+						ip,
+						port,
+						cfg.WebSocket.Path,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap)
+
+				case cfg.TLS.built != nil:
+					return tlslisten.New(
+						ip,
+						port,
+						cfg.TLS.built,
+						cfg.Socket.built,
+						tcpconn.Wrap)
+
+				default:
+					return tcp.New(
+						ip,
+						port,
+						cfg.Socket.built,
+						tcpconn.Wrap)
+				}
+			}
+
+			codecBuilder := cfg.selectedCodec.Build(cfg.CodecSetting, log,
+				m.With(metrics.L("role", "projector")))
+
+			listens := make([]network.Listener, len(cfg.selectedInterfaces))
+
+			for iIdx := range listens {
+				ip := cfg.selectedInterfaces[iIdx]
+
+				if cfg.HopPorts > 1 {
+					ports := make([]network.Listener, cfg.HopPorts)
+
+					for pIdx := range ports {
+						ports[pIdx] = listenOn(ip, cfg.Port+uint16(pIdx))
+					}
+
+					listens[iIdx] = hoplisten.New(ports)
+				} else {
+					listens[iIdx] = listenOn(ip, cfg.Port)
+				}
+
+				if cfg.Bond {
+					listens[iIdx] = bondlisten.New(
+						listens[iIdx],
+						codecBuilder,
+						time.Duration(cfg.InitialTimeout)*time.Second,
+						tcpconn.Wrap)
+				}
+			}
 
 			projects := make([]Server, len(cfg.Projects))
 
 			for mIdx := range cfg.Projects {
 				projects[mIdx] = Server{
-					ID:        projection.ID(cfg.Projects[mIdx].ID),
-					Interface: cfg.Projects[mIdx].selectedInterface,
-					Port:      cfg.Projects[mIdx].Port,
-					Protocol:  cfg.Projects[mIdx].selectedProto,
-					Capacity:  cfg.Projects[mIdx].Capacity,
-					Retries:   cfg.Projects[mIdx].Retries,
+					ID:         projection.ID(cfg.Projects[mIdx].ID),
+					Interfaces: cfg.Projects[mIdx].selectedInterfaces,
+					Port:       cfg.Projects[mIdx].Port,
+					Protocol:   cfg.Projects[mIdx].selectedProto,
+					Capacity:   cfg.Projects[mIdx].Capacity,
+					Retries:    cfg.Projects[mIdx].Retries,
 				}
 			}
 
 			return New(
-				listen,
-				cfg.selectedCodec.Build(cfg.CodecSetting),
+				listens,
+				codecBuilder,
 				log,
+				m,
+				s,
 				Config{
 					Servers:  projects,
 					Capacity: cfg.Capacity,
@@ -383,6 +694,8 @@ func Role() role.Registration {
 					ConnectionChannels: cfg.Channels,
 					ChannelDispatchDelay: time.Duration(
 						cfg.ChannelDispatchDelay) * time.Millisecond,
+					Fallback:      fallback,
+					SocketOptions: cfg.Socket.built,
 				}), nil
 		},
 	}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/reinit/coward/roles/common/network"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 )
 
 // Mapped Mapping destinations
@@ -41,5 +42,7 @@ type Config struct {
 	IdleTimeout          time.Duration
 	ConnectionChannels   uint8
 	ChannelDispatchDelay time.Duration
+	Fallback             network.Dialer
 	Mapping              []Mapped
+	SocketOptions        sockopt.Options
 }
@@ -22,6 +22,7 @@ package proxy
 
 import (
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
 	"github.com/reinit/coward/roles/common/network"
@@ -31,6 +32,7 @@ import (
 )
 
 type handler struct {
+	metrics     metrics.Registry
 	transceiver transceiver.Server
 	runner      worker.Runner
 	mapping     common.Mapping
@@ -40,6 +42,7 @@ type handler struct {
 type client struct {
 	conn        network.Connection
 	logger      logger.Logger
+	metrics     metrics.Registry
 	mapping     common.Mapping
 	transceiver transceiver.Server
 	runner      worker.Runner
@@ -53,6 +56,7 @@ func (d handler) New(
 	return client{
 		conn:        c,
 		logger:      l,
+		metrics:     d.metrics,
 		mapping:     d.mapping,
 		transceiver: d.transceiver,
 		runner:      d.runner,
@@ -67,54 +71,65 @@ func (d client) Serve() error {
 		d.logger,
 		d.conn,
 		command.New(
+			request.Ping{},
 			request.TCPIPv4{
 				TCP: request.TCP{
+					Metrics:           d.metrics,
 					Runner:            d.runner,
 					Buffer:            buf[:],
 					DialTimeout:       d.cfg.InitialTimeout,
 					ConnectionTimeout: d.cfg.IdleTimeout,
 					Cancel:            d.conn.Closed(),
 					NoLocalAccess:     true,
+					SocketOptions:     d.cfg.SocketOptions,
 				},
 			},
 			request.TCPIPv6{
 				TCP: request.TCP{
+					Metrics:           d.metrics,
 					Runner:            d.runner,
 					Buffer:            buf[:],
 					DialTimeout:       d.cfg.InitialTimeout,
 					ConnectionTimeout: d.cfg.IdleTimeout,
 					Cancel:            d.conn.Closed(),
 					NoLocalAccess:     true,
+					SocketOptions:     d.cfg.SocketOptions,
 				},
 			},
 			request.TCPHost{
 				TCP: request.TCP{
+					Metrics:           d.metrics,
 					Runner:            d.runner,
 					Buffer:            buf[:],
 					DialTimeout:       d.cfg.InitialTimeout,
 					ConnectionTimeout: d.cfg.IdleTimeout,
 					Cancel:            d.conn.Closed(),
 					NoLocalAccess:     true,
+					SocketOptions:     d.cfg.SocketOptions,
 				},
 			},
 			request.TCPMapping{
 				TCP: request.TCP{
+					Metrics:           d.metrics,
 					Runner:            d.runner,
 					Buffer:            buf[:],
 					DialTimeout:       d.cfg.InitialTimeout,
 					ConnectionTimeout: d.cfg.IdleTimeout,
 					Cancel:            d.conn.Closed(),
 					NoLocalAccess:     false,
+					SocketOptions:     d.cfg.SocketOptions,
 				},
 				Mapping: d.mapping,
 			},
 			request.UDP{
+				Metrics:   d.metrics,
 				Runner:    d.runner,
 				Buffer:    buf[:],
 				Cancel:    d.conn.Closed(),
 				LocalAddr: d.conn.LocalAddr(),
 			},
 			request.UDPMapping{
+				Metrics:     d.metrics,
 				Runner:      d.runner,
 				Buffer:      buf[:],
 				Cancel:      d.conn.Closed(),
@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -40,9 +42,11 @@ const (
 )
 
 type proxy struct {
-	listener        network.Listener
+	listeners       []network.Listener
 	cfg             Config
 	logger          logger.Logger
+	metrics         metrics.Registry
+	sessions        session.Sessions
 	codec           transceiver.CodecBuilder
 	mapping         common.Mapping
 	serving         network.Serving
@@ -54,16 +58,20 @@ type proxy struct {
 // New creates a new proxy
 func New(
 	codec transceiver.CodecBuilder,
-	l network.Listener,
+	l []network.Listener,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) role.Role {
 	proxyLog := log.Context("Proxy")
 
 	return &proxy{
-		listener:        l,
+		listeners:       l,
 		cfg:             cfg,
 		logger:          proxyLog,
+		metrics:         m.With(metrics.L("role", "proxy")),
+		sessions:        s.With("proxy"),
 		codec:           codec,
 		mapping:         common.Mapping{},
 		serving:         nil,
@@ -109,17 +117,25 @@ func (s *proxy) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 
 	s.runner = runner
 
-	server, serveErr := server.New(s.listener, handler{
-		transceiver: tserver.New(s.codec, nil, tserver.Config{
+	var fallback tserver.Fallback
+
+	if s.cfg.Fallback != nil {
+		fallback = tserver.Splice(s.cfg.Fallback, s.cfg.IdleTimeout)
+	}
+
+	server, serveErr := server.New(s.listeners, handler{
+		metrics: s.metrics,
+		transceiver: tserver.New(s.codec, nil, s.metrics, tserver.Config{
 			InitialTimeout:       s.cfg.InitialTimeout,
 			IdleTimeout:          s.cfg.IdleTimeout,
 			ConnectionChannels:   s.cfg.ConnectionChannels,
 			ChannelDispatchDelay: s.cfg.ChannelDispatchDelay,
+			Fallback:             fallback,
 		}),
 		runner:  s.runner,
 		mapping: s.mapping,
 		cfg:     s.cfg,
-	}, s.logger, s.runner, server.Config{
+	}, s.logger, s.metrics, s.sessions, s.runner, server.Config{
 		AcceptErrorWait: 300 * time.Millisecond,
 		MaxConnections:  s.cfg.Capacity,
 	}).Serve()
@@ -137,6 +153,15 @@ func (s *proxy) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	return nil
 }
 
+// Health returns whether or not the server is serving
+func (s *proxy) Health() error {
+	if s.serving == nil {
+		return role.ErrNotListening
+	}
+
+	return s.serving.Health()
+}
+
 func (s *proxy) Unspawn() error {
 	s.logger.Infof("Closing")
 
@@ -22,6 +22,7 @@ package request
 
 // Request ID
 const (
+	PingCommand         = 0x01
 	TCPCommandIPv4      = 0x10
 	TCPCommandIPv6      = 0x11
 	TCPCommandHost      = 0x12
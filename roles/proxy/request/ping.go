@@ -0,0 +1,106 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package request
+
+import (
+	"io"
+
+	"github.com/reinit/coward/common/fsm"
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/command"
+)
+
+// Respond ID
+const (
+	PingRespondPong = 0x00
+)
+
+// Ping request, used by clients to probe the health of the server
+type Ping struct{}
+
+type ping struct {
+	logger logger.Logger
+	rw     rw.ReadWriteDepleteDoner
+}
+
+// ID returns the request ID
+func (c Ping) ID() command.ID {
+	return PingCommand
+}
+
+// New creates a new context
+func (c Ping) New(rw rw.ReadWriteDepleteDoner, l logger.Logger) fsm.Machine {
+	return &ping{
+		logger: l,
+		rw:     rw,
+	}
+}
+
+func (c *ping) Bootup() (fsm.State, error) {
+	// Ping Request Format
+	// +-----+
+	// | CMD |
+	// +-----+
+	// |  1  |
+	// +-----+
+	//
+	// Both the request and the respond carry no data, the client will
+	// acknowledge the Pong by sending a single byte back so we know the
+	// channel is free to use again
+	dErr := c.rw.Done()
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	_, wErr := rw.WriteFull(c.rw, []byte{PingRespondPong})
+
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	return c.acknowledge, nil
+}
+
+func (c *ping) acknowledge(f fsm.FSM) error {
+	ackBuf := [1]byte{}
+
+	_, rErr := io.ReadFull(c.rw, ackBuf[:])
+
+	if rErr != nil {
+		c.rw.Done()
+
+		return rErr
+	}
+
+	dErr := c.rw.Done()
+
+	if dErr != nil {
+		return dErr
+	}
+
+	return f.Shutdown()
+}
+
+func (c *ping) Shutdown() error {
+	return nil
+}
@@ -26,9 +26,11 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
+	"github.com/reinit/coward/roles/common/network/sockopt"
 	"github.com/reinit/coward/roles/common/relay"
 )
 
@@ -54,22 +56,26 @@ const (
 // TCP request
 type TCP struct {
 	Logger            logger.Logger
+	Metrics           metrics.Registry
 	Runner            worker.Runner
 	Buffer            []byte
 	DialTimeout       time.Duration
 	ConnectionTimeout time.Duration
 	Cancel            <-chan struct{}
 	NoLocalAccess     bool
+	SocketOptions     sockopt.Options
 }
 
 type tcp struct {
 	logger            logger.Logger
+	metrics           metrics.Registry
 	buf               []byte
 	dialTimeout       time.Duration
 	connectionTimeout time.Duration
 	runner            worker.Runner
 	cancel            <-chan struct{}
 	noLocalAccess     bool
+	socketOptions     sockopt.Options
 	rw                rw.ReadWriteDepleteDoner
 	relay             relay.Relay
 }
@@ -63,9 +63,11 @@ func (c TCPHost) New(rw rw.ReadWriteDepleteDoner, l logger.Logger) fsm.Machine {
 			buf:               c.Buffer,
 			dialTimeout:       c.DialTimeout,
 			connectionTimeout: c.ConnectionTimeout,
+			metrics:           c.Metrics,
 			runner:            c.Runner,
 			cancel:            c.Cancel,
 			noLocalAccess:     c.NoLocalAccess,
+			socketOptions:     c.SocketOptions,
 			rw:                rw,
 			relay:             nil,
 		},
@@ -112,12 +114,12 @@ func (c *tcpHost) Bootup() (fsm.State, error) {
 		timeout = c.dialTimeout
 	}
 
-	c.relay = relay.New(c.logger, c.runner, c.rw, c.buf, tcpRelay{
+	c.relay = relay.New(c.logger, c.metrics, c.runner, c.rw, c.buf, tcpRelay{
 		noLocalAccess:     c.noLocalAccess,
 		dialTimeout:       c.dialTimeout,
 		connectionTimeout: c.connectionTimeout,
-		dial: tcpdial.New(
-			string(host), port, timeout, tcpconn.Wrap).Dialer(),
+		dial: tcpdial.New(string(host), port, timeout,
+			c.socketOptions, tcpconn.Wrap).Dialer(),
 	}, make([]byte, 4096))
 
 	bootErr := c.relay.Bootup(c.cancel)
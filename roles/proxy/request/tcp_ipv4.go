@@ -56,9 +56,11 @@ func (c TCPIPv4) New(rw rw.ReadWriteDepleteDoner, l logger.Logger) fsm.Machine {
 			buf:               c.Buffer,
 			dialTimeout:       c.DialTimeout,
 			connectionTimeout: c.ConnectionTimeout,
+			metrics:           c.Metrics,
 			runner:            c.Runner,
 			cancel:            c.Cancel,
 			noLocalAccess:     c.NoLocalAccess,
+			socketOptions:     c.SocketOptions,
 			rw:                rw,
 			relay:             nil,
 		},
@@ -95,12 +97,12 @@ func (c *tcpIPv4) Bootup() (fsm.State, error) {
 		timeout = c.dialTimeout
 	}
 
-	c.relay = relay.New(c.logger, c.runner, c.rw, c.buf, tcpRelay{
+	c.relay = relay.New(c.logger, c.metrics, c.runner, c.rw, c.buf, tcpRelay{
 		noLocalAccess:     c.noLocalAccess,
 		dialTimeout:       c.dialTimeout,
 		connectionTimeout: c.connectionTimeout,
-		dial: tcpdial.New(
-			ipv4.String(), port, timeout, tcpconn.Wrap).Dialer(),
+		dial: tcpdial.New(ipv4.String(), port, timeout,
+			c.socketOptions, tcpconn.Wrap).Dialer(),
 	}, make([]byte, 4096))
 
 	bootErr := c.relay.Bootup(c.cancel)
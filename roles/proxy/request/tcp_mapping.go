@@ -23,14 +23,17 @@ package request
 import (
 	"errors"
 	"io"
+	"strconv"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/roles/common/command"
 	"github.com/reinit/coward/roles/common/network"
 	tcpconn "github.com/reinit/coward/roles/common/network/connection/tcp"
 	tcpdial "github.com/reinit/coward/roles/common/network/dialer/tcp"
+	unixdial "github.com/reinit/coward/roles/common/network/dialer/unix"
 	"github.com/reinit/coward/roles/common/relay"
 	"github.com/reinit/coward/roles/proxy/common"
 )
@@ -68,9 +71,11 @@ func (c TCPMapping) New(
 			buf:               c.Buffer,
 			dialTimeout:       c.DialTimeout,
 			connectionTimeout: c.ConnectionTimeout,
+			metrics:           c.Metrics,
 			runner:            c.Runner,
 			cancel:            c.Cancel,
 			noLocalAccess:     c.NoLocalAccess,
+			socketOptions:     c.SocketOptions,
 			rw:                rw,
 			relay:             nil,
 		},
@@ -89,6 +94,8 @@ func (c *tcpMapping) Bootup() (fsm.State, error) {
 
 	c.rw.Done()
 
+	mappingMetrics := c.metrics.With(
+		metrics.L("mapping", strconv.Itoa(int(c.buf[0]))))
 	mapped, mappedErr := c.mapping.Get(common.MapID(c.buf[0]))
 
 	if mappedErr != nil {
@@ -97,19 +104,29 @@ func (c *tcpMapping) Bootup() (fsm.State, error) {
 		return nil, mappedErr
 	}
 
-	if mapped.Protocol != network.TCP {
+	var dialer network.Dialer
+
+	switch mapped.Protocol {
+	case network.TCP:
+		dialer = tcpdial.New(mapped.Host, mapped.Port, c.dialTimeout,
+			c.socketOptions, tcpconn.Wrap)
+
+	case network.Unix:
+		dialer = unixdial.New(mapped.Host, c.dialTimeout, tcpconn.Wrap)
+
+	default:
 		rw.WriteFull(c.rw, []byte{TCPRespondMappingNotFound})
 
 		return nil, ErrTCPMappingNotFound
 	}
 
-	c.relay = relay.New(c.logger, c.runner, c.rw, c.buf, tcpRelay{
-		noLocalAccess:     c.noLocalAccess,
-		dialTimeout:       c.dialTimeout,
-		connectionTimeout: c.connectionTimeout,
-		dial: tcpdial.New(
-			mapped.Host, mapped.Port, c.dialTimeout, tcpconn.Wrap).Dialer(),
-	}, make([]byte, 4096))
+	c.relay = relay.New(c.logger, mappingMetrics, c.runner, c.rw, c.buf,
+		tcpRelay{
+			noLocalAccess:     c.noLocalAccess,
+			dialTimeout:       c.dialTimeout,
+			connectionTimeout: c.connectionTimeout,
+			dial:              dialer.Dialer(),
+		}, make([]byte, 4096))
 
 	bootErr := c.relay.Bootup(c.cancel)
 
@@ -60,12 +60,13 @@ func (c tcpRelay) Client(
 		return nil, remoteDialErr
 	}
 
-	remoteAddr := remoteConn.RemoteAddr().(*net.TCPAddr)
+	// Destinations that are not TCP (unix domain sockets) are always local
+	remoteAddr, isTCP := remoteConn.RemoteAddr().(*net.TCPAddr)
 
-	if c.noLocalAccess &&
-		(remoteAddr.IP.IsLoopback() ||
-			remoteAddr.IP.IsUnspecified() ||
-			remoteAddr.IP.IsMulticast()) {
+	if c.noLocalAccess && (!isTCP ||
+		remoteAddr.IP.IsLoopback() ||
+		remoteAddr.IP.IsUnspecified() ||
+		remoteAddr.IP.IsMulticast()) {
 		remoteConn.Close()
 
 		_, wErr := rw.WriteFull(server, []byte{TCPRespondAccessDeined})
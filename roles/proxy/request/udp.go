@@ -26,6 +26,7 @@ import (
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
@@ -63,6 +64,7 @@ var (
 
 // UDP Request
 type UDP struct {
+	Metrics   metrics.Registry
 	Runner    worker.Runner
 	Buffer    []byte
 	Cancel    <-chan struct{}
@@ -88,7 +90,7 @@ func (c UDP) New(rw rw.ReadWriteDepleteDoner, log logger.Logger) fsm.Machine {
 		runner: c.Runner,
 		cancel: c.Cancel,
 		rw:     rw,
-		relay: relay.New(log, c.Runner, rw, c.Buffer, &udpRelay{
+		relay: relay.New(log, c.Metrics, c.Runner, rw, c.Buffer, &udpRelay{
 			localAddr: c.LocalAddr,
 			listenIP:  nil,
 		}, make([]byte, 4096)),
@@ -24,10 +24,12 @@ import (
 	"errors"
 	"io"
 	"net"
+	"strconv"
 	"time"
 
 	"github.com/reinit/coward/common/fsm"
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/rw"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/command"
@@ -44,6 +46,7 @@ var (
 
 // UDPMapping UDP Mapping request
 type UDPMapping struct {
+	Metrics     metrics.Registry
 	Runner      worker.Runner
 	Buffer      []byte
 	Cancel      <-chan struct{}
@@ -54,6 +57,7 @@ type UDPMapping struct {
 
 type udpMapping struct {
 	logger      logger.Logger
+	metrics     metrics.Registry
 	mapping     common.Mapping
 	buf         []byte
 	localAddr   net.Addr
@@ -74,6 +78,7 @@ func (c UDPMapping) New(
 	rw rw.ReadWriteDepleteDoner, log logger.Logger) fsm.Machine {
 	return &udpMapping{
 		logger:      log,
+		metrics:     c.Metrics,
 		mapping:     c.Mapping,
 		buf:         c.Buffer,
 		localAddr:   c.LocalAddr,
@@ -96,6 +101,8 @@ func (u *udpMapping) Bootup() (fsm.State, error) {
 
 	u.rw.Done()
 
+	mappingMetrics := u.metrics.With(
+		metrics.L("mapping", strconv.Itoa(int(u.buf[0]))))
 	mapped, mappedErr := u.mapping.Get(common.MapID(u.buf[0]))
 
 	if mappedErr != nil {
@@ -110,12 +117,13 @@ func (u *udpMapping) Bootup() (fsm.State, error) {
 		return nil, ErrUDPMappingNotFound
 	}
 
-	u.relay = relay.New(u.logger, u.runner, u.rw, u.buf, &udpMappingRelay{
-		localAddr:      u.localAddr,
-		resolveTimeout: u.dialTimeout,
-		mapped:         mapped,
-		listenIP:       nil,
-	}, make([]byte, 4096))
+	u.relay = relay.New(u.logger, mappingMetrics, u.runner, u.rw, u.buf,
+		&udpMappingRelay{
+			localAddr:      u.localAddr,
+			resolveTimeout: u.dialTimeout,
+			mapped:         mapped,
+			listenIP:       nil,
+		}, make([]byte, 4096))
 
 	bootupErr := u.relay.Bootup(u.cancel)
 
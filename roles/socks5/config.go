@@ -20,7 +20,12 @@
 
 package socks5
 
-import "time"
+import (
+	"time"
+
+	"github.com/reinit/coward/roles/common/transceiver/clients"
+	"github.com/reinit/coward/roles/socks5/common"
+)
 
 // Config Socks5 configuration
 type Config struct {
@@ -29,4 +34,11 @@ type Config struct {
 	ConnectionTimeout     time.Duration
 	MaxDestinationRecords int
 	Authenticator         Authenticator
+	Strategy              clients.Strategy
+	Weights               []uint32
+	ClientMetrics         common.ClientMetrics
+	HealthCheckInterval   time.Duration
+	BreakerThreshold      uint32
+	BreakerTimeout        time.Duration
+	BreakerTrials         uint32
 }
@@ -171,6 +171,7 @@ func (n *negotiator) Build() (
 				n.selectedAddress,
 				n.runner,
 				n.shb,
+				n.cfg.ClientMetrics,
 				n.cfg.NegotiationTimeout), nil
 
 	case cmdUDP:
@@ -181,6 +182,7 @@ func (n *negotiator) Build() (
 				n.selectedAddress,
 				n.runner,
 				n.shb,
+				n.cfg.ClientMetrics,
 				n.cfg.NegotiationTimeout), nil
 
 	default:
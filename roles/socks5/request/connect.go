@@ -73,6 +73,7 @@ func Connect(
 	addr common.Address,
 	runner worker.Runner,
 	shb *common.SharedBuffers,
+	cms common.ClientMetrics,
 	requestTimeout time.Duration,
 ) transceiver.BalancedRequestBuilder {
 	return func(
@@ -85,7 +86,8 @@ func Connect(
 		return connect{
 			log: log,
 			relay: relay.New(
-				log, runner, conn, shb.For(cID).Select(id), connectRelay{
+				log, cms.For(cID), runner, conn, shb.For(cID).Select(id),
+				connectRelay{
 					client:         client,
 					addr:           addr,
 					requestTimeout: requestTimeout,
@@ -0,0 +1,104 @@
+//  Crypto-Obscured Forwarder
+//
+//  Copyright (C) 2018 Rui NI <ranqus@gmail.com>
+//
+//  This file is part of Crypto-Obscured Forwarder.
+//
+//  Crypto-Obscured Forwarder is free software: you can redistribute it
+//  and/or modify it under the terms of the GNU General Public License
+//  as published by the Free Software Foundation, either version 3 of
+//  the License, or (at your option) any later version.
+//
+//  Crypto-Obscured Forwarder is distributed in the hope that it will be
+//  useful, but WITHOUT ANY WARRANTY; without even the implied warranty
+//  of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+//  GNU General Public License for more details.
+//
+//  You should have received a copy of the GNU General Public License
+//  along with Crypto-Obscured Forwarder. If not, see
+//  <http://www.gnu.org/licenses/>.
+
+package request
+
+import (
+	"errors"
+	"io"
+
+	"github.com/reinit/coward/common/fsm"
+	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/rw"
+	"github.com/reinit/coward/roles/common/transceiver"
+	"github.com/reinit/coward/roles/proxy/request"
+)
+
+// Errors
+var (
+	ErrPingInvalidRespond = errors.New(
+		"Invalid Ping respond")
+)
+
+type ping struct {
+	log    logger.Logger
+	server rw.ReadWriteDepleteDoner
+}
+
+// Ping returns a Ping request builder which can be used to probe the
+// health of a COWARD Proxy server
+func Ping() transceiver.RequestBuilder {
+	return func(
+		id transceiver.ConnectionID,
+		conn rw.ReadWriteDepleteDoner,
+		connCtl transceiver.ConnectionControl,
+		log logger.Logger,
+	) fsm.Machine {
+		return ping{
+			log:    log,
+			server: conn,
+		}
+	}
+}
+
+func (p ping) Bootup() (fsm.State, error) {
+	_, wErr := rw.WriteFull(p.server, []byte{request.PingCommand})
+
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	pong := [1]byte{}
+
+	_, rErr := io.ReadFull(p.server, pong[:])
+
+	if rErr != nil {
+		p.server.Done()
+
+		return nil, rErr
+	}
+
+	dErr := p.server.Done()
+
+	if dErr != nil {
+		return nil, dErr
+	}
+
+	if pong[0] != request.PingRespondPong {
+		return nil, ErrPingInvalidRespond
+	}
+
+	// Acknowledge the Pong so the remote can release the Channel
+	_, wErr = rw.WriteFull(p.server, []byte{request.PingRespondPong})
+
+	if wErr != nil {
+		return nil, wErr
+	}
+
+	return p.tick, nil
+}
+
+func (p ping) tick(f fsm.FSM) error {
+	return f.Shutdown()
+}
+
+func (p ping) Shutdown() error {
+	return nil
+}
@@ -46,6 +46,7 @@ func UDP(
 	addr common.Address,
 	runner worker.Runner,
 	shb *common.SharedBuffers,
+	cms common.ClientMetrics,
 	requestTimeout time.Duration,
 ) transceiver.BalancedRequestBuilder {
 	return func(
@@ -58,7 +59,8 @@ func UDP(
 		return udp{
 			log: log,
 			relay: relay.New(
-				log, runner, conn, shb.For(cID).Select(id), &udpRelay{
+				log, cms.For(cID), runner, conn, shb.For(cID).Select(id),
+				&udpRelay{
 					client:         client,
 					addr:           addr,
 					requestTimeout: requestTimeout,
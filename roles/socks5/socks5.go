@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/reinit/coward/common/logger"
+	"github.com/reinit/coward/common/metrics"
 	"github.com/reinit/coward/common/role"
+	"github.com/reinit/coward/common/session"
 	"github.com/reinit/coward/common/ticker"
 	"github.com/reinit/coward/common/worker"
 	"github.com/reinit/coward/roles/common/network"
@@ -33,6 +35,7 @@ import (
 	"github.com/reinit/coward/roles/common/transceiver/clients"
 	pcommon "github.com/reinit/coward/roles/proxy/common"
 	"github.com/reinit/coward/roles/socks5/common"
+	"github.com/reinit/coward/roles/socks5/request"
 )
 
 // Authenticator is the Socks5 User Authenticator function
@@ -40,8 +43,10 @@ type Authenticator func(username, password string) error
 
 type socks5 struct {
 	clients         transceiver.Balancer
-	listener        network.Listener
+	listeners       []network.Listener
 	log             logger.Logger
+	metrics         metrics.Registry
+	sessions        session.Sessions
 	cfg             Config
 	transceiver     transceiver.Balanced
 	ticker          ticker.RequestCloser
@@ -54,14 +59,29 @@ type socks5 struct {
 func New(
 	ticker ticker.RequestCloser,
 	cs []transceiver.Client,
-	listener network.Listener,
+	listeners []network.Listener,
 	log logger.Logger,
+	m metrics.Registry,
+	s session.Sessions,
 	cfg Config,
 ) role.Role {
 	return &socks5{
-		clients:         clients.New(cs, cfg.MaxDestinationRecords),
-		listener:        listener,
+		clients: clients.New(cs, log, clients.Config{
+			MaxDestinations: cfg.MaxDestinationRecords,
+			Strategy:        cfg.Strategy,
+			Weights:         cfg.Weights,
+			Probe:           request.Ping(),
+			ProbeInterval:   cfg.HealthCheckInterval,
+			Breaker: clients.BreakerConfig{
+				FailureThreshold: cfg.BreakerThreshold,
+				OpenTimeout:      cfg.BreakerTimeout,
+				HalfOpenTrials:   cfg.BreakerTrials,
+			},
+		}),
+		listeners:       listeners,
 		log:             log.Context("Socks5"),
+		metrics:         m,
+		sessions:        s,
 		cfg:             cfg,
 		transceiver:     nil,
 		ticker:          ticker,
@@ -117,14 +137,15 @@ func (s *socks5) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	})
 
 	// Then, start server
-	serverServing, serverServeErr := server.New(s.listener, handler{
+	serverServing, serverServeErr := server.New(s.listeners, handler{
+		cfg:           s.cfg,
 		runner:        s.runner,
 		shb:           shb,
 		transceiver:   s.transceiver,
 		negoTimeout:   s.cfg.NegotiationTimeout,
 		timeout:       s.cfg.ConnectionTimeout,
 		authenticator: s.cfg.Authenticator,
-	}, s.log, s.runner, server.Config{
+	}, s.log, s.metrics, s.sessions, s.runner, server.Config{
 		AcceptErrorWait: 100 * time.Millisecond,
 		MaxConnections:  s.cfg.Capacity,
 	}).Serve()
@@ -142,6 +163,15 @@ func (s *socks5) Spawn(unspawnNotifier role.UnspawnNotifier) error {
 	return nil
 }
 
+// Health returns whether or not the server is serving
+func (s *socks5) Health() error {
+	if s.serverServing == nil {
+		return role.ErrNotListening
+	}
+
+	return s.serverServing.Health()
+}
+
 func (s *socks5) Unspawn() error {
 	s.log.Infof("Closing")
 